@@ -2,14 +2,50 @@ package main
 
 import (
 	"app/internal/application"
+	"app/internal/config"
+	"errors"
+	"flag"
 	"fmt"
+	"log/slog"
+	"os"
 )
 
 func main() {
-	app := application.NewDefaultHttp(":8080")
+	cfg, err := config.Load(os.Args[1:], os.Getenv, os.Stderr)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		fmt.Println(err)
+		os.Exit(2)
+	}
+
+	// the standard logger writes through slog, so its messages are logged at the info level
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: cfg.SlogLevel()})))
+	slog.Debug("configuration loaded", "address", cfg.Address, "repository", cfg.Backend(), "features", cfg.Features)
+
+	app := application.NewDefaultHttp(&application.ConfigDefaultHttp{
+		Address:            cfg.Address,
+		RepositoryBackend:  cfg.Backend(),
+		ProductsFilePath:   cfg.Repository.Path,
+		SQLiteDSN:          cfg.Repository.DSN,
+		ReadTimeout:        cfg.Timeouts.Read,
+		WriteTimeout:       cfg.Timeouts.Write,
+		IdleTimeout:        cfg.Timeouts.Idle,
+		ShutdownTimeout:    cfg.Timeouts.Shutdown,
+		ShutdownDelay:      cfg.Timeouts.ShutdownDelay,
+		DisableAudit:       !cfg.Features.Audit,
+		DisableVersioning:  !cfg.Features.Versioning,
+		DisableStockLedger: !cfg.Features.StockLedger,
+		DisableAlerts:      !cfg.Features.Alerts,
+		AlertInterval:      cfg.Alerts.Interval,
+		LowStockThreshold:  cfg.Alerts.LowStockThreshold,
+		ExpirationDays:     cfg.Alerts.ExpirationDays,
+		AlertWebhookURL:    cfg.Alerts.WebhookURL,
+	})
 
 	if err := app.Run(); err != nil {
 		fmt.Println(err)
-		return
+		os.Exit(1)
 	}
 }
@@ -0,0 +1,39 @@
+package internal
+
+import (
+	"context"
+	"time"
+)
+
+// AlertKind is the condition of a product an alert is raised for
+type AlertKind string
+
+const (
+	// AlertLowStock is raised while the quantity of a product is below the threshold
+	AlertLowStock AlertKind = "low_stock"
+	// AlertExpiration is raised while a product expires within the configured days, or is expired
+	AlertExpiration AlertKind = "expiration"
+)
+
+// Alert is raised once when a product enters a condition and stays raised until it leaves it
+type Alert struct {
+	ID         int
+	Kind       AlertKind
+	ProductID  int
+	CodeValue  string
+	Quantity   int
+	Expiration Date
+	Message    string
+	RaisedAt   time.Time
+}
+
+// AlertNotifier emits the alerts as they are raised, e.g. to a log or a webhook
+type AlertNotifier interface {
+	Notify(ctx context.Context, alert Alert) error
+}
+
+// AlertService holds the alerts currently raised
+type AlertService interface {
+	// Alerts returns the raised alerts of the kind, every kind when it is empty, oldest first
+	Alerts(kind AlertKind) ([]Alert, error)
+}
@@ -0,0 +1,361 @@
+package application
+
+import (
+	"app/internal"
+	"app/internal/handler"
+	"app/internal/notifier"
+	"app/internal/repository"
+	"app/internal/service"
+	"app/platform/web/health"
+	"app/platform/web/metrics"
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// reservationSweepInterval is the time between two sweeps of the expired reservations
+const reservationSweepInterval = time.Minute
+
+// healthCheckTimeout bounds each health check
+const healthCheckTimeout = 2 * time.Second
+
+// Option replaces a part of the application built by a Builder
+type Option func(b *Builder)
+
+// WithRepository stores the products in rp instead of the configured backend.
+// The audit log, the versions, the stock ledger and the reservations are then kept in memory, and rp is not closed by the application.
+func WithRepository(rp internal.ProductRepository) Option {
+	return func(b *Builder) {
+		b.rp = rp
+	}
+}
+
+// WithService serves sv instead of the default service over the repository.
+// sv must serve the products of the repository given with WithRepository, the alerts being raised over it:
+// Build fails without it.
+func WithService(sv internal.ProductService) Option {
+	return func(b *Builder) {
+		b.sv = sv
+	}
+}
+
+// WithMiddleware adds the middlewares to the router, after the built-in ones and in the given order
+func WithMiddleware(middlewares ...func(http.Handler) http.Handler) Option {
+	return func(b *Builder) {
+		b.middlewares = append(b.middlewares, middlewares...)
+	}
+}
+
+// WithAlertNotifier emits the alerts through nt instead of the log or the configured webhook
+func WithAlertNotifier(nt internal.AlertNotifier) Option {
+	return func(b *Builder) {
+		b.nt = nt
+	}
+}
+
+// WithLivenessCheck adds the check to the ones of /healthz
+func WithLivenessCheck(name string, check health.Check) Option {
+	return func(b *Builder) {
+		b.liveness = append(b.liveness, namedCheck{name: name, check: check})
+	}
+}
+
+// WithReadinessCheck adds the check to the ones of /readyz, after the checks of the repository
+func WithReadinessCheck(name string, check health.Check) Option {
+	return func(b *Builder) {
+		b.readiness = append(b.readiness, namedCheck{name: name, check: check})
+	}
+}
+
+// namedCheck is a health check given as option
+type namedCheck struct {
+	name  string
+	check health.Check
+}
+
+// Builder assembles the application from its configuration, the parts given as options replacing the configured ones
+type Builder struct {
+	repositoryBackend string
+	productsFilePath  string
+	sqliteDSN         string
+	features          features
+	alerts            service.ConfigAlertMonitor
+	alertWebhookURL   string
+
+	rp          internal.ProductRepository
+	sv          internal.ProductService
+	middlewares []func(http.Handler) http.Handler
+	nt          internal.AlertNotifier
+	liveness    []namedCheck
+	readiness   []namedCheck
+}
+
+// features are the optional features enabled
+type features struct {
+	audit       bool
+	versioning  bool
+	stockLedger bool
+	alerts      bool
+}
+
+// NewBuilder returns a builder of the application configured by cfg, which can be nil, and the options
+func NewBuilder(cfg *ConfigDefaultHttp, opts ...Option) *Builder {
+	defaultRepositoryBackend := ""
+	defaultFilePath := ""
+	defaultSQLiteDSN := ""
+	defaultFeatures := features{audit: true, versioning: true, stockLedger: true, alerts: true}
+	defaultAlerts := service.ConfigAlertMonitor{
+		Interval:          time.Minute,
+		LowStockThreshold: 5,
+		ExpirationDays:    7,
+	}
+	defaultAlertWebhookURL := ""
+
+	if cfg != nil {
+		defaultRepositoryBackend = cfg.RepositoryBackend
+		defaultFilePath = cfg.ProductsFilePath
+		defaultSQLiteDSN = cfg.SQLiteDSN
+		defaultFeatures = features{
+			audit:       !cfg.DisableAudit,
+			versioning:  !cfg.DisableVersioning,
+			stockLedger: !cfg.DisableStockLedger,
+			alerts:      !cfg.DisableAlerts,
+		}
+		if cfg.AlertInterval > 0 {
+			defaultAlerts.Interval = cfg.AlertInterval
+		}
+		if cfg.LowStockThreshold > 0 {
+			defaultAlerts.LowStockThreshold = cfg.LowStockThreshold
+		}
+		if cfg.ExpirationDays > 0 {
+			defaultAlerts.ExpirationDays = cfg.ExpirationDays
+		}
+		defaultAlertWebhookURL = cfg.AlertWebhookURL
+	}
+
+	b := &Builder{
+		repositoryBackend: defaultRepositoryBackend,
+		productsFilePath:  defaultFilePath,
+		sqliteDSN:         defaultSQLiteDSN,
+		features:          defaultFeatures,
+		alerts:            defaultAlerts,
+		alertWebhookURL:   defaultAlertWebhookURL,
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	return b
+}
+
+// App is an assembled application.
+// Its handler can be served or used with httptest, the background jobs only running once RunJobs is called.
+type App struct {
+	handler   http.Handler
+	jobs      []func(ctx context.Context)
+	liveness  *health.Checker
+	readiness *health.Checker
+	// close closes the repository opened by the builder, nil when there is nothing to close
+	close func() error
+}
+
+// Handler returns the router of the application
+func (a *App) Handler() http.Handler {
+	return a.handler
+}
+
+// SetShuttingDown makes the readiness fail, so no more traffic is routed to the application
+func (a *App) SetShuttingDown() {
+	a.readiness.Down("shutting down")
+}
+
+// RunJobs runs the background jobs until the context is done, returning once they all stopped
+func (a *App) RunJobs(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, job := range a.jobs {
+		wg.Add(1)
+		go func(job func(ctx context.Context)) {
+			defer wg.Done()
+			job(ctx)
+		}(job)
+	}
+	wg.Wait()
+}
+
+// Close closes the repository opened by the builder, an injected repository being left open
+func (a *App) Close() error {
+	if a.close == nil {
+		return nil
+	}
+	return a.close()
+}
+
+// backend returns the repository backend, inferred from the dsn and the file path when it is not set
+func (b *Builder) backend() string {
+	switch {
+	case b.repositoryBackend != "":
+		return b.repositoryBackend
+	case b.sqliteDSN != "":
+		return "sqlite"
+	case b.productsFilePath != "":
+		return "file"
+	}
+	return "memory"
+}
+
+// Build opens the repository and assembles the service, the background jobs and the router
+func (b *Builder) Build() (*App, error) {
+	app := &App{
+		liveness:  health.New(healthCheckTimeout),
+		readiness: health.New(healthCheckTimeout),
+	}
+
+	// the builder can not know the repository behind an injected service
+	if b.sv != nil && b.rp == nil {
+		return nil, fmt.Errorf("the injected service needs its repository, see WithRepository")
+	}
+
+	rp := b.rp
+	// the audit log, the versions, the stock ledger and the reservations are kept in the sqlite database when there is one,
+	// in memory otherwise: the file backend only persists the products
+	var au internal.AuditStore = repository.NewAuditMap()
+	var vs internal.ProductVersionStore = repository.NewProductVersionMap()
+	var sl internal.StockLedger = repository.NewStockLedgerMap()
+	var rs internal.ReservationStore = repository.NewReservationMap()
+	if rp == nil {
+		switch b.backend() {
+		case "sqlite":
+			// the schema is migrated when the database is opened
+			ps, err := repository.NewProductSQLite(b.sqliteDSN)
+			if err != nil {
+				return nil, err
+			}
+			app.close = ps.Close
+			app.readiness.Register("repository", ps.Ping)
+			app.readiness.Register("migrations", func(ctx context.Context) error {
+				return repository.CheckMigrations(ps.DB())
+			})
+			rp = ps
+			au = repository.NewAuditSQLite(ps.DB())
+			vs = repository.NewProductVersionSQLite(ps.DB())
+			sl = repository.NewStockLedgerSQLite(ps.DB())
+			rs = repository.NewReservationSQLite(ps.DB())
+		case "file":
+			pf, err := repository.NewProductFile(b.productsFilePath)
+			if err != nil {
+				return nil, err
+			}
+			app.readiness.Register("products_file", func(ctx context.Context) error {
+				return pf.Writable()
+			})
+			rp = pf
+		case "memory":
+			rp = repository.NewProductMap(make(map[int]internal.Product), 0)
+		default:
+			return nil, fmt.Errorf("unknown repository backend %q", b.repositoryBackend)
+		}
+	}
+
+	// the other backends and the injected repositories are reached by finding a product
+	if b.rp != nil || b.backend() != "sqlite" {
+		found := rp
+		app.readiness.Register("repository", func(ctx context.Context) error {
+			_, _, err := found.Find(internal.ProductQuery{Limit: 1})
+			return err
+		})
+	}
+	for _, nc := range b.liveness {
+		app.liveness.Register(nc.name, nc.check)
+	}
+	for _, nc := range b.readiness {
+		app.readiness.Register(nc.name, nc.check)
+	}
+
+	// the operations are timed below the versioning, the catalog being counted without them on every scrape
+	reg := metrics.NewRegistry()
+	registerCatalogMetrics(reg, rp)
+	rp = repository.NewProductObserved(rp, repositoryObserver(reg))
+
+	// the stores of the disabled features are dropped, the service skipping them
+	if !b.features.audit {
+		au = nil
+	}
+	if !b.features.stockLedger {
+		sl = nil
+	}
+
+	if b.features.versioning {
+		rp = repository.NewProductVersioned(rp, vs)
+	}
+
+	sv := b.sv
+	if sv == nil {
+		sv = service.NewProductDefault(rp, au, sl, rs)
+	}
+
+	nt := b.nt
+	if nt == nil {
+		nt = notifier.NewAlertLog(nil)
+		if b.alertWebhookURL != "" {
+			nt = notifier.NewAlertWebhook(b.alertWebhookURL, nil)
+		}
+	}
+	am := service.NewAlertMonitor(rp, nt, b.alerts)
+
+	app.jobs = append(app.jobs, service.NewReservationSweeper(sv, reservationSweepInterval).Run)
+	if b.features.alerts {
+		app.jobs = append(app.jobs, am.Run)
+	}
+
+	hm := handler.NewMetrics(reg)
+	hd := handler.NewDefaultProducts(sv, hm)
+	ha := handler.NewDefaultAlerts(am)
+
+	rt := chi.NewRouter()
+
+	// the requests are measured first, so the time spent in the other middlewares is included
+	rt.Use(hm.Middleware)
+	rt.Use(handler.Actor)
+	rt.Use(b.middlewares...)
+
+	rt.Get("/ping", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("pong"))
+	})
+	rt.Get("/healthz", app.liveness.Handler())
+	rt.Get("/readyz", app.readiness.Handler())
+	rt.Get("/metrics", reg.Handler())
+
+	rt.Get("/products", hd.GetAll())
+	rt.Post("/products", hd.Create())
+	rt.Post("/products/batch", hd.CreateBatch())
+	rt.Get("/products/export.csv", hd.Export())
+	rt.Post("/products/import", hd.Import())
+	rt.Get("/products/trash", hd.Trash())
+	rt.Get("/products/{id}", hd.GetById())
+	rt.Get("/products/code/{code_value}", hd.GetByCode())
+	rt.Put("/products/{id}", hd.Update())
+	rt.Patch("/products/{id}", hd.UpdatePartial())
+	rt.Delete("/products/{id}", hd.Delete())
+	rt.Post("/products/{id}/restore", hd.Restore())
+	rt.Get("/products/{id}/history", hd.History())
+	rt.Get("/products/{id}/versions/{version}", hd.GetVersion())
+	rt.Post("/products/{id}/stock/increment", hd.IncrementStock())
+	rt.Post("/products/{id}/stock/decrement", hd.DecrementStock())
+	rt.Get("/products/{id}/stock/movements", hd.StockMovements())
+	rt.Get("/products/{id}/stock", hd.Stock())
+	rt.Get("/products/{id}/reservations", hd.Reservations())
+	rt.Post("/products/{id}/reservations", hd.Reserve())
+	rt.Post("/products/{id}/reservations/{reservation_id}/confirm", hd.ConfirmReservation())
+	rt.Post("/products/{id}/reservations/{reservation_id}/cancel", hd.CancelReservation())
+	rt.Post("/products/{id}/revert/{version}", hd.Revert())
+
+	rt.Get("/alerts", ha.GetAll())
+
+	app.handler = rt
+
+	return app, nil
+}
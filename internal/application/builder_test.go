@@ -0,0 +1,286 @@
+package application_test
+
+import (
+	"app/internal"
+	"app/internal/application"
+	"app/internal/repository"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// productBody is a valid product to create
+const productBody = `{"name":"product","quantity":10,"code_value":"code","is_published":true,"expiration":"2099-01-01","price":"1.50"}`
+
+// newServer serves the application built with the configuration and options
+func newServer(t *testing.T, cfg *application.ConfigDefaultHttp, opts ...application.Option) *httptest.Server {
+	app, err := application.NewBuilder(cfg, opts...).Build()
+	require.NoError(t, err)
+	srv := httptest.NewServer(app.Handler())
+	t.Cleanup(func() {
+		srv.Close()
+		require.NoError(t, app.Close())
+	})
+	return srv
+}
+
+// do sends the request with the json body, which can be empty, returning the response and its decoded body
+func do(t *testing.T, srv *httptest.Server, method string, path string, body string, headers ...string) (*http.Response, map[string]any) {
+	req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+	require.NoError(t, err)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+
+	res, err := srv.Client().Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	content, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	var decoded map[string]any
+	if len(content) > 0 && strings.HasPrefix(res.Header.Get("Content-Type"), "application/json") {
+		require.NoError(t, json.Unmarshal(content, &decoded), string(content))
+	}
+
+	return res, decoded
+}
+
+// data returns the data member of a response body
+func data(body map[string]any) map[string]any {
+	d, _ := body["data"].(map[string]any)
+	return d
+}
+
+// Tests for the application built by Builder
+func TestBuilder_EndToEnd(t *testing.T) {
+	t.Run("success - product lifecycle over the memory backend", func(t *testing.T) {
+		// arrange
+		srv := newServer(t, nil)
+
+		// act
+		created, createdBody := do(t, srv, http.MethodPost, "/products", productBody, "X-Actor", "alice")
+		found, foundBody := do(t, srv, http.MethodGet, "/products/1", "")
+		stale, _ := do(t, srv, http.MethodPut, "/products/1", productBody, "If-Match", `"0"`)
+		updated, _ := do(t, srv, http.MethodPut, "/products/1", strings.Replace(productBody, `"product"`, `"renamed"`, 1), "If-Match", found.Header.Get("ETag"))
+		deleted, _ := do(t, srv, http.MethodDelete, "/products/1", "")
+		missing, missingBody := do(t, srv, http.MethodGet, "/products/1", "")
+		_, historyBody := do(t, srv, http.MethodGet, "/products/1/history", "")
+
+		// assert
+		require.Equal(t, http.StatusCreated, created.StatusCode)
+		require.Equal(t, float64(1), data(createdBody)["id"])
+		require.Equal(t, http.StatusOK, found.StatusCode)
+		require.Equal(t, "product", data(foundBody)["name"])
+		require.NotEmpty(t, found.Header.Get("ETag"))
+		require.Equal(t, http.StatusPreconditionFailed, stale.StatusCode)
+		require.Equal(t, http.StatusOK, updated.StatusCode)
+		require.Equal(t, http.StatusOK, deleted.StatusCode)
+		require.Equal(t, http.StatusNotFound, missing.StatusCode)
+		require.Equal(t, "product_not_found", missingBody["code"])
+		history := historyBody["data"].([]any)
+		require.Len(t, history, 3)
+		require.Equal(t, "alice", history[0].(map[string]any)["actor"])
+	})
+
+	t.Run("success - reservations hold the available stock", func(t *testing.T) {
+		// arrange
+		srv := newServer(t, nil)
+		do(t, srv, http.MethodPost, "/products", productBody)
+
+		// act
+		reserved, reservedBody := do(t, srv, http.MethodPost, "/products/1/reservations", `{"quantity":8}`)
+		exceeding, _ := do(t, srv, http.MethodPost, "/products/1/stock/decrement", `{"quantity":3,"reason":"sale"}`)
+		_, stockBody := do(t, srv, http.MethodGet, "/products/1/stock", "")
+		confirmed, _ := do(t, srv, http.MethodPost, "/products/1/reservations/1/confirm", "")
+		_, productBody := do(t, srv, http.MethodGet, "/products/1", "")
+
+		// assert
+		require.Equal(t, http.StatusCreated, reserved.StatusCode)
+		require.Equal(t, "active", data(reservedBody)["status"])
+		require.Equal(t, http.StatusConflict, exceeding.StatusCode)
+		require.Equal(t, float64(2), data(stockBody)["available"])
+		require.Equal(t, http.StatusOK, confirmed.StatusCode)
+		require.Equal(t, float64(2), data(productBody)["quantity"])
+	})
+
+	t.Run("success - sqlite backend", func(t *testing.T) {
+		// arrange
+		cfg := &application.ConfigDefaultHttp{SQLiteDSN: filepath.Join(t.TempDir(), "products.db")}
+		srv := newServer(t, cfg)
+
+		// act
+		created, _ := do(t, srv, http.MethodPost, "/products", productBody)
+		incremented, _ := do(t, srv, http.MethodPost, "/products/1/stock/increment", `{"quantity":5,"reason":"purchase"}`)
+		_, movementsBody := do(t, srv, http.MethodGet, "/products/1/stock/movements", "")
+
+		// assert
+		require.Equal(t, http.StatusCreated, created.StatusCode)
+		require.Equal(t, http.StatusOK, incremented.StatusCode)
+		require.Len(t, movementsBody["data"], 1)
+	})
+
+	t.Run("success - injected repository and middlewares", func(t *testing.T) {
+		// arrange
+		db := map[int]internal.Product{1: {ID: 1, Name: "seed", CodeValue: "seed", Version: 1}}
+		rp := repository.NewProductMap(db, 1)
+		var actor string
+		middleware := func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// the built-in middlewares run first
+				actor = internal.ActorFromContext(r.Context())
+				w.Header().Set("X-Test", "seen")
+				next.ServeHTTP(w, r)
+			})
+		}
+		srv := newServer(t, nil, application.WithRepository(rp), application.WithMiddleware(middleware))
+
+		// act
+		res, body := do(t, srv, http.MethodGet, "/products/code/seed", "", "X-Actor", "bob")
+
+		// assert
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, "seen", res.Header.Get("X-Test"))
+		require.Equal(t, "bob", actor)
+		require.Equal(t, "seed", data(body)["name"])
+	})
+
+	t.Run("success - injected service", func(t *testing.T) {
+		// arrange
+		sv := &stubService{product: internal.Product{ID: 7, Name: "stub", Version: 1}}
+		rp := repository.NewProductMap(nil, 0)
+		srv := newServer(t, nil, application.WithRepository(rp), application.WithService(sv))
+
+		// act
+		res, body := do(t, srv, http.MethodGet, "/products/7", "")
+
+		// assert
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, "stub", data(body)["name"])
+	})
+
+	t.Run("success - health and readiness run the registered checks", func(t *testing.T) {
+		// arrange
+		cfg := &application.ConfigDefaultHttp{SQLiteDSN: filepath.Join(t.TempDir(), "products.db")}
+		app, err := application.NewBuilder(cfg,
+			application.WithLivenessCheck("custom", func(ctx context.Context) error { return nil }),
+		).Build()
+		require.NoError(t, err)
+		srv := httptest.NewServer(app.Handler())
+		defer srv.Close()
+		defer app.Close()
+
+		// act
+		live, liveBody := do(t, srv, http.MethodGet, "/healthz", "")
+		ready, readyBody := do(t, srv, http.MethodGet, "/readyz", "")
+		app.SetShuttingDown()
+		down, _ := do(t, srv, http.MethodGet, "/readyz", "")
+		stillLive, _ := do(t, srv, http.MethodGet, "/healthz", "")
+
+		// assert
+		require.Equal(t, http.StatusOK, live.StatusCode)
+		require.Len(t, liveBody["checks"], 1)
+		require.Equal(t, http.StatusOK, ready.StatusCode, readyBody)
+		names := make([]string, 0)
+		for _, check := range readyBody["checks"].([]any) {
+			names = append(names, check.(map[string]any)["name"].(string))
+		}
+		require.Equal(t, []string{"repository", "migrations"}, names)
+		require.Equal(t, http.StatusServiceUnavailable, down.StatusCode)
+		require.Equal(t, http.StatusOK, stillLive.StatusCode)
+	})
+
+	t.Run("error - failing readiness check", func(t *testing.T) {
+		// arrange
+		srv := newServer(t, nil, application.WithReadinessCheck("broken", func(ctx context.Context) error {
+			return errors.New("unreachable")
+		}))
+
+		// act
+		res, body := do(t, srv, http.MethodGet, "/readyz", "")
+
+		// assert
+		require.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+		require.Equal(t, "failing", body["status"])
+	})
+
+	t.Run("error - injected service without its repository", func(t *testing.T) {
+		// arrange
+		sv := &stubService{product: internal.Product{ID: 7, Name: "stub", Version: 1}}
+
+		// act
+		app, err := application.NewBuilder(nil, application.WithService(sv)).Build()
+
+		// assert
+		require.Error(t, err)
+		require.Nil(t, app)
+	})
+
+	t.Run("error - unknown repository backend", func(t *testing.T) {
+		// act
+		app, err := application.NewBuilder(&application.ConfigDefaultHttp{RepositoryBackend: "badger"}).Build()
+
+		// assert
+		require.Error(t, err)
+		require.Nil(t, app)
+	})
+}
+
+// stubService serves a single product, the other use cases being left unimplemented
+type stubService struct {
+	internal.ProductService
+	product internal.Product
+}
+
+func (s *stubService) GetById(id int) (internal.Product, error) {
+	if id != s.product.ID {
+		return internal.Product{}, internal.ErrProductNotFound
+	}
+	return s.product, nil
+}
+
+// Tests for the metrics of the application built by Builder
+func TestBuilder_Metrics(t *testing.T) {
+	t.Run("success - requests, repository operations, catalog and validation failures", func(t *testing.T) {
+		// arrange
+		srv := newServer(t, nil)
+		do(t, srv, http.MethodPost, "/products", productBody)
+		do(t, srv, http.MethodGet, "/products/1", "")
+		do(t, srv, http.MethodGet, "/products/2", "")
+		do(t, srv, http.MethodPost, "/products", `{"name":"","quantity":1,"code_value":"other","expiration":"2099-01-01","price":"1.50"}`)
+		do(t, srv, http.MethodGet, "/unknown", "")
+
+		// act
+		res, err := srv.Client().Get(srv.URL + "/metrics")
+		require.NoError(t, err)
+		defer res.Body.Close()
+		content, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		out := string(content)
+
+		// assert
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Contains(t, out, `http_requests_total{method="GET",route="/products/{id}",status="200"} 1`+"\n")
+		require.Contains(t, out, `http_requests_total{method="GET",route="/products/{id}",status="404"} 1`+"\n")
+		require.Contains(t, out, `http_requests_total{method="POST",route="/products",status="201"} 1`+"\n")
+		require.Contains(t, out, `http_requests_total{method="GET",route="unmatched",status="404"} 1`+"\n")
+		require.Contains(t, out, `http_request_duration_seconds_count{method="GET",route="/products/{id}",status="200"} 1`+"\n")
+		require.Contains(t, out, `repository_operation_duration_seconds_count{operation="save"} 1`+"\n")
+		require.Contains(t, out, `repository_operation_errors_total{operation="get_by_id"} 1`+"\n")
+		require.Contains(t, out, `catalog_products{state="active"} 1`+"\n")
+		require.Contains(t, out, `catalog_products{state="deleted"} 0`+"\n")
+		require.Contains(t, out, "catalog_products_published 1\n")
+		require.Contains(t, out, `validation_failures_total{field="name",rule="required"} 1`+"\n")
+	})
+}
@@ -1,50 +1,225 @@
 package application
 
 import (
-	"app/internal"
-	"app/internal/handler"
-	"app/internal/repository"
-	"app/internal/service"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
-
-	"github.com/go-chi/chi/v5"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 )
 
+// ConfigDefaultHttp is the configuration of the application
+type ConfigDefaultHttp struct {
+	// Address is the address the server listens on
+	Address string
+	// RepositoryBackend is where products are stored: memory, file or sqlite.
+	// When it is empty it is sqlite if SQLiteDSN is set, file if ProductsFilePath is set and memory otherwise.
+	RepositoryBackend string
+	// ProductsFilePath is the json file products are persisted to by the file backend
+	ProductsFilePath string
+	// SQLiteDSN is the sqlite database products are stored in by the sqlite backend
+	SQLiteDSN string
+	// ReadTimeout, WriteTimeout and IdleTimeout bound the requests of the server, zero meaning no timeout
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
+	// ShutdownTimeout bounds the draining of the connections on SIGINT or SIGTERM, 15 seconds by default
+	ShutdownTimeout time.Duration
+	// ShutdownDelay is how long the server keeps accepting connections once its readiness fails on shutdown,
+	// giving the load balancers time to stop routing to it
+	ShutdownDelay time.Duration
+	// DisableAudit, DisableVersioning, DisableStockLedger and DisableAlerts turn the features off
+	DisableAudit       bool
+	DisableVersioning  bool
+	DisableStockLedger bool
+	DisableAlerts      bool
+	// AlertInterval is the time between two scans of the products for alerts, a minute by default
+	AlertInterval time.Duration
+	// LowStockThreshold raises an alert for the products with a quantity below it, 5 by default
+	LowStockThreshold int
+	// ExpirationDays raises an alert for the products expiring within the days, 7 by default
+	ExpirationDays int
+	// AlertWebhookURL is the url the alerts are posted to, they are logged when it is empty
+	AlertWebhookURL string
+}
+
 type DefaultHttp struct {
-	address string
+	// builder assembles the application served
+	builder         *Builder
+	shutdownTimeout time.Duration
+	shutdownDelay   time.Duration
+
+	// server serves the application built by Run, it exists beforehand so Shutdown can be called at any time
+	server *http.Server
+
+	mu sync.Mutex
+	// closed is set by the shutdown, so Run called afterwards does not open anything
+	closed bool
+	// app is the application built by Run, nil before
+	app *App
+	// stopJobs stops the background jobs started by Run, nil before
+	stopJobs context.CancelFunc
+	jobs     sync.WaitGroup
+
+	// shutdown runs the shutdown once, its result being kept for the later calls
+	shutdown    sync.Once
+	shutdownErr error
+	// done is closed when the shutdown is over
+	done chan struct{}
 }
 
-func NewDefaultHttp(address string) *DefaultHttp {
+// NewDefaultHttp returns the server of the application configured by cfg, which can be nil, and the options
+func NewDefaultHttp(cfg *ConfigDefaultHttp, opts ...Option) *DefaultHttp {
 	defaultAddrs := ":8080"
+	var defaultReadTimeout, defaultWriteTimeout, defaultIdleTimeout time.Duration
+	defaultShutdownTimeout := 15 * time.Second
+	var defaultShutdownDelay time.Duration
 
-	if address != "" {
-		defaultAddrs = address
+	if cfg != nil {
+		if cfg.Address != "" {
+			defaultAddrs = cfg.Address
+		}
+		defaultReadTimeout = cfg.ReadTimeout
+		defaultWriteTimeout = cfg.WriteTimeout
+		defaultIdleTimeout = cfg.IdleTimeout
+		if cfg.ShutdownTimeout > 0 {
+			defaultShutdownTimeout = cfg.ShutdownTimeout
+		}
+		defaultShutdownDelay = cfg.ShutdownDelay
 	}
 
 	return &DefaultHttp{
-		address: defaultAddrs,
+		builder:         NewBuilder(cfg, opts...),
+		shutdownTimeout: defaultShutdownTimeout,
+		shutdownDelay:   defaultShutdownDelay,
+		server: &http.Server{
+			Addr:         defaultAddrs,
+			ReadTimeout:  defaultReadTimeout,
+			WriteTimeout: defaultWriteTimeout,
+			IdleTimeout:  defaultIdleTimeout,
+		},
+		done: make(chan struct{}),
 	}
 }
 
+// Run serves the application until Shutdown is called or the process receives SIGINT or SIGTERM,
+// which shut it down draining the connections for up to the shutdown timeout
 func (s *DefaultHttp) Run() error {
+	if err := s.setup(); err != nil {
+		if errors.Is(err, http.ErrServerClosed) {
+			<-s.done
+			return s.shutdownErr
+		}
+		// what was opened before the failure is closed
+		s.Shutdown(context.Background())
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- s.server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errc:
+		if !errors.Is(err, http.ErrServerClosed) {
+			s.Shutdown(context.Background())
+			return err
+		}
+		// Shutdown was called, the server stops once it is over
+		<-s.done
+		return s.shutdownErr
+	case <-ctx.Done():
+		// a second signal kills the process right away
+		stop()
+		slog.Info("shutting down, draining the connections", "timeout", s.shutdownTimeout)
+
+		ctx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+		defer cancel()
+		return s.Shutdown(ctx)
+	}
+}
+
+// Shutdown makes the readiness fail and, after the shutdown delay, stops accepting connections
+// and waits for the in-flight requests until the context is done, closing the remaining connections then.
+// The background jobs are stopped and the repository is closed last.
+// Only the first call shuts the application down, the later ones wait for it and return its result.
+func (s *DefaultHttp) Shutdown(ctx context.Context) error {
+	s.shutdown.Do(func() {
+		defer close(s.done)
 
-	rp := repository.NewProductMap(make(map[int]internal.Product), 0)
+		// the readiness fails first, the connections being accepted for the delay so traffic moves away meanwhile
+		s.mu.Lock()
+		app := s.app
+		s.mu.Unlock()
+		if app != nil {
+			app.SetShuttingDown()
+			select {
+			case <-time.After(s.shutdownDelay):
+			case <-ctx.Done():
+			}
+		}
 
-	sv := service.NewProductDefault(rp)
+		var errs []error
+		if err := s.server.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("draining connections: %w", err))
+			s.server.Close()
+		}
 
-	hd := handler.NewDefaultProducts(sv)
+		s.mu.Lock()
+		defer s.mu.Unlock()
 
-	rt := chi.NewRouter()
+		s.closed = true
+		if s.stopJobs != nil {
+			s.stopJobs()
+			s.jobs.Wait()
+		}
+		if s.app != nil {
+			if err := s.app.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("closing repository: %w", err))
+			}
+		}
 
-	rt.Get("/ping", func(w http.ResponseWriter, r *http.Request) {
-		w.Write([]byte("pong"))
+		s.shutdownErr = errors.Join(errs...)
 	})
 
-	rt.Post("/products", hd.Create())
-	rt.Get("/products/{id}", hd.GetById())
-	rt.Put("/products/{id}", hd.Update())
-	rt.Patch("/products/{id}", hd.UpdatePartial())
-	rt.Delete("/products/{id}", hd.Delete())
+	<-s.done
+	return s.shutdownErr
+}
+
+// setup builds the application, starts its background jobs and serves its handler
+func (s *DefaultHttp) setup() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return http.ErrServerClosed
+	}
+
+	app, err := s.builder.Build()
+	if err != nil {
+		return err
+	}
+	s.app = app
+
+	// the background jobs run until the shutdown
+	ctx, cancel := context.WithCancel(context.Background())
+	s.stopJobs = cancel
+	s.jobs.Add(1)
+	go func() {
+		defer s.jobs.Done()
+		app.RunJobs(ctx)
+	}()
+
+	s.server.Handler = app.Handler()
 
-	return http.ListenAndServe(s.address, rt)
+	return nil
 }
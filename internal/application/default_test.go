@@ -0,0 +1,41 @@
+package application_test
+
+import (
+	"app/internal/application"
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for DefaultHttp
+func TestDefaultHttp_Shutdown(t *testing.T) {
+	t.Run("success - run returns once shut down", func(t *testing.T) {
+		// arrange
+		app := application.NewDefaultHttp(&application.ConfigDefaultHttp{
+			Address:   "127.0.0.1:0",
+			SQLiteDSN: filepath.Join(t.TempDir(), "products.db"),
+		})
+		errc := make(chan error, 1)
+		go func() {
+			errc <- app.Run()
+		}()
+
+		// act
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		err := app.Shutdown(ctx)
+
+		// assert
+		require.NoError(t, err)
+		select {
+		case err := <-errc:
+			require.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("run did not return")
+		}
+		require.NoError(t, app.Shutdown(ctx))
+	})
+}
@@ -0,0 +1,62 @@
+package application
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"app/platform/web/metrics"
+	"log/slog"
+	"time"
+)
+
+// repositoryBuckets are the upper bounds of the repository operation durations, in seconds,
+// finer than the ones of the requests as the in-memory backends answer within microseconds
+var repositoryBuckets = []float64{.0001, .0005, .001, .005, .01, .05, .1, .5, 1}
+
+// repositoryObserver returns the observer timing the repository operations in reg
+func repositoryObserver(reg *metrics.Registry) repository.ProductObserver {
+	duration := reg.Histogram("repository_operation_duration_seconds",
+		"Duration of the product repository operations, by operation.",
+		repositoryBuckets, "operation")
+	errs := reg.Counter("repository_operation_errors_total",
+		"Product repository operations returning an error, e.g. a product not found, by operation.",
+		"operation")
+
+	return func(operation string, d time.Duration, err error) {
+		duration.Observe(d.Seconds(), operation)
+		if err != nil {
+			errs.Inc(operation)
+		}
+	}
+}
+
+// registerCatalogMetrics counts the products of rp on every scrape of reg.
+// A count failing keeps the value of the previous scrape.
+func registerCatalogMetrics(reg *metrics.Registry, rp internal.ProductRepository) {
+	products := reg.Gauge("catalog_products",
+		"Products in the catalog, by state: active or deleted, i.e. in the trash.",
+		"state")
+	published := reg.Gauge("catalog_products_published",
+		"Active products which are published.")
+
+	isPublished := true
+	counts := []struct {
+		gauge  *metrics.Gauge
+		query  internal.ProductQuery
+		labels []string
+	}{
+		{gauge: products, query: internal.ProductQuery{Limit: 1}, labels: []string{"active"}},
+		{gauge: products, query: internal.ProductQuery{Deleted: true, Limit: 1}, labels: []string{"deleted"}},
+		{gauge: published, query: internal.ProductQuery{IsPublished: &isPublished, Limit: 1}},
+	}
+
+	reg.OnCollect(func() {
+		for _, c := range counts {
+			_, total, err := rp.Find(c.query)
+			if err != nil {
+				slog.Warn("metrics: counting the products", "error", err)
+				continue
+			}
+			c.gauge.Set(float64(total), c.labels...)
+		}
+	})
+}
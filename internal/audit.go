@@ -0,0 +1,62 @@
+package internal
+
+import (
+	"context"
+	"time"
+)
+
+// AuditOperation is the kind of product mutation recorded in the audit log
+type AuditOperation string
+
+const (
+	AuditOperationCreate  AuditOperation = "create"
+	AuditOperationUpdate  AuditOperation = "update"
+	AuditOperationDelete  AuditOperation = "delete"
+	AuditOperationRestore AuditOperation = "restore"
+	AuditOperationPurge   AuditOperation = "purge"
+)
+
+// AuditChange is the value of a product field before and after a mutation, nil when the field had no value
+type AuditChange struct {
+	Field  string
+	Before any
+	After  any
+}
+
+// AuditEntry records a mutation of a product
+type AuditEntry struct {
+	ID        int
+	ProductID int
+	// Actor is who made the mutation, see ContextWithActor
+	Actor     string
+	Timestamp time.Time
+	Operation AuditOperation
+	Changes   []AuditChange
+}
+
+// AuditStore stores the audit log of the products
+type AuditStore interface {
+	// Record appends the entry to the log, setting its id
+	Record(entry *AuditEntry) error
+	// History returns the entries of the product, oldest first
+	History(productID int) ([]AuditEntry, error)
+}
+
+// AnonymousActor is the actor of the mutations made without one
+const AnonymousActor = "anonymous"
+
+// actorKey is the context key of the actor
+type actorKey struct{}
+
+// ContextWithActor returns a copy of ctx carrying the actor making the requests
+func ContextWithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor carried by ctx, AnonymousActor if there is none
+func ActorFromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return AnonymousActor
+}
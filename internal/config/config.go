@@ -0,0 +1,199 @@
+// Package config loads the configuration of the server from its defaults, a yaml file,
+// the environment and the command line flags, each source overriding the previous ones.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ErrInvalid is wrapped by every error of an invalid configuration
+var ErrInvalid = errors.New("invalid configuration")
+
+// repository backends
+const (
+	BackendMemory = "memory"
+	BackendFile   = "file"
+	BackendSQLite = "sqlite"
+)
+
+// Config is the configuration of the server
+type Config struct {
+	// Address is the address the server listens on, as host:port
+	Address    string           `yaml:"address"`
+	Repository RepositoryConfig `yaml:"repository"`
+	Timeouts   TimeoutsConfig   `yaml:"timeouts"`
+	// LogLevel is the lowest level logged: debug, info, warn or error
+	LogLevel string         `yaml:"log_level"`
+	Features FeaturesConfig `yaml:"features"`
+	Alerts   AlertsConfig   `yaml:"alerts"`
+}
+
+// RepositoryConfig selects where the products are stored
+type RepositoryConfig struct {
+	// Backend is memory, file or sqlite.
+	// When it is empty it is sqlite if DSN is set, file if Path is set and memory otherwise.
+	// Only the sqlite backend stores the audit log, the versions, the stock ledger and the reservations:
+	// the others keep them in memory, so they are lost when the server restarts.
+	Backend string `yaml:"backend"`
+	// Path is the json file of the file backend
+	Path string `yaml:"path"`
+	// DSN is the database of the sqlite backend
+	DSN string `yaml:"dsn"`
+}
+
+// TimeoutsConfig bounds the time spent on the requests of the server
+type TimeoutsConfig struct {
+	Read  time.Duration `yaml:"read"`
+	Write time.Duration `yaml:"write"`
+	Idle  time.Duration `yaml:"idle"`
+	// Shutdown bounds the draining of the connections when the server is stopped
+	Shutdown time.Duration `yaml:"shutdown"`
+	// ShutdownDelay is how long connections are still accepted once the readiness fails on shutdown
+	ShutdownDelay time.Duration `yaml:"shutdown_delay"`
+}
+
+// FeaturesConfig toggles the optional features, all enabled by default
+type FeaturesConfig struct {
+	Audit       bool `yaml:"audit"`
+	Versioning  bool `yaml:"versioning"`
+	StockLedger bool `yaml:"stock_ledger"`
+	Alerts      bool `yaml:"alerts"`
+}
+
+// AlertsConfig configures the low stock and expiration alerts
+type AlertsConfig struct {
+	Interval          time.Duration `yaml:"interval"`
+	LowStockThreshold int           `yaml:"low_stock_threshold"`
+	ExpirationDays    int           `yaml:"expiration_days"`
+	// WebhookURL is the url the alerts are posted to, they are logged when it is empty
+	WebhookURL string `yaml:"webhook_url"`
+}
+
+// Default returns the configuration used for the settings no source provides
+func Default() Config {
+	return Config{
+		Address: ":8080",
+		Timeouts: TimeoutsConfig{
+			Read:     10 * time.Second,
+			Write:    30 * time.Second,
+			Idle:     2 * time.Minute,
+			Shutdown: 15 * time.Second,
+		},
+		LogLevel: "info",
+		Features: FeaturesConfig{
+			Audit:       true,
+			Versioning:  true,
+			StockLedger: true,
+			Alerts:      true,
+		},
+		Alerts: AlertsConfig{
+			Interval:          time.Minute,
+			LowStockThreshold: 5,
+			ExpirationDays:    7,
+		},
+	}
+}
+
+// logLevels maps the log levels to their slog level
+var logLevels = map[string]slog.Level{
+	"debug": slog.LevelDebug,
+	"info":  slog.LevelInfo,
+	"warn":  slog.LevelWarn,
+	"error": slog.LevelError,
+}
+
+// SlogLevel returns the slog level of the log level, which must be valid
+func (c Config) SlogLevel() slog.Level {
+	return logLevels[c.LogLevel]
+}
+
+// Backend returns the repository backend, inferred from the path and dsn when it is not set
+func (c Config) Backend() string {
+	switch {
+	case c.Repository.Backend != "":
+		return c.Repository.Backend
+	case c.Repository.DSN != "":
+		return BackendSQLite
+	case c.Repository.Path != "":
+		return BackendFile
+	}
+	return BackendMemory
+}
+
+// Validate checks every setting, returning all the invalid ones joined
+func (c Config) Validate() error {
+	var errs []error
+	invalid := func(setting string, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%w: %s %s", ErrInvalid, setting, fmt.Sprintf(format, args...)))
+	}
+
+	if _, port, err := net.SplitHostPort(c.Address); err != nil {
+		invalid("address", "must be host:port, got %q", c.Address)
+	} else if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 {
+		invalid("address", "must have a port between 0 and 65535, got %q", port)
+	}
+
+	switch c.Backend() {
+	case BackendMemory:
+	case BackendFile:
+		if c.Repository.Path == "" {
+			invalid("repository.path", "is required by the file backend")
+		}
+	case BackendSQLite:
+		if c.Repository.DSN == "" {
+			invalid("repository.dsn", "is required by the sqlite backend")
+		}
+	default:
+		invalid("repository.backend", "must be memory, file or sqlite, got %q", c.Repository.Backend)
+	}
+
+	timeouts := []struct {
+		setting string
+		timeout time.Duration
+	}{
+		{"timeouts.read", c.Timeouts.Read},
+		{"timeouts.write", c.Timeouts.Write},
+		{"timeouts.idle", c.Timeouts.Idle},
+		{"timeouts.shutdown", c.Timeouts.Shutdown},
+	}
+	for _, t := range timeouts {
+		if t.timeout <= 0 {
+			invalid(t.setting, "must be greater than 0, got %s", t.timeout)
+		}
+	}
+
+	if c.Timeouts.ShutdownDelay < 0 {
+		invalid("timeouts.shutdown_delay", "must not be negative, got %s", c.Timeouts.ShutdownDelay)
+	} else if c.Timeouts.ShutdownDelay >= c.Timeouts.Shutdown {
+		invalid("timeouts.shutdown_delay", "must be less than timeouts.shutdown, got %s", c.Timeouts.ShutdownDelay)
+	}
+
+	if _, ok := logLevels[c.LogLevel]; !ok {
+		invalid("log_level", "must be debug, info, warn or error, got %q", c.LogLevel)
+	}
+
+	if c.Features.Alerts {
+		if c.Alerts.Interval <= 0 {
+			invalid("alerts.interval", "must be greater than 0, got %s", c.Alerts.Interval)
+		}
+		if c.Alerts.LowStockThreshold < 1 {
+			invalid("alerts.low_stock_threshold", "must be at least 1, got %d", c.Alerts.LowStockThreshold)
+		}
+		if c.Alerts.ExpirationDays < 1 {
+			invalid("alerts.expiration_days", "must be at least 1, got %d", c.Alerts.ExpirationDays)
+		}
+		if c.Alerts.WebhookURL != "" {
+			if u, err := url.Parse(c.Alerts.WebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				invalid("alerts.webhook_url", "must be an http or https url, got %q", c.Alerts.WebhookURL)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,129 @@
+package config_test
+
+import (
+	"app/internal/config"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// env returns a getenv reading the variables
+func env(variables map[string]string) func(string) string {
+	return func(key string) string {
+		return variables[key]
+	}
+}
+
+// writeFile writes the yaml content to a file of a temporary directory, returning its path
+func writeFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+// Tests for Load
+func TestLoad(t *testing.T) {
+	t.Run("success - defaults", func(t *testing.T) {
+		// act
+		cfg, err := config.Load(nil, env(nil), io.Discard)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, config.Default(), cfg)
+		require.Equal(t, config.BackendMemory, cfg.Backend())
+	})
+
+	t.Run("success - flags override env which overrides the file", func(t *testing.T) {
+		// arrange
+		path := writeFile(t, `
+address: ":8081"
+log_level: debug
+timeouts:
+  read: 3s
+features:
+  audit: false
+alerts:
+  low_stock_threshold: 2
+`)
+		variables := map[string]string{
+			"CONFIG_FILE":         path,
+			"SERVER_ADDRESS":      ":8082",
+			"LOW_STOCK_THRESHOLD": "3",
+		}
+
+		// act
+		cfg, err := config.Load([]string{"-address", ":8083", "-versioning=false"}, env(variables), io.Discard)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, ":8083", cfg.Address)
+		require.Equal(t, "debug", cfg.LogLevel)
+		require.Equal(t, 3*time.Second, cfg.Timeouts.Read)
+		require.Equal(t, config.Default().Timeouts.Write, cfg.Timeouts.Write)
+		require.False(t, cfg.Features.Audit)
+		require.False(t, cfg.Features.Versioning)
+		require.True(t, cfg.Features.Alerts)
+		require.Equal(t, 3, cfg.Alerts.LowStockThreshold)
+	})
+
+	t.Run("success - backend is inferred from the dsn and the path", func(t *testing.T) {
+		// act
+		sqlite, err1 := config.Load([]string{"-sqlite-dsn", "products.db", "-products-file", "products.json"}, env(nil), io.Discard)
+		file, err2 := config.Load(nil, env(map[string]string{"PRODUCTS_FILE_PATH": "products.json"}), io.Discard)
+
+		// assert
+		require.NoError(t, err1)
+		require.Equal(t, config.BackendSQLite, sqlite.Backend())
+		require.NoError(t, err2)
+		require.Equal(t, config.BackendFile, file.Backend())
+	})
+
+	t.Run("error - every invalid setting is reported", func(t *testing.T) {
+		// act
+		_, err := config.Load([]string{"-address", "8080", "-repository", "file", "-log-level", "loud", "-idle-timeout", "0s"}, env(nil), io.Discard)
+
+		// assert
+		require.ErrorIs(t, err, config.ErrInvalid)
+		require.ErrorContains(t, err, "address")
+		require.ErrorContains(t, err, "repository.path")
+		require.ErrorContains(t, err, "log_level")
+		require.ErrorContains(t, err, "timeouts.idle")
+	})
+
+	t.Run("error - values that can not be parsed", func(t *testing.T) {
+		// arrange
+		cases := []struct {
+			args      []string
+			variables map[string]string
+		}{
+			{args: []string{"-read-timeout", "soon"}},
+			{args: []string{"-alerts=maybe"}},
+			{args: []string{"-unknown"}},
+			{variables: map[string]string{"EXPIRATION_DAYS": "week"}},
+		}
+
+		for _, c := range cases {
+			// act
+			_, err := config.Load(c.args, env(c.variables), io.Discard)
+
+			// assert
+			require.ErrorIs(t, err, config.ErrInvalid, c)
+		}
+	})
+
+	t.Run("error - unknown setting in the file", func(t *testing.T) {
+		// arrange
+		path := writeFile(t, "adress: \":8081\"\n")
+
+		// act
+		_, err := config.Load([]string{"-config", path}, env(nil), io.Discard)
+
+		// assert
+		require.ErrorIs(t, err, config.ErrInvalid)
+		require.ErrorContains(t, err, "adress")
+	})
+}
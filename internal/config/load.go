@@ -0,0 +1,186 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// setting is a configuration setting provided by a flag and an environment variable
+type setting struct {
+	flag  string
+	env   string
+	usage string
+	// set parses the value into the setting of the configuration
+	set func(c *Config, value string) error
+	// isBool lets the flag be given without a value, meaning true
+	isBool bool
+}
+
+// settings are the settings provided by the flags and the environment, the yaml file providing them all
+var settings = []setting{
+	{"address", "SERVER_ADDRESS", "address the server listens on, as host:port", stringSetting(func(c *Config) *string { return &c.Address }), false},
+	{"repository", "REPOSITORY_BACKEND", "repository backend: memory, file or sqlite", stringSetting(func(c *Config) *string { return &c.Repository.Backend }), false},
+	{"products-file", "PRODUCTS_FILE_PATH", "json file of the file backend", stringSetting(func(c *Config) *string { return &c.Repository.Path }), false},
+	{"sqlite-dsn", "SQLITE_DSN", "database of the sqlite backend", stringSetting(func(c *Config) *string { return &c.Repository.DSN }), false},
+	{"read-timeout", "READ_TIMEOUT", "time to read a request", durationSetting(func(c *Config) *time.Duration { return &c.Timeouts.Read }), false},
+	{"write-timeout", "WRITE_TIMEOUT", "time to write a response", durationSetting(func(c *Config) *time.Duration { return &c.Timeouts.Write }), false},
+	{"idle-timeout", "IDLE_TIMEOUT", "time a keep-alive connection waits for the next request", durationSetting(func(c *Config) *time.Duration { return &c.Timeouts.Idle }), false},
+	{"shutdown-timeout", "SHUTDOWN_TIMEOUT", "time the connections are drained for when the server is stopped", durationSetting(func(c *Config) *time.Duration { return &c.Timeouts.Shutdown }), false},
+	{"shutdown-delay", "SHUTDOWN_DELAY", "time connections are still accepted once the readiness fails on shutdown", durationSetting(func(c *Config) *time.Duration { return &c.Timeouts.ShutdownDelay }), false},
+	{"log-level", "LOG_LEVEL", "lowest level logged: debug, info, warn or error", stringSetting(func(c *Config) *string { return &c.LogLevel }), false},
+	{"audit", "FEATURE_AUDIT", "record the audit log of the products", boolSetting(func(c *Config) *bool { return &c.Features.Audit }), true},
+	{"versioning", "FEATURE_VERSIONING", "retain every version of the products", boolSetting(func(c *Config) *bool { return &c.Features.Versioning }), true},
+	{"stock-ledger", "FEATURE_STOCK_LEDGER", "record the stock movements of the products", boolSetting(func(c *Config) *bool { return &c.Features.StockLedger }), true},
+	{"alerts", "FEATURE_ALERTS", "raise low stock and expiration alerts", boolSetting(func(c *Config) *bool { return &c.Features.Alerts }), true},
+	{"alert-interval", "ALERT_INTERVAL", "time between two scans for alerts", durationSetting(func(c *Config) *time.Duration { return &c.Alerts.Interval }), false},
+	{"low-stock-threshold", "LOW_STOCK_THRESHOLD", "quantity below which a low stock alert is raised", intSetting(func(c *Config) *int { return &c.Alerts.LowStockThreshold }), false},
+	{"expiration-days", "EXPIRATION_DAYS", "days before the expiration an expiration alert is raised", intSetting(func(c *Config) *int { return &c.Alerts.ExpirationDays }), false},
+	{"alert-webhook-url", "ALERT_WEBHOOK_URL", "url the alerts are posted to, they are logged otherwise", stringSetting(func(c *Config) *string { return &c.Alerts.WebhookURL }), false},
+}
+
+const (
+	// configFlag is the flag of the yaml file
+	configFlag = "config"
+	// configEnv is the environment variable of the yaml file, the flag taking precedence
+	configEnv = "CONFIG_FILE"
+)
+
+// Load returns the validated configuration of the defaults overridden by the yaml file,
+// then by the environment read with getenv and then by the flags in args (without the program name).
+// flag.ErrHelp is returned when the flags ask for help, which is written to output.
+func Load(args []string, getenv func(string) string, output io.Writer) (Config, error) {
+	fs := flag.NewFlagSet("serverChi", flag.ContinueOnError)
+	fs.SetOutput(output)
+	file := fs.String(configFlag, "", "yaml configuration file (env "+configEnv+")")
+	for _, s := range settings {
+		fs.Var(&flagValue{isBool: s.isBool}, s.flag, fmt.Sprintf("%s (env %s)", s.usage, s.env))
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return Config{}, err
+		}
+		return Config{}, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+
+	cfg := Default()
+
+	if *file == "" {
+		*file = getenv(configEnv)
+	}
+	if *file != "" {
+		if err := loadFile(&cfg, *file); err != nil {
+			return Config{}, err
+		}
+	}
+
+	for _, s := range settings {
+		if value := getenv(s.env); value != "" {
+			if err := s.set(&cfg, value); err != nil {
+				return Config{}, fmt.Errorf("%w: env %s: %v", ErrInvalid, s.env, err)
+			}
+		}
+	}
+
+	var err error
+	fs.Visit(func(f *flag.Flag) {
+		for _, s := range settings {
+			if err == nil && s.flag == f.Name {
+				if e := s.set(&cfg, f.Value.String()); e != nil {
+					err = fmt.Errorf("%w: flag -%s: %v", ErrInvalid, s.flag, e)
+				}
+			}
+		}
+	})
+	if err != nil {
+		return Config{}, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+
+	return cfg, nil
+}
+
+// loadFile overrides the configuration with the settings of the yaml file, unknown settings being rejected
+func loadFile(cfg *Config, path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("%w: %s: %v", ErrInvalid, path, err)
+	}
+
+	return nil
+}
+
+// flagValue keeps the raw value of a flag, which is parsed once the sources of lower precedence are applied
+type flagValue struct {
+	value  string
+	isBool bool
+}
+
+func (v *flagValue) String() string {
+	return v.value
+}
+
+func (v *flagValue) Set(value string) error {
+	v.value = value
+	return nil
+}
+
+func (v *flagValue) IsBoolFlag() bool {
+	return v.isBool
+}
+
+func stringSetting(field func(c *Config) *string) func(c *Config, value string) error {
+	return func(c *Config, value string) error {
+		*field(c) = value
+		return nil
+	}
+}
+
+func durationSetting(field func(c *Config) *time.Duration) func(c *Config, value string) error {
+	return func(c *Config, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		*field(c) = d
+		return nil
+	}
+}
+
+func intSetting(field func(c *Config) *int) func(c *Config, value string) error {
+	return func(c *Config, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		*field(c) = n
+		return nil
+	}
+}
+
+func boolSetting(field func(c *Config) *bool) func(c *Config, value string) error {
+	return func(c *Config, value string) error {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		*field(c) = b
+		return nil
+	}
+}
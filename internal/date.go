@@ -0,0 +1,113 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// DateLayout is the dd/mm/yyyy layout dates are historically written with
+	DateLayout = "02/01/2006"
+	// DateLayoutISO is the ISO-8601 yyyy-mm-dd layout dates are written with
+	DateLayoutISO = "2006-01-02"
+)
+
+// ErrDateFormat is returned when a date is not written with one of the supported layouts
+var ErrDateFormat = errors.New("date must be dd/mm/yyyy or yyyy-mm-dd")
+
+// Date is a calendar day, without time of day nor location
+type Date struct {
+	t time.Time
+}
+
+// NewDate returns the date of the given day
+func NewDate(year int, month time.Month, day int) Date {
+	return Date{t: time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+}
+
+// DateOf returns the day of t in its location
+func DateOf(t time.Time) Date {
+	return NewDate(t.Year(), t.Month(), t.Day())
+}
+
+// Today returns the current day in the local time zone
+func Today() Date {
+	return DateOf(time.Now())
+}
+
+// ParseDate parses a date written as dd/mm/yyyy or as ISO-8601 yyyy-mm-dd
+func ParseDate(s string) (Date, error) {
+	for _, layout := range []string{DateLayoutISO, DateLayout} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return Date{t: t}, nil
+		}
+	}
+	return Date{}, fmt.Errorf("%w: %q", ErrDateFormat, s)
+}
+
+// IsZero reports whether d is the zero date, used for missing dates
+func (d Date) IsZero() bool {
+	return d.t.IsZero()
+}
+
+// Before reports whether d is before u
+func (d Date) Before(u Date) bool {
+	return d.t.Before(u.t)
+}
+
+// After reports whether d is after u
+func (d Date) After(u Date) bool {
+	return d.t.After(u.t)
+}
+
+// Compare returns -1, 0 or 1 if d is before, equal or after u
+func (d Date) Compare(u Date) int {
+	return d.t.Compare(u.t)
+}
+
+// AddDays returns the date n days after d
+func (d Date) AddDays(n int) Date {
+	return Date{t: d.t.AddDate(0, 0, n)}
+}
+
+// Time returns the start of the day in UTC
+func (d Date) Time() time.Time {
+	return d.t
+}
+
+// String returns the ISO-8601 yyyy-mm-dd representation of d, empty for the zero date
+func (d Date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.t.Format(DateLayoutISO)
+}
+
+// MarshalJSON writes the date as an ISO-8601 string, null for the zero date
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON reads a date written as dd/mm/yyyy or yyyy-mm-dd, null or "" being the zero date
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var s *string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("%w: %s", ErrDateFormat, data)
+	}
+	if s == nil || *s == "" {
+		*d = Date{}
+		return nil
+	}
+
+	date, err := ParseDate(*s)
+	if err != nil {
+		return err
+	}
+	*d = date
+	return nil
+}
@@ -0,0 +1,164 @@
+package internal_test
+
+import (
+	"app/internal"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for ParseDate
+func TestParseDate(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    string
+		expected internal.Date
+	}{
+		{name: "iso layout", input: "2030-02-28", expected: internal.NewDate(2030, time.February, 28)},
+		{name: "dd/mm/yyyy layout", input: "28/02/2030", expected: internal.NewDate(2030, time.February, 28)},
+		{name: "leap day", input: "29/02/2028", expected: internal.NewDate(2028, time.February, 29)},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run("success - "+c.name, func(t *testing.T) {
+			// act
+			date, err := internal.ParseDate(c.input)
+
+			// assert
+			require.NoError(t, err)
+			require.Equal(t, c.expected, date)
+		})
+	}
+
+	for _, input := range []string{"31/02/2030", "2030-02-31", "29/02/2030", "2030-13-01", "01-01-2030", "2030/01/01", "tomorrow", ""} {
+		input := input
+		t.Run("error - "+input, func(t *testing.T) {
+			// act
+			date, err := internal.ParseDate(input)
+
+			// assert
+			require.ErrorIs(t, err, internal.ErrDateFormat)
+			require.True(t, date.IsZero())
+		})
+	}
+}
+
+// Tests for Date
+func TestDate(t *testing.T) {
+	t.Run("success - add days crosses months and years", func(t *testing.T) {
+		// arrange
+		date := internal.NewDate(2030, time.December, 31)
+
+		// act
+		next := date.AddDays(1)
+		previous := date.AddDays(-365)
+
+		// assert
+		require.Equal(t, internal.NewDate(2031, time.January, 1), next)
+		require.Equal(t, internal.NewDate(2029, time.December, 31), previous)
+	})
+
+	t.Run("success - before, after and compare", func(t *testing.T) {
+		// arrange
+		date := internal.NewDate(2030, time.January, 1)
+		next := date.AddDays(1)
+
+		// assert
+		require.True(t, date.Before(next))
+		require.False(t, next.Before(date))
+		require.False(t, date.Before(date))
+		require.True(t, next.After(date))
+		require.Equal(t, -1, date.Compare(next))
+		require.Equal(t, 0, date.Compare(internal.NewDate(2030, time.January, 1)))
+		require.Equal(t, 1, next.Compare(date))
+	})
+
+	t.Run("success - the day of a time is kept in its location", func(t *testing.T) {
+		// arrange
+		location := time.FixedZone("UTC-3", -3*60*60)
+		instant := time.Date(2030, time.January, 1, 23, 0, 0, 0, location)
+
+		// act
+		date := internal.DateOf(instant)
+
+		// assert
+		require.Equal(t, "2030-01-01", date.String())
+	})
+
+	t.Run("success - the zero date is written empty", func(t *testing.T) {
+		// arrange
+		var date internal.Date
+
+		// assert
+		require.True(t, date.IsZero())
+		require.Equal(t, "", date.String())
+	})
+}
+
+// Tests for the json encoding of Date
+func TestDate_JSON(t *testing.T) {
+	t.Run("success - round trip", func(t *testing.T) {
+		// arrange
+		date := internal.NewDate(2030, time.March, 15)
+
+		// act
+		data, errMarshal := json.Marshal(date)
+		var decoded internal.Date
+		errUnmarshal := json.Unmarshal(data, &decoded)
+
+		// assert
+		require.NoError(t, errMarshal)
+		require.Equal(t, `"2030-03-15"`, string(data))
+		require.NoError(t, errUnmarshal)
+		require.Equal(t, date, decoded)
+	})
+
+	t.Run("success - the zero date is null", func(t *testing.T) {
+		// act
+		data, err := json.Marshal(internal.Date{})
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, "null", string(data))
+	})
+
+	cases := []struct {
+		name     string
+		input    string
+		expected internal.Date
+	}{
+		{name: "dd/mm/yyyy is read", input: `"15/03/2030"`, expected: internal.NewDate(2030, time.March, 15)},
+		{name: "null is the zero date", input: `null`, expected: internal.Date{}},
+		{name: "empty is the zero date", input: `""`, expected: internal.Date{}},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run("success - "+c.name, func(t *testing.T) {
+			// arrange
+			date := internal.NewDate(2000, time.January, 1)
+
+			// act
+			err := json.Unmarshal([]byte(c.input), &date)
+
+			// assert
+			require.NoError(t, err)
+			require.Equal(t, c.expected, date)
+		})
+	}
+
+	for _, input := range []string{`"31/02/2030"`, `20300315`, `{}`} {
+		input := input
+		t.Run("error - "+input, func(t *testing.T) {
+			// arrange
+			var date internal.Date
+
+			// act
+			err := json.Unmarshal([]byte(input), &date)
+
+			// assert
+			require.ErrorIs(t, err, internal.ErrDateFormat)
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package internal
+
+// FieldError is an error caused by a specific field, e.g. a required field or a duplicated code_value.
+// It wraps one of the sentinel errors so callers can still match it with errors.Is.
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+// NewFieldError returns err caused by field
+func NewFieldError(err error, field string) *FieldError {
+	return &FieldError{
+		Field: field,
+		Err:   err,
+	}
+}
+
+func (e *FieldError) Error() string {
+	return e.Err.Error() + ": " + e.Field
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
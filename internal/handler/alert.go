@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"app/internal"
+	"app/platform/web/response"
+	"net/http"
+	"time"
+)
+
+type DefaultAlert struct {
+	sv internal.AlertService
+}
+
+// BodyResponseAlertJSON is an alert raised for a product
+type BodyResponseAlertJSON struct {
+	ID         int           `json:"id"`
+	Kind       string        `json:"kind"`
+	ProductID  int           `json:"product_id"`
+	CodeValue  string        `json:"code_value"`
+	Quantity   int           `json:"quantity"`
+	Expiration internal.Date `json:"expiration"`
+	Message    string        `json:"message"`
+	RaisedAt   time.Time     `json:"raised_at"`
+}
+
+func NewDefaultAlerts(sv internal.AlertService) *DefaultAlert {
+	return &DefaultAlert{
+		sv: sv,
+	}
+}
+
+// GetAll lists the raised alerts, oldest first, filtered by the optional kind query parameter
+func (d *DefaultAlert) GetAll() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		kind := internal.AlertKind(r.URL.Query().Get("kind"))
+		switch kind {
+		case "", internal.AlertLowStock, internal.AlertExpiration:
+		default:
+			responseError(w, internal.NewFieldError(internal.ErrQueryParam, "kind"))
+			return
+		}
+
+		alerts, err := d.sv.Alerts(kind)
+		if err != nil {
+			responseError(w, err)
+			return
+		}
+
+		data := make([]BodyResponseAlertJSON, 0, len(alerts))
+		for _, alert := range alerts {
+			data = append(data, BodyResponseAlertJSON{
+				ID:         alert.ID,
+				Kind:       string(alert.Kind),
+				ProductID:  alert.ProductID,
+				CodeValue:  alert.CodeValue,
+				Quantity:   alert.Quantity,
+				Expiration: alert.Expiration,
+				Message:    alert.Message,
+				RaisedAt:   alert.RaisedAt,
+			})
+		}
+
+		response.JSON(w, http.StatusOK, map[string]any{
+			"Message": "Alerts found successfully",
+			"data":    data,
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package handler
+
+import (
+	"app/internal"
+	"app/platform/web/response"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+)
+
+var (
+	// ErrInvalidID is used when the id in the url is not a number
+	ErrInvalidID = errors.New("invalid id")
+	// ErrInvalidVersion is used when the version in the url is not a number
+	ErrInvalidVersion = errors.New("invalid version")
+	// ErrInvalidBody is used when the request body can not be read or decoded
+	ErrInvalidBody = errors.New("invalid body")
+	// ErrUnsupportedMediaType is used when the request body is sent with a content type the endpoint does not accept
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+	// ErrInvalidPatch is used when a patch document is malformed
+	ErrInvalidPatch = errors.New("invalid patch")
+	// ErrPatchConflict is used when a patch can not be applied to the current product, e.g. a test operation failed
+	ErrPatchConflict = errors.New("patch can not be applied")
+)
+
+// machine readable codes of the json error responses
+const (
+	ErrCodeInvalidID                = "invalid_id"
+	ErrCodeInvalidVersion           = "invalid_version"
+	ErrCodeInvalidBody              = "invalid_body"
+	ErrCodeValidation               = "validation_failed"
+	ErrCodeFieldRequired            = "field_required"
+	ErrCodeFieldFormat              = "field_format"
+	ErrCodeProductCodeAlreadyExists = "product_code_already_exists"
+	ErrCodeProductNotFound          = "product_not_found"
+	ErrCodeProductVersionNotFound   = "product_version_not_found"
+	ErrCodeStockInsufficient        = "insufficient_stock"
+	ErrCodeReservationNotFound      = "reservation_not_found"
+	ErrCodeReservationNotActive     = "reservation_not_active"
+	ErrCodeQueryParam               = "invalid_query_param"
+	ErrCodeBatchInvalid             = "invalid_batch"
+	ErrCodePreconditionFailed       = "precondition_failed"
+	ErrCodeUnsupportedMediaType     = "unsupported_media_type"
+	ErrCodeInvalidPatch             = "invalid_patch"
+	ErrCodePatchConflict            = "patch_conflict"
+	ErrCodeInternal                 = "internal_error"
+)
+
+// errorCode returns the status code and the machine readable code of err,
+// derived from the sentinel error it wraps
+func errorCode(err error) (statusCode int, code string) {
+	switch {
+	case errors.Is(err, ErrInvalidID):
+		return http.StatusBadRequest, ErrCodeInvalidID
+	case errors.Is(err, ErrInvalidVersion):
+		return http.StatusBadRequest, ErrCodeInvalidVersion
+	case errors.Is(err, ErrInvalidBody):
+		return http.StatusBadRequest, ErrCodeInvalidBody
+	case errors.Is(err, ErrUnsupportedMediaType):
+		return http.StatusUnsupportedMediaType, ErrCodeUnsupportedMediaType
+	case errors.Is(err, ErrInvalidPatch):
+		return http.StatusBadRequest, ErrCodeInvalidPatch
+	case errors.Is(err, ErrPatchConflict):
+		return http.StatusConflict, ErrCodePatchConflict
+	case errors.Is(err, internal.ErrValidation):
+		return http.StatusUnprocessableEntity, ErrCodeValidation
+	case errors.Is(err, internal.ErrFieldRequired):
+		return http.StatusBadRequest, ErrCodeFieldRequired
+	case errors.Is(err, internal.ErrFieldFormat):
+		return http.StatusBadRequest, ErrCodeFieldFormat
+	case errors.Is(err, internal.ErrProductCodeAlreadyExists):
+		return http.StatusBadRequest, ErrCodeProductCodeAlreadyExists
+	case errors.Is(err, internal.ErrProductNotFound), errors.Is(err, internal.ErrProductID):
+		return http.StatusNotFound, ErrCodeProductNotFound
+	case errors.Is(err, internal.ErrProductVersionNotFound):
+		return http.StatusNotFound, ErrCodeProductVersionNotFound
+	case errors.Is(err, internal.ErrQueryParam):
+		return http.StatusBadRequest, ErrCodeQueryParam
+	case errors.Is(err, internal.ErrBatchSize), errors.Is(err, internal.ErrBatchMode):
+		return http.StatusBadRequest, ErrCodeBatchInvalid
+	case errors.Is(err, internal.ErrStockInsufficient):
+		return http.StatusConflict, ErrCodeStockInsufficient
+	case errors.Is(err, internal.ErrReservationNotFound):
+		return http.StatusNotFound, ErrCodeReservationNotFound
+	case errors.Is(err, internal.ErrReservationNotActive):
+		return http.StatusConflict, ErrCodeReservationNotActive
+	case errors.Is(err, internal.ErrProductVersionConflict):
+		return http.StatusPreconditionFailed, ErrCodePreconditionFailed
+	}
+	return http.StatusInternalServerError, ErrCodeInternal
+}
+
+// formatMessage returns the message of a value that can not be decoded into its product field
+func formatMessage(err error) string {
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.Is(err, internal.ErrDateFormat):
+		return "must be a dd/mm/yyyy or yyyy-mm-dd date"
+	case errors.Is(err, internal.ErrMoneyFormat):
+		return "must be a decimal amount"
+	case errors.Is(err, internal.ErrMoneyPrecision):
+		return "has more decimals than its currency allows"
+	case errors.Is(err, internal.ErrCurrency):
+		return "currency must be a supported ISO-4217 code"
+	case errors.As(err, &typeErr):
+		switch typeErr.Type.Kind() {
+		case reflect.Bool:
+			return "must be a boolean"
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return "must be an integer"
+		case reflect.String:
+			return "must be a string"
+		}
+	}
+	return "has an invalid value"
+}
+
+// errorField returns the field that caused err, if any
+func errorField(err error) string {
+	var fieldErr *internal.FieldError
+	if errors.As(err, &fieldErr) {
+		return fieldErr.Field
+	}
+	return ""
+}
+
+// BodyResponseValidationErrorJSON is a field that failed validation
+type BodyResponseValidationErrorJSON struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// validationErrors returns the json list of the validation errors wrapped by err, nil if there are none
+func validationErrors(err error) []BodyResponseValidationErrorJSON {
+	var ve internal.ValidationErrors
+	if !errors.As(err, &ve) {
+		return nil
+	}
+
+	list := make([]BodyResponseValidationErrorJSON, 0, len(ve))
+	for _, e := range ve {
+		list = append(list, BodyResponseValidationErrorJSON{
+			Field:   e.Field,
+			Rule:    e.Rule,
+			Message: e.Message,
+		})
+	}
+	return list
+}
+
+// responseError writes err as a json error response.
+// Validation errors are listed field by field and unexpected errors are reported
+// without their message so internals are not leaked.
+func responseError(w http.ResponseWriter, err error) {
+	statusCode, code := errorCode(err)
+
+	if list := validationErrors(err); list != nil {
+		response.ErrorDetails(w, statusCode, code, internal.ErrValidation.Error(), list)
+		return
+	}
+
+	message := err.Error()
+	if statusCode == http.StatusInternalServerError {
+		message = "internal server error"
+	}
+
+	response.ErrorCode(w, statusCode, code, errorField(err), message)
+}
+
+// BodyResponseItemErrorJSON is the error of one item of a batch or an import
+type BodyResponseItemErrorJSON struct {
+	Code    string                            `json:"code"`
+	Field   string                            `json:"field,omitempty"`
+	Message string                            `json:"message"`
+	Errors  []BodyResponseValidationErrorJSON `json:"errors,omitempty"`
+}
+
+// itemError returns the json error of one item of a batch or an import
+func itemError(err error) *BodyResponseItemErrorJSON {
+	statusCode, code := errorCode(err)
+
+	message := err.Error()
+	if statusCode == http.StatusInternalServerError {
+		message = "internal server error"
+	}
+
+	if list := validationErrors(err); list != nil {
+		message = internal.ErrValidation.Error()
+	}
+
+	return &BodyResponseItemErrorJSON{
+		Code:    code,
+		Field:   errorField(err),
+		Message: message,
+		Errors:  validationErrors(err),
+	}
+}
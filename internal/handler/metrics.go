@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"app/internal"
+	"app/platform/web/metrics"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// routeUnmatched is the route label of the requests no route matched
+const routeUnmatched = "unmatched"
+
+// methods are the method labels, any other method being recorded as other so clients can not make series at will
+var methods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodOptions: true,
+}
+
+// ValidationRecorder records the fields failing validation in the responses, see NewDefaultProducts
+type ValidationRecorder interface {
+	RecordValidationFailure(field, rule string)
+}
+
+// Metrics records the requests served by the router and, as a ValidationRecorder, the validation failures of their responses
+type Metrics struct {
+	requests           *metrics.Counter
+	duration           *metrics.Histogram
+	validationFailures *metrics.Counter
+}
+
+// NewMetrics registers the metrics of the requests in reg
+func NewMetrics(reg *metrics.Registry) *Metrics {
+	return &Metrics{
+		requests: reg.Counter("http_requests_total",
+			"Requests served, by method, route pattern and status code.",
+			"method", "route", "status"),
+		duration: reg.Histogram("http_request_duration_seconds",
+			"Latency of the requests, by method, route pattern and status code.",
+			nil, "method", "route", "status"),
+		validationFailures: reg.Counter("validation_failures_total",
+			"Fields failing validation in the responses, by field and rule.",
+			"field", "rule"),
+	}
+}
+
+// Middleware records the request under the route pattern it matched, e.g. /products/{id},
+// so each id does not make a series of its own
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		mw := &metricsWriter{ResponseWriter: w}
+
+		next.ServeHTTP(mw, r)
+
+		// the pattern is complete once the router is done with the request
+		route := routeUnmatched
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+		method := r.Method
+		if !methods[method] {
+			method = "other"
+		}
+		status := mw.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		m.requests.Inc(method, route, strconv.Itoa(status))
+		m.duration.Observe(time.Since(start).Seconds(), method, route, strconv.Itoa(status))
+	})
+}
+
+// metricsWriter keeps the status code of the response
+type metricsWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (mw *metricsWriter) WriteHeader(statusCode int) {
+	if mw.status == 0 {
+		mw.status = statusCode
+	}
+	mw.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (mw *metricsWriter) Write(b []byte) (int, error) {
+	if mw.status == 0 {
+		mw.status = http.StatusOK
+	}
+	return mw.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the wrapped writer, for http.ResponseController
+func (mw *metricsWriter) Unwrap() http.ResponseWriter {
+	return mw.ResponseWriter
+}
+
+func (m *Metrics) RecordValidationFailure(field, rule string) {
+	m.validationFailures.Inc(field, rule)
+}
+
+// recordValidationFailures reports the fields failing validation in err to the recorder, if any
+func (d *DefaultProduct) recordValidationFailures(err error) {
+	if d.vr == nil {
+		return
+	}
+
+	var failures internal.ValidationErrors
+	var fieldErr *internal.FieldError
+	switch {
+	case errors.As(err, &failures):
+	case errors.Is(err, internal.ErrFieldRequired) && errors.As(err, &fieldErr):
+		failures.Add(fieldErr.Field, internal.RuleRequired, "")
+	case errors.Is(err, internal.ErrFieldFormat) && errors.As(err, &fieldErr):
+		failures.Add(fieldErr.Field, internal.RuleFormat, "")
+	}
+
+	for _, failure := range failures {
+		d.vr.RecordValidationFailure(failure.Field, failure.Rule)
+	}
+}
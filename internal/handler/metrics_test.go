@@ -0,0 +1,49 @@
+package handler_test
+
+import (
+	"app/internal/handler"
+	"app/internal/repository"
+	"app/internal/service"
+	"app/platform/web/metrics"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// opaqueWriter wraps the response writer without exposing it, as some middlewares do
+type opaqueWriter struct {
+	http.ResponseWriter
+}
+
+// Tests for Metrics
+func TestMetrics(t *testing.T) {
+	t.Run("success - the validation failures are recorded behind a middleware wrapping the writer", func(t *testing.T) {
+		// arrange
+		reg := metrics.NewRegistry()
+		hm := handler.NewMetrics(reg)
+		sv := service.NewProductDefault(repository.NewProductMap(nil, 0), nil, nil, repository.NewReservationMap())
+		hd := handler.NewDefaultProducts(sv, hm)
+		rt := chi.NewRouter()
+		rt.Use(hm.Middleware)
+		rt.Use(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				next.ServeHTTP(opaqueWriter{w}, r)
+			})
+		})
+		rt.Post("/products", hd.Create())
+
+		// act
+		res, _ := serve(t, rt, http.MethodPost, "/products", `{"name":"","quantity":"3","code_value":"code","expiration":"2030-01-01","price":"1.50"}`)
+
+		// assert
+		require.Equal(t, http.StatusUnprocessableEntity, res.Code)
+		out := httptest.NewRecorder()
+		reg.Handler().ServeHTTP(out, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		require.Contains(t, out.Body.String(), `validation_failures_total{field="name",rule="required"} 1`+"\n")
+		require.Contains(t, out.Body.String(), `validation_failures_total{field="quantity",rule="format"} 1`+"\n")
+		require.Contains(t, out.Body.String(), `http_requests_total{method="POST",route="/products",status="422"} 1`+"\n")
+	})
+}
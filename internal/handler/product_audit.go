@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"app/internal"
+	"app/platform/web/response"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// ActorHeader is the request header naming who makes the request, recorded in the audit log.
+// Requests are not authenticated, so the actor is trusted as sent.
+const ActorHeader = "X-Actor"
+
+// Actor is a middleware carrying the actor of the request in its context, see internal.ActorFromContext
+func Actor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if actor := r.Header.Get(ActorHeader); actor != "" {
+			r = r.WithContext(internal.ContextWithActor(r.Context(), actor))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// BodyResponseAuditChangeJSON is the value of a field before and after a mutation
+type BodyResponseAuditChangeJSON struct {
+	Field  string `json:"field"`
+	Before any    `json:"before"`
+	After  any    `json:"after"`
+}
+
+// BodyResponseAuditEntryJSON is a mutation of a product
+type BodyResponseAuditEntryJSON struct {
+	ID        int                           `json:"id"`
+	ProductID int                           `json:"product_id"`
+	Actor     string                        `json:"actor"`
+	Timestamp time.Time                     `json:"timestamp"`
+	Operation string                        `json:"operation"`
+	Changes   []BodyResponseAuditChangeJSON `json:"changes"`
+}
+
+// History lists the mutations of the product, oldest first
+func (d *DefaultProduct) History() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			d.responseError(w, ErrInvalidID)
+			return
+		}
+
+		entries, err := d.sv.History(id)
+		if err != nil {
+			d.responseError(w, err)
+			return
+		}
+
+		data := make([]BodyResponseAuditEntryJSON, 0, len(entries))
+		for _, entry := range entries {
+			changes := make([]BodyResponseAuditChangeJSON, 0, len(entry.Changes))
+			for _, change := range entry.Changes {
+				changes = append(changes, BodyResponseAuditChangeJSON{
+					Field:  change.Field,
+					Before: change.Before,
+					After:  change.After,
+				})
+			}
+
+			data = append(data, BodyResponseAuditEntryJSON{
+				ID:        entry.ID,
+				ProductID: entry.ProductID,
+				Actor:     entry.Actor,
+				Timestamp: entry.Timestamp,
+				Operation: string(entry.Operation),
+				Changes:   changes,
+			})
+		}
+
+		response.JSON(w, http.StatusOK, map[string]any{
+			"Message": "Product history found successfully",
+			"data":    data,
+		})
+	}
+}
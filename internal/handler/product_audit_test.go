@@ -0,0 +1,97 @@
+package handler_test
+
+import (
+	"app/internal"
+	"app/internal/handler"
+	"app/internal/repository"
+	"app/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for Actor
+func TestActor(t *testing.T) {
+	cases := []struct {
+		name     string
+		headers  []string
+		expected string
+	}{
+		{name: "the header is carried", headers: []string{handler.ActorHeader, "alice"}, expected: "alice"},
+		{name: "without header the actor is anonymous", expected: internal.AnonymousActor},
+		{name: "an empty header is anonymous", headers: []string{handler.ActorHeader, ""}, expected: internal.AnonymousActor},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run("success - "+c.name, func(t *testing.T) {
+			// arrange
+			var actor string
+			h := handler.Actor(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				actor = internal.ActorFromContext(r.Context())
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for i := 0; i+1 < len(c.headers); i += 2 {
+				req.Header.Set(c.headers[i], c.headers[i+1])
+			}
+
+			// act
+			h.ServeHTTP(httptest.NewRecorder(), req)
+
+			// assert
+			require.Equal(t, c.expected, actor)
+		})
+	}
+}
+
+// Tests for DefaultProduct.History
+func TestDefaultProduct_History(t *testing.T) {
+	// newAuditedRouter routes the product handlers over a service recording its mutations, behind the Actor middleware
+	newAuditedRouter := func() http.Handler {
+		sv := service.NewProductDefault(repository.NewProductMap(nil, 0), repository.NewAuditMap(), nil, repository.NewReservationMap())
+		hd := handler.NewDefaultProducts(sv, nil)
+
+		rt := chi.NewRouter()
+		rt.Use(handler.Actor)
+		rt.Post("/products", hd.Create())
+		rt.Patch("/products/{id}", hd.UpdatePartial())
+		rt.Get("/products/{id}/history", hd.History())
+		return rt
+	}
+
+	t.Run("success - the mutations are listed with their actor", func(t *testing.T) {
+		// arrange
+		h := newAuditedRouter()
+		res, _ := serve(t, h, http.MethodPost, "/products", productJSON("code"), handler.ActorHeader, "alice")
+		require.Equal(t, http.StatusCreated, res.Code)
+		res, _ = serve(t, h, http.MethodPatch, "/products/1", `{"name":"renamed"}`)
+		require.Equal(t, http.StatusOK, res.Code)
+
+		// act
+		res, body := serve(t, h, http.MethodGet, "/products/1/history", "")
+
+		// assert
+		require.Equal(t, http.StatusOK, res.Code)
+		data := body["data"].([]any)
+		require.Len(t, data, 2)
+		created := data[0].(map[string]any)
+		require.Equal(t, "alice", created["actor"])
+		require.Equal(t, "create", created["operation"])
+		updated := data[1].(map[string]any)
+		require.Equal(t, internal.AnonymousActor, updated["actor"])
+		require.Equal(t, []any{map[string]any{"field": "name", "before": "product", "after": "renamed"}}, updated["changes"])
+	})
+
+	t.Run("error - product not found", func(t *testing.T) {
+		// arrange
+		h := newAuditedRouter()
+
+		// act
+		res, _ := serve(t, h, http.MethodGet, "/products/1/history", "")
+
+		// assert
+		require.Equal(t, http.StatusNotFound, res.Code)
+	})
+}
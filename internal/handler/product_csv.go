@@ -0,0 +1,285 @@
+package handler
+
+import (
+	"app/internal"
+	"app/platform/web/response"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// productCSVHeader are the columns of the product catalog csv
+var productCSVHeader = []string{"id", "name", "quantity", "code_value", "is_published", "expiration", "price", "currency"}
+
+const (
+	// exportPageSize is the amount of products read from the service per page while exporting
+	exportPageSize = 100
+	// importChunkSize is the amount of rows sent to the service per batch while importing
+	importChunkSize = 500
+)
+
+// BodyResponseImportRowJSON is the outcome of one row of an imported csv
+type BodyResponseImportRowJSON struct {
+	// Row is the line of the row in the csv, the header being line 1
+	Row   int                        `json:"row"`
+	ID    int                        `json:"id,omitempty"`
+	Error *BodyResponseItemErrorJSON `json:"error,omitempty"`
+}
+
+// Export streams the whole product catalog as csv, ordered by id
+func (d *DefaultProduct) Export() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		// the first page is read before writing so failures can still be reported,
+		// the next ones start after the last id exported so creations and deletions do not shift them
+		query := internal.ProductQuery{Limit: exportPageSize}
+		products, _, err := d.sv.Find(&query)
+		if err != nil {
+			d.responseError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="products.csv"`)
+		w.WriteHeader(http.StatusOK)
+
+		cw := csv.NewWriter(w)
+		cw.Write(productCSVHeader)
+
+		for {
+			for _, product := range products {
+				cw.Write([]string{
+					strconv.Itoa(product.ID),
+					product.Name,
+					strconv.Itoa(product.Quantity),
+					product.CodeValue,
+					strconv.FormatBool(product.IsPublished),
+					product.Expiration.String(),
+					product.Price.String(),
+					product.Price.Currency,
+				})
+			}
+			cw.Flush()
+			if flusher, ok := w.(http.Flusher); ok {
+				flusher.Flush()
+			}
+
+			if len(products) < exportPageSize {
+				return
+			}
+			query.AfterID = products[len(products)-1].ID
+
+			// the status is already sent, a failure can only cut the stream
+			products, _, err = d.sv.Find(&query)
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Import creates the products of a csv sent as the body or as the "file" field of a multipart form.
+// The header row names the columns, the id column being ignored.
+// With dry_run=true the rows are validated but no product is created.
+func (d *DefaultProduct) Import() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		dryRun := false
+		if v := r.URL.Query().Get("dry_run"); v != "" {
+			var err error
+			if dryRun, err = strconv.ParseBool(v); err != nil {
+				d.responseError(w, internal.NewFieldError(internal.ErrQueryParam, "dry_run"))
+				return
+			}
+		}
+
+		body, err := csvBody(r)
+		if err != nil {
+			d.responseError(w, err)
+			return
+		}
+		defer body.Close()
+
+		cr := csv.NewReader(body)
+		cr.FieldsPerRecord = -1
+		header, err := cr.Read()
+		if err != nil {
+			d.responseError(w, fmt.Errorf("%w: missing csv header", ErrInvalidBody))
+			return
+		}
+		columns, err := csvColumns(header)
+		if err != nil {
+			d.responseError(w, err)
+			return
+		}
+
+		var rows []BodyResponseImportRowJSON
+		var products []*internal.Product
+		var positions []int
+		codes := make(map[string]int)
+		for line := 2; ; line++ {
+			record, err := cr.Read()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			rows = append(rows, BodyResponseImportRowJSON{Row: line})
+			i := len(rows) - 1
+			if err != nil {
+				var parseErr *csv.ParseError
+				if !errors.As(err, &parseErr) {
+					d.responseError(w, fmt.Errorf("%w: %v", ErrInvalidBody, err))
+					return
+				}
+				rows[i].Error = d.itemError(fmt.Errorf("%w: %v", ErrInvalidBody, parseErr.Err))
+				continue
+			}
+
+			product, err := csvProduct(record, columns)
+			if err != nil {
+				rows[i].Error = d.itemError(err)
+				continue
+			}
+			if row, ok := codes[product.CodeValue]; ok {
+				rows[i].Error = d.itemError(fmt.Errorf("%w, already used in row %d", internal.NewFieldError(internal.ErrProductCodeAlreadyExists, "code_value"), row))
+				continue
+			}
+			codes[product.CodeValue] = line
+
+			products = append(products, product)
+			positions = append(positions, i)
+		}
+
+		if len(rows) == 0 {
+			d.responseError(w, fmt.Errorf("%w: csv has no rows", ErrInvalidBody))
+			return
+		}
+
+		for start := 0; start < len(products); start += importChunkSize {
+			end := min(start+importChunkSize, len(products))
+
+			// the rows are imported in best effort, a dry run only validating them
+			var results []internal.BatchResult
+			var err error
+			if dryRun {
+				results, err = d.sv.ValidateBatch(products[start:end])
+			} else {
+				results, err = d.sv.SaveBatch(r.Context(), products[start:end], internal.BatchModeBestEffort)
+			}
+			if err != nil {
+				d.responseError(w, err)
+				return
+			}
+
+			for _, result := range results {
+				i := positions[start+result.Index]
+				rows[i].ID = result.ID
+				if result.Err != nil {
+					rows[i].Error = d.itemError(result.Err)
+				}
+			}
+		}
+
+		valid, failed := 0, 0
+		for _, row := range rows {
+			if row.Error != nil {
+				failed++
+				continue
+			}
+			valid++
+		}
+
+		message := "Products imported"
+		if dryRun {
+			message = "Products validated, nothing was imported"
+		}
+
+		response.JSON(w, http.StatusOK, map[string]any{
+			"Message": message,
+			"data": map[string]any{
+				"dry_run": dryRun,
+				"valid":   valid,
+				"failed":  failed,
+				"rows":    rows,
+			},
+		})
+	}
+}
+
+// csvBody returns the uploaded csv, either the "file" field of a multipart form or the raw body
+func csvBody(r *http.Request) (io.ReadCloser, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		return r.Body, nil
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		return nil, internal.NewFieldError(internal.ErrFieldRequired, "file")
+	}
+	return file, nil
+}
+
+// csvColumns maps each known column of the header to its position
+func csvColumns(header []string) (map[string]int, error) {
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	for _, name := range ProductValidator.Required() {
+		if _, ok := columns[name]; !ok {
+			return nil, internal.NewFieldError(internal.ErrFieldRequired, name)
+		}
+	}
+
+	return columns, nil
+}
+
+// csvProduct converts a csv record into a product
+func csvProduct(record []string, columns map[string]int) (*internal.Product, error) {
+	field := func(name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	product := internal.Product{
+		Name:      field("name"),
+		CodeValue: field("code_value"),
+	}
+
+	var err error
+	if v := field("expiration"); v != "" {
+		if product.Expiration, err = internal.ParseDate(v); err != nil {
+			return nil, internal.NewFieldError(internal.ErrFieldFormat, "expiration")
+		}
+	}
+	if v := field("quantity"); v != "" {
+		if product.Quantity, err = strconv.Atoi(v); err != nil {
+			return nil, internal.NewFieldError(internal.ErrFieldFormat, "quantity")
+		}
+	}
+	if v := field("price"); v != "" {
+		currency := field("currency")
+		if currency == "" {
+			currency = internal.DefaultCurrency
+		}
+		if product.Price, err = internal.ParseMoney(v, currency); err != nil {
+			return nil, internal.NewFieldError(internal.ErrFieldFormat, "price")
+		}
+	}
+	if v := field("is_published"); v != "" {
+		if product.IsPublished, err = strconv.ParseBool(v); err != nil {
+			return nil, internal.NewFieldError(internal.ErrFieldFormat, "is_published")
+		}
+	}
+
+	return &product, nil
+}
@@ -0,0 +1,126 @@
+package handler_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"encoding/csv"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for DefaultProduct.Export
+func TestDefaultProduct_Export(t *testing.T) {
+	// newCatalog returns a repository of n products, the even ones being deleted
+	newCatalog := func(t *testing.T, n int) *repository.ProductMap {
+		rp := repository.NewProductMap(nil, 0)
+		for i := 1; i <= n; i++ {
+			product := internal.Product{Name: "product", Quantity: i, CodeValue: "code" + strconv.Itoa(i), IsPublished: true,
+				Expiration: internal.NewDate(2030, time.January, 1), Price: internal.Money{Amount: 150, Currency: "EUR"}}
+			require.NoError(t, rp.Save(&product))
+			if i%2 == 0 {
+				require.NoError(t, rp.Delete(product.ID))
+			}
+		}
+		return rp
+	}
+
+	cases := []struct {
+		name     string
+		products int
+		// expected are the ids of the rows exported
+		expected []int
+	}{
+		{name: "empty catalog - only the header", products: 0, expected: []int{}},
+		{name: "one page - deleted products are left out", products: 4, expected: []int{1, 3}},
+		{name: "several pages - every active product once, by id", products: 450, expected: func() []int {
+			ids := []int{}
+			for i := 1; i <= 450; i += 2 {
+				ids = append(ids, i)
+			}
+			return ids
+		}()},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run("success - "+c.name, func(t *testing.T) {
+			// arrange
+			h := newRouter(newCatalog(t, c.products))
+
+			// act
+			res, _ := serve(t, h, http.MethodGet, "/products/export.csv", "")
+
+			// assert
+			require.Equal(t, http.StatusOK, res.Code)
+			require.Equal(t, "text/csv; charset=utf-8", res.Header().Get("Content-Type"))
+			require.Equal(t, `attachment; filename="products.csv"`, res.Header().Get("Content-Disposition"))
+			records, err := csv.NewReader(res.Body).ReadAll()
+			require.NoError(t, err)
+			require.Equal(t, []string{"id", "name", "quantity", "code_value", "is_published", "expiration", "price", "currency"}, records[0])
+			ids := []int{}
+			for _, record := range records[1:] {
+				id, err := strconv.Atoi(record[0])
+				require.NoError(t, err)
+				ids = append(ids, id)
+			}
+			require.Equal(t, c.expected, ids)
+			if len(records) > 1 {
+				require.Equal(t, []string{"1", "product", "1", "code1", "true", "2030-01-01", "1.50", "EUR"}, records[1])
+			}
+		})
+	}
+}
+
+// Tests for DefaultProduct.Import
+func TestDefaultProduct_Import(t *testing.T) {
+	csv := "name,quantity,code_value,is_published,expiration,price\n" +
+		"first,1,first,true,2030-01-01,1.50\n" +
+		"second,2,taken,true,2030-01-01,2.50\n" +
+		",3,invalid,true,2030-01-01,3.50\n"
+
+	// newCatalog returns a repository where the code taken is used
+	newCatalog := func(t *testing.T) *repository.ProductMap {
+		rp := repository.NewProductMap(nil, 0)
+		taken := internal.Product{Name: "taken", Quantity: 1, CodeValue: "taken",
+			Expiration: internal.NewDate(2030, time.January, 1), Price: internal.Money{Amount: 100, Currency: "USD"}}
+		require.NoError(t, rp.Save(&taken))
+		return rp
+	}
+
+	t.Run("success - the valid rows are imported", func(t *testing.T) {
+		// arrange
+		rp := newCatalog(t)
+		h := newRouter(rp)
+
+		// act
+		res, body := serve(t, h, http.MethodPost, "/products/import", csv, "Content-Type", "text/csv")
+
+		// assert
+		require.Equal(t, http.StatusOK, res.Code)
+		data := body["data"].(map[string]any)
+		require.Equal(t, false, data["dry_run"])
+		require.Equal(t, float64(1), data["valid"])
+		_, total, _ := rp.Find(internal.ProductQuery{})
+		require.Equal(t, 2, total)
+	})
+
+	t.Run("success - a dry run validates the rows without importing them", func(t *testing.T) {
+		// arrange
+		rp := newCatalog(t)
+		h := newRouter(rp)
+
+		// act
+		res, body := serve(t, h, http.MethodPost, "/products/import?dry_run=true", csv, "Content-Type", "text/csv")
+
+		// assert
+		require.Equal(t, http.StatusOK, res.Code)
+		data := body["data"].(map[string]any)
+		require.Equal(t, true, data["dry_run"])
+		require.Equal(t, float64(1), data["valid"])
+		_, total, _ := rp.Find(internal.ProductQuery{})
+		require.Equal(t, 1, total)
+	})
+}
@@ -2,90 +2,169 @@ package handler
 
 import (
 	"app/internal"
+	"app/platform/web/patch"
 	"app/platform/web/request"
 	"app/platform/web/response"
+	"app/platform/web/validate"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
+	"net/url"
+	"reflect"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 )
 
 type DefaultProduct struct {
 	sv internal.ProductService
+	// vr records the fields failing validation in the responses, nil disables the recording
+	vr ValidationRecorder
 }
 
+// BodyRequestProductJSON is the product sent by clients
 type BodyRequestProductJSON struct {
-	Name        string  `json:"name"`
-	Quantity    int     `json:"quantity"`
-	CodeValue   string  `json:"code_value"`
-	IsPublished bool    `json:"is_published"`
-	Expiration  string  `json:"expiration"`
-	Price       float64 `json:"price"`
+	Name        string         `json:"name"`
+	Quantity    int            `json:"quantity"`
+	CodeValue   string         `json:"code_value"`
+	IsPublished bool           `json:"is_published"`
+	Expiration  internal.Date  `json:"expiration"`
+	Price       internal.Money `json:"price"`
 }
 
+// ProductValidator holds the rules declared on internal.Product, bound to BodyRequestProductJSON:
+// it panics when the body misses a field with rules. The handlers use it to check the required keys.
+var ProductValidator = validate.MustNewFor(internal.Product{}, BodyRequestProductJSON{})
+
 type BodyResponseProductJSON struct {
-	ID          int     `json:"id"`
-	Name        string  `json:"name"`
-	Quantity    int     `json:"quantity"`
-	CodeValue   string  `json:"code_value"`
-	IsPublished bool    `json:"is_published"`
-	Expiration  string  `json:"expiration"`
-	Price       float64 `json:"price"`
+	ID          int            `json:"id"`
+	Name        string         `json:"name"`
+	Quantity    int            `json:"quantity"`
+	CodeValue   string         `json:"code_value"`
+	IsPublished bool           `json:"is_published"`
+	Expiration  internal.Date  `json:"expiration"`
+	Price       internal.Money `json:"price"`
+	Version     int            `json:"version"`
+	DeletedAt   *time.Time     `json:"deleted_at,omitempty"`
 }
 
-func NewDefaultProducts(sv internal.ProductService) *DefaultProduct {
+// NewDefaultProducts returns the product handlers over sv, recording the validation failures in vr when it is not nil
+func NewDefaultProducts(sv internal.ProductService, vr ValidationRecorder) *DefaultProduct {
 	return &DefaultProduct{
 		sv: sv,
+		vr: vr,
 	}
 }
 
-func ValidateKeyExistance(mp map[string]any, keys ...string) error {
+// responseError writes err as a json error response, see responseError, recording its validation failures
+func (d *DefaultProduct) responseError(w http.ResponseWriter, err error) {
+	d.recordValidationFailures(err)
+	responseError(w, err)
+}
+
+// itemError returns the json error of one item of a batch or an import, see itemError, recording its validation failures
+func (d *DefaultProduct) itemError(err error) *BodyResponseItemErrorJSON {
+	d.recordValidationFailures(err)
+	return itemError(err)
+}
+
+// ValidateKeyExistance checks every key is present in mp, reporting the missing ones as internal.ValidationErrors
+func ValidateKeyExistance[V any](mp map[string]V, keys ...string) error {
+	var ve internal.ValidationErrors
 	for _, key := range keys {
 		if _, ok := mp[key]; !ok {
-			return fmt.Errorf("key %s does not exist", key)
+			ve.Add(key, internal.RuleRequired, "field is required")
+		}
+	}
+	return ve.Err()
+}
+
+// decodeProduct decodes the json object in data over body field by field, so a client gets every problem at once:
+// the keys missing among required and the values that can not be decoded are returned as violations.
+// The fields failing to decode are left unchanged, see decodeField. Data that is not a json object is an invalid body.
+func decodeProduct(data []byte, body *BodyRequestProductJSON, required []string) (internal.ValidationErrors, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil || fields == nil {
+		return nil, fmt.Errorf("%w: must be a json object", ErrInvalidBody)
+	}
+
+	var ve internal.ValidationErrors
+	if err := ValidateKeyExistance(fields, required...); err != nil {
+		errors.As(err, &ve)
+	}
+
+	rv := reflect.ValueOf(body).Elem()
+	for i := 0; i < rv.NumField(); i++ {
+		name, _, _ := strings.Cut(rv.Type().Field(i).Tag.Get("json"), ",")
+		raw, ok := fields[name]
+		if !ok {
+			continue
+		}
+		if err := decodeField(raw, rv.Field(i).Addr().Interface()); err != nil {
+			ve.Add(name, internal.RuleFormat, formatMessage(err))
 		}
 	}
+
+	return ve, nil
+}
+
+// decodeField decodes the json value into the field ptr points to, leaving it unchanged on failure.
+// A price sent without currency keeps the currency the field already has, e.g. the one of the patched product.
+func decodeField(raw json.RawMessage, ptr any) error {
+	price, ok := ptr.(*internal.Money)
+	if !ok || price.Currency == "" {
+		return json.Unmarshal(raw, ptr)
+	}
+
+	money, err := internal.ParseMoneyJSON(raw, price.Currency)
+	if err != nil {
+		return err
+	}
+	*price = money
 	return nil
 }
 
+// productError returns the violations found decoding the product along with the ones the service finds
+// in the fields that could be decoded, each field being reported by the first stage it failed
+func (d *DefaultProduct) productError(ve internal.ValidationErrors, product *internal.Product) error {
+	var found internal.ValidationErrors
+	if err := d.sv.Validate(product); err != nil && !errors.As(err, &found) {
+		return err
+	}
+
+	reported := make(map[string]bool, len(ve))
+	for _, e := range ve {
+		reported[e.Field] = true
+	}
+	for _, e := range found {
+		if !reported[e.Field] {
+			ve = append(ve, e)
+		}
+	}
+
+	return ve
+}
+
 func (d *DefaultProduct) Create() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 
-		var mp map[string]any
-
 		requestBody, err := io.ReadAll(r.Body)
 
 		if err != nil {
-			w.Header().Set("Content-Type", "text/plain")
-			w.WriteHeader(http.StatusBadRequest)
-			w.Write([]byte("invalid body"))
-			return
-		}
-
-		if err := json.Unmarshal(requestBody, &mp); err != nil {
-			w.Header().Set("Content-Type", "text/plain")
-			w.WriteHeader(http.StatusBadRequest)
-			w.Write([]byte("invalid body" + err.Error()))
-			return
-		}
-
-		if err := ValidateKeyExistance(mp, "name", "quantity", "code_value", "expiration", "price"); err != nil {
-			w.Header().Set("Content-Type", "text/plain")
-			w.WriteHeader(http.StatusBadRequest)
-			w.Write([]byte("invalid body " + err.Error()))
+			d.responseError(w, ErrInvalidBody)
 			return
 		}
 
 		var body BodyRequestProductJSON
 
-		if err := json.Unmarshal(requestBody, &body); err != nil {
-			w.Header().Set("Content-Type", "text/plain")
-			w.WriteHeader(http.StatusBadRequest)
-			w.Write([]byte("invalid body" + err.Error()))
+		ve, err := decodeProduct(requestBody, &body, ProductValidator.Required())
+		if err != nil {
+			d.responseError(w, err)
 			return
 		}
 
@@ -98,17 +177,13 @@ func (d *DefaultProduct) Create() http.HandlerFunc {
 			Price:       body.Price,
 		}
 
-		if err := d.sv.Save(&product); err != nil {
-			switch {
-			case errors.Is(err, internal.ErrFieldRequired), errors.Is(err, internal.ErrProductCodeAlreadyExists), errors.Is(err, internal.ErrFieldFormat):
-				w.Header().Set("Content-Type", "text/plain")
-				w.WriteHeader(http.StatusBadRequest)
-				w.Write([]byte("invalid body: " + err.Error()))
-			default:
-				w.Header().Set("Content-Type", "text/plain")
-				w.WriteHeader(http.StatusInternalServerError)
-				w.Write([]byte("internal server error"))
-			}
+		if len(ve) > 0 {
+			d.responseError(w, d.productError(ve, &product))
+			return
+		}
+
+		if err := d.sv.Save(r.Context(), &product); err != nil {
+			d.responseError(w, err)
 			return
 		}
 
@@ -120,11 +195,10 @@ func (d *DefaultProduct) Create() http.HandlerFunc {
 			IsPublished: product.IsPublished,
 			Expiration:  product.Expiration,
 			Price:       product.Price,
+			Version:     product.Version,
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusCreated)
-		json.NewEncoder(w).Encode(map[string]any{
+		response.JSON(w, http.StatusCreated, map[string]any{
 			"Message": "Product created successfully",
 			"data":    data,
 		})
@@ -133,31 +207,137 @@ func (d *DefaultProduct) Create() http.HandlerFunc {
 
 }
 
+// BodyResponseBatchResultJSON is the outcome of one product of a batch
+type BodyResponseBatchResultJSON struct {
+	Index int                        `json:"index"`
+	ID    int                        `json:"id,omitempty"`
+	Error *BodyResponseItemErrorJSON `json:"error,omitempty"`
+}
+
+// CreateBatch creates the array of products in the body.
+// The mode query parameter selects "atomic" (default, all or nothing) or "best_effort".
+func (d *DefaultProduct) CreateBatch() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		mode := internal.BatchMode(r.URL.Query().Get("mode"))
+		if mode == "" {
+			mode = internal.BatchModeAtomic
+		}
+		if mode != internal.BatchModeAtomic && mode != internal.BatchModeBestEffort {
+			d.responseError(w, fmt.Errorf("%w: %s", internal.ErrBatchMode, mode))
+			return
+		}
+
+		var items []json.RawMessage
+		if err := request.JSON(r, &items); err != nil {
+			d.responseError(w, fmt.Errorf("%w: %v", ErrInvalidBody, err))
+			return
+		}
+		// the undecodable items count, so the service can not be handed a batch bigger than the one sent
+		if len(items) == 0 || len(items) > internal.MaxBatchSize {
+			d.responseError(w, fmt.Errorf("%w: must have between 1 and %d products", internal.ErrBatchSize, internal.MaxBatchSize))
+			return
+		}
+
+		// decode each item, keeping track of its position in the batch
+		results := make([]BodyResponseBatchResultJSON, len(items))
+		products := make([]*internal.Product, 0, len(items))
+		positions := make([]int, 0, len(items))
+		for i, item := range items {
+			results[i].Index = i
+
+			var body BodyRequestProductJSON
+			ve, err := decodeProduct(item, &body, ProductValidator.Required())
+			if err != nil {
+				results[i].Error = d.itemError(err)
+				continue
+			}
+
+			product := &internal.Product{
+				Name:        body.Name,
+				Quantity:    body.Quantity,
+				CodeValue:   body.CodeValue,
+				IsPublished: body.IsPublished,
+				Expiration:  body.Expiration,
+				Price:       body.Price,
+			}
+			if len(ve) > 0 {
+				results[i].Error = d.itemError(d.productError(ve, product))
+				continue
+			}
+
+			products = append(products, product)
+			positions = append(positions, i)
+		}
+
+		// in atomic mode a single undecodable item rejects the batch
+		if mode == internal.BatchModeAtomic && len(products) != len(items) {
+			response.JSON(w, http.StatusBadRequest, map[string]any{
+				"Message": "Batch rejected, no product was created",
+				"data":    results,
+			})
+			return
+		}
+
+		// with every item undecodable there is nothing left to save
+		var batch []internal.BatchResult
+		var err error
+		if len(products) > 0 {
+			batch, err = d.sv.SaveBatch(r.Context(), products, mode)
+		}
+		if err != nil && !errors.Is(err, internal.ErrBatchRejected) {
+			d.responseError(w, err)
+			return
+		}
+
+		failed := len(items) - len(products)
+		for _, result := range batch {
+			i := positions[result.Index]
+			results[i].ID = result.ID
+			if result.Err != nil {
+				results[i].Error = d.itemError(result.Err)
+				failed++
+			}
+		}
+
+		switch {
+		case errors.Is(err, internal.ErrBatchRejected):
+			response.JSON(w, http.StatusBadRequest, map[string]any{
+				"Message": "Batch rejected, no product was created",
+				"data":    results,
+			})
+		case failed > 0:
+			response.JSON(w, http.StatusMultiStatus, map[string]any{
+				"Message": "Batch partially created",
+				"data":    results,
+			})
+		default:
+			response.JSON(w, http.StatusCreated, map[string]any{
+				"Message": "Batch created successfully",
+				"data":    results,
+			})
+		}
+	}
+}
+
 func (d *DefaultProduct) GetById() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 
 		id, err := strconv.Atoi(chi.URLParam(r, "id"))
 
 		if err != nil {
-			w.Header().Set("Content-Type", "text/plain")
-			w.WriteHeader(http.StatusBadRequest)
-			w.Write([]byte("invalid id"))
+			d.responseError(w, ErrInvalidID)
 			return
 		}
 
 		product, err := d.sv.GetById(id)
 
 		if err != nil {
-			switch {
-			case errors.Is(err, internal.ErrProductID):
-				w.Header().Set("Content-Type", "text/plain")
-				w.WriteHeader(http.StatusNotFound)
-				w.Write([]byte("product with the provided id not found"))
-				return
-			}
+			d.responseError(w, err)
+			return
 		}
 
-		response := BodyResponseProductJSON{
+		data := BodyResponseProductJSON{
 			ID:          product.ID,
 			Name:        product.Name,
 			Quantity:    product.Quantity,
@@ -165,49 +345,121 @@ func (d *DefaultProduct) GetById() http.HandlerFunc {
 			IsPublished: product.IsPublished,
 			Expiration:  product.Expiration,
 			Price:       product.Price,
+			Version:     product.Version,
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(map[string]any{
+		w.Header().Set("ETag", productETag(product.Version))
+		response.JSON(w, http.StatusOK, map[string]any{
 			"Message": "Product found successfully",
-			"data":    response,
+			"data":    data,
 		})
 
 	}
 }
 
-func (d *DefaultProduct) Update() http.HandlerFunc {
+func (d *DefaultProduct) GetByCode() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 
-		id, err := strconv.Atoi(chi.URLParam(r, "id"))
-
+		product, err := d.sv.GetByCode(chi.URLParam(r, "code_value"))
 		if err != nil {
-			response.Text(w, http.StatusBadRequest, "invalid id")
+			d.responseError(w, err)
 			return
 		}
 
-		bytes, err := io.ReadAll(r.Body)
+		data := BodyResponseProductJSON{
+			ID:          product.ID,
+			Name:        product.Name,
+			Quantity:    product.Quantity,
+			CodeValue:   product.CodeValue,
+			IsPublished: product.IsPublished,
+			Expiration:  product.Expiration,
+			Price:       product.Price,
+			Version:     product.Version,
+		}
 
+		w.Header().Set("ETag", productETag(product.Version))
+		response.JSON(w, http.StatusOK, map[string]any{
+			"Message": "Product found successfully",
+			"data":    data,
+		})
+	}
+}
+
+// GetAll lists the active products matching the query parameters, see parseProductQuery
+func (d *DefaultProduct) GetAll() http.HandlerFunc {
+	return d.find(false, "Products found successfully")
+}
+
+// Trash lists the deleted products matching the query parameters, see parseProductQuery
+func (d *DefaultProduct) Trash() http.HandlerFunc {
+	return d.find(true, "Deleted products found successfully")
+}
+
+// find lists the products of the trash (deleted) or the active ones
+func (d *DefaultProduct) find(deleted bool, message string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		query, err := parseProductQuery(r.URL.Query())
 		if err != nil {
-			response.Text(w, http.StatusBadRequest, "invalid body")
+			d.responseError(w, err)
 			return
 		}
+		query.Deleted = deleted
 
-		var bodyMap map[string]any
-		if err := json.Unmarshal(bytes, &bodyMap); err != nil {
-			response.Text(w, http.StatusBadRequest, "invalid body")
+		products, total, err := d.sv.Find(&query)
+		if err != nil {
+			d.responseError(w, err)
 			return
 		}
 
-		if err := ValidateKeyExistance(bodyMap, "name", "quantity", "code_value", "expiration", "price"); err != nil {
-			response.Text(w, http.StatusBadRequest, "invalid body")
+		data := make([]BodyResponseProductJSON, 0, len(products))
+		for _, product := range products {
+			data = append(data, BodyResponseProductJSON{
+				ID:          product.ID,
+				Name:        product.Name,
+				Quantity:    product.Quantity,
+				CodeValue:   product.CodeValue,
+				IsPublished: product.IsPublished,
+				Expiration:  product.Expiration,
+				Price:       product.Price,
+				Version:     product.Version,
+				DeletedAt:   deletedAt(product),
+			})
+		}
+
+		response.JSON(w, http.StatusOK, map[string]any{
+			"Message": message,
+			"data":    data,
+			"pagination": map[string]any{
+				"total":  total,
+				"limit":  query.Limit,
+				"offset": query.Offset,
+			},
+		})
+	}
+}
+
+func (d *DefaultProduct) Update() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+
+		if err != nil {
+			d.responseError(w, ErrInvalidID)
+			return
+		}
+
+		bytes, err := io.ReadAll(r.Body)
+
+		if err != nil {
+			d.responseError(w, ErrInvalidBody)
 			return
 		}
 
 		var body BodyRequestProductJSON
-		if err := json.Unmarshal(bytes, &body); err != nil {
-			response.Text(w, http.StatusBadRequest, "invalid body")
+		ve, err := decodeProduct(bytes, &body, ProductValidator.Required())
+		if err != nil {
+			d.responseError(w, err)
 			return
 		}
 
@@ -221,41 +473,78 @@ func (d *DefaultProduct) Update() http.HandlerFunc {
 			Price:       body.Price,
 		}
 
-		if err := d.sv.Update(&product); err != nil {
-			switch {
-			case errors.Is(err, internal.ErrProductNotFound):
-				response.Text(w, http.StatusNotFound, "product with the provided id not found")
-				return
-			case errors.Is(err, internal.ErrFieldRequired), errors.Is(err, internal.ErrProductCodeAlreadyExists), errors.Is(err, internal.ErrFieldFormat):
-				response.Text(w, http.StatusBadRequest, "invalid body: "+err.Error())
+		if len(ve) > 0 {
+			d.responseError(w, d.productError(ve, &product))
+			return
+		}
+
+		// a conditional update is only written over the version the client has seen
+		if r.Header.Get("If-Match") != "" {
+			current, err := d.sv.GetById(id)
+			if err != nil {
+				d.responseError(w, err)
 				return
-			default:
-				response.Text(w, http.StatusInternalServerError, "internal server error")
+			}
+			if !ifMatch(r, current.Version) {
+				d.responseError(w, internal.ErrProductVersionConflict)
 				return
 			}
+			product.Version = current.Version
+		}
+
+		if err := d.sv.Update(r.Context(), &product); err != nil {
+			d.responseError(w, err)
+			return
+		}
+
+		data := BodyResponseProductJSON{
+			ID:          product.ID,
+			Name:        product.Name,
+			Quantity:    product.Quantity,
+			CodeValue:   product.CodeValue,
+			IsPublished: product.IsPublished,
+			Expiration:  product.Expiration,
+			Price:       product.Price,
+			Version:     product.Version,
 		}
+
+		w.Header().Set("ETag", productETag(product.Version))
+		response.JSON(w, http.StatusOK, map[string]any{
+			"Message": "Product updated successfully",
+			"data":    data,
+		})
 	}
 }
 
+// UpdatePartial patches the product with the body, sent either as application/json (decoded over the product),
+// application/merge-patch+json (RFC 7396) or application/json-patch+json (RFC 6902)
 func (d *DefaultProduct) UpdatePartial() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 
 		id, err := strconv.Atoi(chi.URLParam(r, "id"))
 		if err != nil {
-			response.Text(w, http.StatusBadRequest, "invalid id")
+			d.responseError(w, ErrInvalidID)
+			return
+		}
+
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		switch mediaType {
+		case "application/json", patch.MediaTypeMergePatch, patch.MediaTypeJSONPatch:
+		default:
+			w.Header().Set("Accept-Patch", acceptPatch)
+			d.responseError(w, ErrUnsupportedMediaType)
 			return
 		}
 
 		product, err := d.sv.GetById(id)
 		if err != nil {
-			switch {
-			case errors.Is(err, internal.ErrProductNotFound):
-				response.Text(w, http.StatusNotFound, "product with the provided id not found")
-				return
-			default:
-				response.Text(w, http.StatusInternalServerError, "internal server error")
-				return
-			}
+			d.responseError(w, err)
+			return
+		}
+
+		if !ifMatch(r, product.Version) {
+			d.responseError(w, internal.ErrProductVersionConflict)
+			return
 		}
 
 		reqBody := BodyRequestProductJSON{
@@ -267,11 +556,23 @@ func (d *DefaultProduct) UpdatePartial() http.HandlerFunc {
 			Price:       product.Price,
 		}
 
-		if err := request.JSON(r, &reqBody); err != nil {
-			response.Text(w, http.StatusBadRequest, "invalid body")
+		var ve internal.ValidationErrors
+		if mediaType == "application/json" {
+			var bytes []byte
+			if bytes, err = io.ReadAll(r.Body); err != nil {
+				d.responseError(w, ErrInvalidBody)
+				return
+			}
+			ve, err = decodeProduct(bytes, &reqBody, nil)
+		} else {
+			ve, err = patchProduct(&reqBody, mediaType, r.Body)
+		}
+		if err != nil {
+			d.responseError(w, err)
 			return
 		}
 
+		// the update is written over the version read, so a concurrent update is not overwritten
 		product = internal.Product{
 			ID:          id,
 			Name:        reqBody.Name,
@@ -280,30 +581,31 @@ func (d *DefaultProduct) UpdatePartial() http.HandlerFunc {
 			IsPublished: reqBody.IsPublished,
 			Expiration:  reqBody.Expiration,
 			Price:       reqBody.Price,
+			Version:     product.Version,
 		}
 
-		if err := d.sv.Update(&product); err != nil {
-			switch {
-			case errors.Is(err, internal.ErrProductNotFound):
-				response.Text(w, http.StatusNotFound, "product with the provided id not found")
-			case errors.Is(err, internal.ErrFieldRequired), errors.Is(err, internal.ErrProductCodeAlreadyExists), errors.Is(err, internal.ErrFieldFormat):
-				response.Text(w, http.StatusBadRequest, "invalid body: "+err.Error())
-			default:
-				response.Text(w, http.StatusInternalServerError, "internal server error")
-			}
+		if len(ve) > 0 {
+			d.responseError(w, d.productError(ve, &product))
+			return
+		}
+
+		if err := d.sv.Update(r.Context(), &product); err != nil {
+			d.responseError(w, err)
 			return
 		}
 
 		data := BodyResponseProductJSON{
-			ID:          id,
-			Name:        reqBody.Name,
-			Quantity:    reqBody.Quantity,
-			CodeValue:   reqBody.CodeValue,
-			IsPublished: reqBody.IsPublished,
-			Expiration:  reqBody.Expiration,
-			Price:       reqBody.Price,
+			ID:          product.ID,
+			Name:        product.Name,
+			Quantity:    product.Quantity,
+			CodeValue:   product.CodeValue,
+			IsPublished: product.IsPublished,
+			Expiration:  product.Expiration,
+			Price:       product.Price,
+			Version:     product.Version,
 		}
 
+		w.Header().Set("ETag", productETag(product.Version))
 		response.JSON(w, http.StatusOK, map[string]any{
 			"Message": "Product updated successfully",
 			"data":    data,
@@ -312,25 +614,174 @@ func (d *DefaultProduct) UpdatePartial() http.HandlerFunc {
 	}
 }
 
+// Delete moves the product to the trash, or removes it permanently with hard=true
 func (d *DefaultProduct) Delete() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 
 		id, err := strconv.Atoi(chi.URLParam(r, "id"))
 
 		if err != nil {
-			response.Text(w, http.StatusBadRequest, "invalid id")
+			d.responseError(w, ErrInvalidID)
+			return
 		}
 
-		if err := d.sv.Delete(id); err != nil {
-			switch {
-			case errors.Is(err, internal.ErrProductNotFound):
-				response.Text(w, http.StatusNotFound, "product with the provided id not found")
-			default:
-				response.Text(w, http.StatusInternalServerError, "internal server error")
+		hard := false
+		if v := r.URL.Query().Get("hard"); v != "" {
+			if hard, err = strconv.ParseBool(v); err != nil {
+				d.responseError(w, internal.NewFieldError(internal.ErrQueryParam, "hard"))
+				return
 			}
+		}
+
+		if hard {
+			err = d.sv.Purge(r.Context(), id)
+		} else {
+			err = d.sv.Delete(r.Context(), id)
+		}
+		if err != nil {
+			d.responseError(w, err)
 			return
 		}
 
+		if hard {
+			response.Text(w, http.StatusOK, "Product purged successfully")
+			return
+		}
 		response.Text(w, http.StatusOK, "Product deleted successfully")
 	}
 }
+
+// Restore moves the product back from the trash
+func (d *DefaultProduct) Restore() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			d.responseError(w, ErrInvalidID)
+			return
+		}
+
+		if err := d.sv.Restore(r.Context(), id); err != nil {
+			d.responseError(w, err)
+			return
+		}
+
+		product, err := d.sv.GetById(id)
+		if err != nil {
+			d.responseError(w, err)
+			return
+		}
+
+		data := BodyResponseProductJSON{
+			ID:          product.ID,
+			Name:        product.Name,
+			Quantity:    product.Quantity,
+			CodeValue:   product.CodeValue,
+			IsPublished: product.IsPublished,
+			Expiration:  product.Expiration,
+			Price:       product.Price,
+			Version:     product.Version,
+		}
+
+		w.Header().Set("ETag", productETag(product.Version))
+		response.JSON(w, http.StatusOK, map[string]any{
+			"Message": "Product restored successfully",
+			"data":    data,
+		})
+	}
+}
+
+// deletedAt returns when the product was moved to the trash, nil for active products
+func deletedAt(product internal.Product) *time.Time {
+	if !product.IsDeleted() {
+		return nil
+	}
+	return &product.DeletedAt
+}
+
+// parseProductQuery builds a product query from the url query parameters
+// - filters: name, is_published, price_min and price_max (in currency, USD by default), quantity_min, quantity_max,
+// expiration_before and expiration_after (dd/mm/yyyy or yyyy-mm-dd)
+// - sort: comma separated fields, prefixed with "-" for descending order
+// - pagination: limit and offset
+func parseProductQuery(values url.Values) (query internal.ProductQuery, err error) {
+	query.Name = values.Get("name")
+
+	if v := values.Get("is_published"); v != "" {
+		isPublished, err := strconv.ParseBool(v)
+		if err != nil {
+			return query, internal.NewFieldError(internal.ErrQueryParam, "is_published")
+		}
+		query.IsPublished = &isPublished
+	}
+
+	currency := values.Get("currency")
+	if currency == "" {
+		currency = internal.DefaultCurrency
+	}
+	// the params are parsed in a fixed order, so the one reported among several invalid ones is always the same
+	for _, param := range []struct {
+		key string
+		ptr **internal.Money
+	}{{"price_min", &query.PriceMin}, {"price_max", &query.PriceMax}} {
+		if v := values.Get(param.key); v != "" {
+			price, err := internal.ParseMoney(v, currency)
+			if err != nil {
+				return query, internal.NewFieldError(internal.ErrQueryParam, param.key)
+			}
+			*param.ptr = &price
+		}
+	}
+
+	for _, param := range []struct {
+		key string
+		ptr **int
+	}{{"quantity_min", &query.QuantityMin}, {"quantity_max", &query.QuantityMax}} {
+		if v := values.Get(param.key); v != "" {
+			quantity, err := strconv.Atoi(v)
+			if err != nil {
+				return query, internal.NewFieldError(internal.ErrQueryParam, param.key)
+			}
+			*param.ptr = &quantity
+		}
+	}
+
+	for _, param := range []struct {
+		key string
+		ptr **internal.Date
+	}{{"expiration_before", &query.ExpirationBefore}, {"expiration_after", &query.ExpirationAfter}} {
+		if v := values.Get(param.key); v != "" {
+			expiration, err := internal.ParseDate(v)
+			if err != nil {
+				return query, internal.NewFieldError(internal.ErrQueryParam, param.key)
+			}
+			*param.ptr = &expiration
+		}
+	}
+
+	if v := values.Get("sort"); v != "" {
+		for _, field := range strings.Split(v, ",") {
+			key := internal.ProductSort{Field: strings.TrimSpace(field)}
+			if strings.HasPrefix(key.Field, "-") {
+				key.Field = key.Field[1:]
+				key.Desc = true
+			}
+			query.Sort = append(query.Sort, key)
+		}
+	}
+
+	for _, param := range []struct {
+		key string
+		ptr *int
+	}{{"limit", &query.Limit}, {"offset", &query.Offset}} {
+		if v := values.Get(param.key); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return query, internal.NewFieldError(internal.ErrQueryParam, param.key)
+			}
+			*param.ptr = n
+		}
+	}
+
+	return query, nil
+}
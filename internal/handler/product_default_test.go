@@ -0,0 +1,563 @@
+package handler_test
+
+import (
+	"app/internal"
+	"app/internal/handler"
+	"app/internal/repository"
+	"app/internal/service"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// newRouter routes the product handlers over a service backed by rp
+func newRouter(rp internal.ProductRepository) http.Handler {
+	sv := service.NewProductDefault(rp, nil, nil, repository.NewReservationMap())
+	hd := handler.NewDefaultProducts(sv, nil)
+
+	rt := chi.NewRouter()
+	rt.Get("/products", hd.GetAll())
+	rt.Post("/products", hd.Create())
+	rt.Post("/products/batch", hd.CreateBatch())
+	rt.Get("/products/export.csv", hd.Export())
+	rt.Post("/products/import", hd.Import())
+	rt.Get("/products/trash", hd.Trash())
+	rt.Get("/products/{id}", hd.GetById())
+	rt.Get("/products/code/{code_value}", hd.GetByCode())
+	rt.Put("/products/{id}", hd.Update())
+	rt.Patch("/products/{id}", hd.UpdatePartial())
+	rt.Delete("/products/{id}", hd.Delete())
+	rt.Post("/products/{id}/restore", hd.Restore())
+	rt.Get("/products/{id}/versions/{version}", hd.GetVersion())
+	rt.Post("/products/{id}/stock/decrement", hd.DecrementStock())
+	rt.Post("/products/{id}/revert/{version}", hd.Revert())
+	rt.Get("/products/{id}/reservations", hd.Reservations())
+	rt.Post("/products/{id}/reservations", hd.Reserve())
+	rt.Post("/products/{id}/reservations/{reservation_id}/confirm", hd.ConfirmReservation())
+	rt.Post("/products/{id}/reservations/{reservation_id}/cancel", hd.CancelReservation())
+	rt.Get("/products/{id}/stock", hd.Stock())
+	return rt
+}
+
+// serve sends the request with the json body, which can be empty, returning the response and its decoded body
+func serve(t *testing.T, h http.Handler, method string, target string, body string, headers ...string) (*httptest.ResponseRecorder, map[string]any) {
+	t.Helper()
+
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	res := httptest.NewRecorder()
+
+	h.ServeHTTP(res, req)
+
+	var decoded map[string]any
+	if strings.HasPrefix(res.Header().Get("Content-Type"), "application/json") {
+		require.NoError(t, json.Unmarshal(res.Body.Bytes(), &decoded), res.Body.String())
+	}
+	return res, decoded
+}
+
+// Tests for DefaultProduct.GetAll
+func TestDefaultProduct_GetAll(t *testing.T) {
+	t.Run("success - query parameters are applied", func(t *testing.T) {
+		// arrange
+		db := map[int]internal.Product{
+			1: {ID: 1, Name: "apple", Quantity: 1, CodeValue: "a", IsPublished: true, Price: internal.Money{Amount: 100, Currency: "USD"}},
+			2: {ID: 2, Name: "apricot", Quantity: 2, CodeValue: "b", IsPublished: true, Price: internal.Money{Amount: 300, Currency: "USD"}},
+			3: {ID: 3, Name: "avocado", Quantity: 3, CodeValue: "c", IsPublished: false, Price: internal.Money{Amount: 200, Currency: "USD"}},
+		}
+		h := newRouter(repository.NewProductMap(db, 3))
+
+		// act
+		res, body := serve(t, h, http.MethodGet, "/products?name=ap&is_published=true&price_min=1.00&sort=-price&limit=1&offset=0", "")
+
+		// assert
+		require.Equal(t, http.StatusOK, res.Code)
+		data := body["data"].([]any)
+		require.Len(t, data, 1)
+		require.Equal(t, float64(2), data[0].(map[string]any)["id"])
+		require.Equal(t, float64(2), body["pagination"].(map[string]any)["total"])
+	})
+
+	t.Run("error - the first invalid parameter is always the one reported", func(t *testing.T) {
+		// arrange
+		h := newRouter(repository.NewProductMap(nil, 0))
+
+		for i := 0; i < 20; i++ {
+			// act
+			res, body := serve(t, h, http.MethodGet, "/products?limit=x&offset=y&quantity_max=z&quantity_min=w&price_max=v&price_min=u&expiration_after=t&expiration_before=s", "")
+
+			// assert
+			require.Equal(t, http.StatusBadRequest, res.Code)
+			require.Equal(t, "price_min", body["field"])
+		}
+	})
+
+	t.Run("error - limit above 100", func(t *testing.T) {
+		// arrange
+		h := newRouter(repository.NewProductMap(nil, 0))
+
+		// act
+		res, body := serve(t, h, http.MethodGet, "/products?limit=101", "")
+
+		// assert
+		require.Equal(t, http.StatusBadRequest, res.Code)
+		require.Equal(t, handler.ErrCodeQueryParam, body["code"])
+		require.Equal(t, "limit", body["field"])
+	})
+}
+
+// productJSON is a valid product with the code
+func productJSON(code string) string {
+	return `{"name":"product","quantity":10,"code_value":"` + code + `","is_published":true,"expiration":"2030-01-01","price":"1.50"}`
+}
+
+// violations returns the rule violated by each field listed in the errors of the body
+func violations(body map[string]any) map[string]string {
+	rules := make(map[string]string)
+	for _, e := range body["errors"].([]any) {
+		rules[e.(map[string]any)["field"].(string)] = e.(map[string]any)["rule"].(string)
+	}
+	return rules
+}
+
+// Tests for DefaultProduct.Create
+func TestDefaultProduct_Create(t *testing.T) {
+	t.Run("success - the product is created", func(t *testing.T) {
+		// arrange
+		h := newRouter(repository.NewProductMap(nil, 0))
+
+		// act
+		res, body := serve(t, h, http.MethodPost, "/products", productJSON("code"))
+
+		// assert
+		require.Equal(t, http.StatusCreated, res.Code, body)
+		require.Equal(t, float64(1), body["data"].(map[string]any)["id"])
+	})
+
+	cases := []struct {
+		name     string
+		body     string
+		expected map[string]string
+	}{
+		{
+			name:     "values that can not be decoded along with empty fields",
+			body:     `{"name":"","quantity":1,"code_value":"","expiration":"31/02/2020","price":"1.001"}`,
+			expected: map[string]string{"name": "required", "code_value": "required", "expiration": "format", "price": "format"},
+		},
+		{
+			name:     "a missing key along with empty fields",
+			body:     `{"name":"","code_value":"","expiration":"2030-01-01","price":"1.50"}`,
+			expected: map[string]string{"quantity": "required", "name": "required", "code_value": "required"},
+		},
+		{
+			name:     "a value of the wrong json type",
+			body:     `{"name":"product","quantity":"3","code_value":"code","is_published":1,"expiration":"2030-01-01","price":"1.50"}`,
+			expected: map[string]string{"quantity": "format", "is_published": "format"},
+		},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run("error - every violation is reported at once: "+c.name, func(t *testing.T) {
+			// arrange
+			rp := repository.NewProductMap(nil, 0)
+			h := newRouter(rp)
+
+			// act
+			res, body := serve(t, h, http.MethodPost, "/products", c.body)
+
+			// assert
+			require.Equal(t, http.StatusUnprocessableEntity, res.Code, body)
+			require.Equal(t, handler.ErrCodeValidation, body["code"])
+			require.Equal(t, c.expected, violations(body))
+			_, total, _ := rp.Find(internal.ProductQuery{})
+			require.Equal(t, 0, total)
+		})
+	}
+
+	t.Run("error - the body is not a json object", func(t *testing.T) {
+		// arrange
+		h := newRouter(repository.NewProductMap(nil, 0))
+
+		// act
+		res, body := serve(t, h, http.MethodPost, "/products", `["product"]`)
+
+		// assert
+		require.Equal(t, http.StatusBadRequest, res.Code)
+		require.Equal(t, handler.ErrCodeInvalidBody, body["code"])
+	})
+}
+
+// Tests for DefaultProduct.CreateBatch
+func TestDefaultProduct_CreateBatch(t *testing.T) {
+	t.Run("success - best effort with every item undecodable creates nothing", func(t *testing.T) {
+		// arrange
+		rp := repository.NewProductMap(nil, 0)
+		h := newRouter(rp)
+
+		// act
+		res, body := serve(t, h, http.MethodPost, "/products/batch?mode=best_effort", `[1, {"name":"missing keys"}]`)
+
+		// assert
+		require.Equal(t, http.StatusMultiStatus, res.Code)
+		require.Len(t, body["data"], 2)
+		_, total, _ := rp.Find(internal.ProductQuery{})
+		require.Equal(t, 0, total)
+	})
+
+	t.Run("success - best effort creates the decodable items", func(t *testing.T) {
+		// arrange
+		rp := repository.NewProductMap(nil, 0)
+		h := newRouter(rp)
+
+		// act
+		res, body := serve(t, h, http.MethodPost, "/products/batch?mode=best_effort", "["+productJSON("a")+`, "x", `+productJSON("b")+"]")
+
+		// assert
+		require.Equal(t, http.StatusMultiStatus, res.Code)
+		data := body["data"].([]any)
+		require.Equal(t, float64(1), data[0].(map[string]any)["id"])
+		require.NotNil(t, data[1].(map[string]any)["error"])
+		require.Equal(t, float64(2), data[2].(map[string]any)["id"])
+	})
+
+	t.Run("success - the content type can have a charset", func(t *testing.T) {
+		// arrange
+		h := newRouter(repository.NewProductMap(nil, 0))
+
+		// act
+		res, body := serve(t, h, http.MethodPost, "/products/batch", "["+productJSON("a")+"]", "Content-Type", "application/json; charset=utf-8")
+
+		// assert
+		require.Equal(t, http.StatusCreated, res.Code, body)
+	})
+
+	t.Run("error - atomic with an undecodable item creates nothing", func(t *testing.T) {
+		// arrange
+		rp := repository.NewProductMap(nil, 0)
+		h := newRouter(rp)
+
+		// act
+		res, _ := serve(t, h, http.MethodPost, "/products/batch", "["+productJSON("a")+`, "x"]`)
+
+		// assert
+		require.Equal(t, http.StatusBadRequest, res.Code)
+		_, total, _ := rp.Find(internal.ProductQuery{})
+		require.Equal(t, 0, total)
+	})
+
+	t.Run("error - the size limit counts the undecodable items", func(t *testing.T) {
+		// arrange
+		rp := repository.NewProductMap(nil, 0)
+		h := newRouter(rp)
+		items := make([]string, internal.MaxBatchSize+1)
+		for i := range items {
+			items[i] = `"x"`
+		}
+		items[0] = productJSON("a")
+
+		// act
+		res, body := serve(t, h, http.MethodPost, "/products/batch?mode=best_effort", "["+strings.Join(items, ",")+"]")
+
+		// assert
+		require.Equal(t, http.StatusBadRequest, res.Code)
+		require.Equal(t, handler.ErrCodeBatchInvalid, body["code"])
+		_, total, _ := rp.Find(internal.ProductQuery{})
+		require.Equal(t, 0, total)
+	})
+
+	t.Run("error - dry run is not a batch mode", func(t *testing.T) {
+		// arrange
+		rp := repository.NewProductMap(nil, 0)
+		h := newRouter(rp)
+
+		// act
+		res, body := serve(t, h, http.MethodPost, "/products/batch?mode=dry_run", "["+productJSON("a")+"]")
+
+		// assert
+		require.Equal(t, http.StatusBadRequest, res.Code)
+		require.Equal(t, handler.ErrCodeBatchInvalid, body["code"])
+		_, total, _ := rp.Find(internal.ProductQuery{})
+		require.Equal(t, 0, total)
+	})
+
+	t.Run("error - empty batch", func(t *testing.T) {
+		// arrange
+		h := newRouter(repository.NewProductMap(nil, 0))
+
+		// act
+		res, body := serve(t, h, http.MethodPost, "/products/batch", "[]")
+
+		// assert
+		require.Equal(t, http.StatusBadRequest, res.Code)
+		require.Equal(t, handler.ErrCodeBatchInvalid, body["code"])
+	})
+}
+
+// Tests for the updates of a product out of stock
+func TestDefaultProduct_OutOfStock(t *testing.T) {
+	// newOutOfStock returns a router over a product whose stock was decremented to 0, at version 2
+	newOutOfStock := func(t *testing.T) http.Handler {
+		h := newRouter(repository.NewProductVersioned(repository.NewProductMap(nil, 0), repository.NewProductVersionMap()))
+		res, _ := serve(t, h, http.MethodPost, "/products", productJSON("code"))
+		require.Equal(t, http.StatusCreated, res.Code)
+		res, body := serve(t, h, http.MethodPost, "/products/1/stock/decrement", `{"quantity":10,"reason":"sale"}`)
+		require.Equal(t, http.StatusOK, res.Code)
+		require.Equal(t, float64(0), body["data"].(map[string]any)["product"].(map[string]any)["quantity"])
+		return h
+	}
+
+	t.Run("success - put keeps the quantity at 0", func(t *testing.T) {
+		// arrange
+		h := newOutOfStock(t)
+		product := strings.Replace(productJSON("code"), `"quantity":10`, `"quantity":0`, 1)
+
+		// act
+		res, body := serve(t, h, http.MethodPut, "/products/1", product)
+
+		// assert
+		require.Equal(t, http.StatusOK, res.Code, body)
+		require.Equal(t, float64(0), body["data"].(map[string]any)["quantity"])
+	})
+
+	t.Run("success - patch of another field", func(t *testing.T) {
+		// arrange
+		h := newOutOfStock(t)
+
+		// act
+		res, body := serve(t, h, http.MethodPatch, "/products/1", `{"name":"renamed"}`)
+
+		// assert
+		require.Equal(t, http.StatusOK, res.Code, body)
+		require.Equal(t, float64(0), body["data"].(map[string]any)["quantity"])
+	})
+
+	t.Run("success - revert keeps the quantity at 0", func(t *testing.T) {
+		// arrange
+		h := newOutOfStock(t)
+		res, _ := serve(t, h, http.MethodPatch, "/products/1", `{"name":"renamed"}`)
+		require.Equal(t, http.StatusOK, res.Code)
+
+		// act
+		res, body := serve(t, h, http.MethodPost, "/products/1/revert/1", "")
+
+		// assert
+		require.Equal(t, http.StatusOK, res.Code, body)
+		require.Equal(t, "product", body["data"].(map[string]any)["name"])
+		require.Equal(t, float64(0), body["data"].(map[string]any)["quantity"])
+	})
+
+	t.Run("error - put without quantity", func(t *testing.T) {
+		// arrange
+		h := newOutOfStock(t)
+		product := strings.Replace(productJSON("code"), `"quantity":10,`, "", 1)
+
+		// act
+		res, body := serve(t, h, http.MethodPut, "/products/1", product)
+
+		// assert
+		require.Equal(t, http.StatusUnprocessableEntity, res.Code)
+		require.Equal(t, handler.ErrCodeValidation, body["code"])
+		require.Equal(t, "quantity", body["errors"].([]any)[0].(map[string]any)["field"])
+	})
+}
+
+// Tests for DefaultProduct.GetByCode
+func TestDefaultProduct_GetByCode(t *testing.T) {
+	cases := []struct {
+		name     string
+		code     string
+		expected int
+		errCode  string
+	}{
+		{name: "success - the product of the code", code: "code", expected: http.StatusOK},
+		{name: "error - product not found", code: "other", expected: http.StatusNotFound, errCode: handler.ErrCodeProductNotFound},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			// arrange
+			h := newRouter(repository.NewProductMap(nil, 0))
+			res, _ := serve(t, h, http.MethodPost, "/products", productJSON("code"))
+			require.Equal(t, http.StatusCreated, res.Code)
+
+			// act
+			res, body := serve(t, h, http.MethodGet, "/products/code/"+c.code, "")
+
+			// assert
+			require.Equal(t, c.expected, res.Code, body)
+			if c.errCode != "" {
+				require.Equal(t, c.errCode, body["code"])
+				return
+			}
+			require.Equal(t, float64(1), body["data"].(map[string]any)["id"])
+			require.Equal(t, `"1"`, res.Header().Get("ETag"))
+		})
+	}
+}
+
+// Tests for DefaultProduct.Delete, DefaultProduct.Restore and DefaultProduct.Trash
+func TestDefaultProduct_Trash(t *testing.T) {
+	cases := []struct {
+		name     string
+		target   string
+		expected int
+		errCode  string
+		// trashed is the amount of products left in the trash
+		trashed int
+		active  bool
+	}{
+		{name: "success - a deleted product is moved to the trash", target: "/products/1", expected: http.StatusOK, trashed: 1},
+		{name: "success - a purged product is removed", target: "/products/1?hard=true", expected: http.StatusOK},
+		{name: "error - invalid id", target: "/products/abc", expected: http.StatusBadRequest, errCode: handler.ErrCodeInvalidID, active: true},
+		{name: "error - invalid hard", target: "/products/1?hard=maybe", expected: http.StatusBadRequest, errCode: handler.ErrCodeQueryParam, active: true},
+		{name: "error - product not found", target: "/products/2", expected: http.StatusNotFound, errCode: handler.ErrCodeProductNotFound, active: true},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			// arrange
+			h := newRouter(repository.NewProductMap(nil, 0))
+			res, _ := serve(t, h, http.MethodPost, "/products", productJSON("code"))
+			require.Equal(t, http.StatusCreated, res.Code)
+
+			// act
+			res, body := serve(t, h, http.MethodDelete, c.target, "")
+
+			// assert
+			require.Equal(t, c.expected, res.Code, res.Body.String())
+			if c.errCode != "" {
+				require.Equal(t, c.errCode, body["code"])
+			}
+			res, body = serve(t, h, http.MethodGet, "/products/trash", "")
+			require.Equal(t, http.StatusOK, res.Code)
+			require.Len(t, body["data"], c.trashed)
+			res, _ = serve(t, h, http.MethodGet, "/products/1", "")
+			require.Equal(t, c.active, res.Code == http.StatusOK)
+		})
+	}
+
+	t.Run("success - the trash lists when products were deleted", func(t *testing.T) {
+		// arrange
+		h := newRouter(repository.NewProductMap(nil, 0))
+		for _, code := range []string{"first", "second"} {
+			res, _ := serve(t, h, http.MethodPost, "/products", productJSON(code))
+			require.Equal(t, http.StatusCreated, res.Code)
+		}
+		res, _ := serve(t, h, http.MethodDelete, "/products/2", "")
+		require.Equal(t, http.StatusOK, res.Code)
+
+		// act
+		res, body := serve(t, h, http.MethodGet, "/products/trash?limit=10", "")
+
+		// assert
+		require.Equal(t, http.StatusOK, res.Code)
+		data := body["data"].([]any)
+		require.Len(t, data, 1)
+		require.Equal(t, float64(2), data[0].(map[string]any)["id"])
+		require.NotEmpty(t, data[0].(map[string]any)["deleted_at"])
+		require.Equal(t, float64(1), body["pagination"].(map[string]any)["total"])
+	})
+
+	t.Run("success - a restored product is active again", func(t *testing.T) {
+		// arrange
+		h := newRouter(repository.NewProductMap(nil, 0))
+		res, _ := serve(t, h, http.MethodPost, "/products", productJSON("code"))
+		require.Equal(t, http.StatusCreated, res.Code)
+		res, _ = serve(t, h, http.MethodDelete, "/products/1", "")
+		require.Equal(t, http.StatusOK, res.Code)
+
+		// act
+		res, body := serve(t, h, http.MethodPost, "/products/1/restore", "")
+
+		// assert
+		require.Equal(t, http.StatusOK, res.Code, body)
+		require.Equal(t, float64(3), body["data"].(map[string]any)["version"])
+		require.Equal(t, `"3"`, res.Header().Get("ETag"))
+		res, body = serve(t, h, http.MethodGet, "/products/trash", "")
+		require.Equal(t, http.StatusOK, res.Code)
+		require.Empty(t, body["data"])
+	})
+
+	t.Run("error - restoring an active product", func(t *testing.T) {
+		// arrange
+		h := newRouter(repository.NewProductMap(nil, 0))
+		res, _ := serve(t, h, http.MethodPost, "/products", productJSON("code"))
+		require.Equal(t, http.StatusCreated, res.Code)
+
+		// act
+		res, body := serve(t, h, http.MethodPost, "/products/1/restore", "")
+
+		// assert
+		require.Equal(t, http.StatusNotFound, res.Code)
+		require.Equal(t, handler.ErrCodeProductNotFound, body["code"])
+	})
+
+	t.Run("error - restoring a product whose code was taken", func(t *testing.T) {
+		// arrange
+		h := newRouter(repository.NewProductMap(nil, 0))
+		res, _ := serve(t, h, http.MethodPost, "/products", productJSON("code"))
+		require.Equal(t, http.StatusCreated, res.Code)
+		res, _ = serve(t, h, http.MethodDelete, "/products/1", "")
+		require.Equal(t, http.StatusOK, res.Code)
+		res, _ = serve(t, h, http.MethodPost, "/products", productJSON("code"))
+		require.Equal(t, http.StatusCreated, res.Code)
+
+		// act
+		res, body := serve(t, h, http.MethodPost, "/products/1/restore", "")
+
+		// assert
+		require.Equal(t, http.StatusBadRequest, res.Code)
+		require.Equal(t, handler.ErrCodeProductCodeAlreadyExists, body["code"])
+	})
+}
+
+// Tests for DefaultProduct.GetVersion
+func TestDefaultProduct_GetVersion(t *testing.T) {
+	cases := []struct {
+		name     string
+		target   string
+		expected int
+		errCode  string
+		// productName is the name of the product at the version found
+		productName string
+	}{
+		{name: "success - a previous version", target: "/products/1/versions/1", expected: http.StatusOK, productName: "product"},
+		{name: "success - the current version", target: "/products/1/versions/2", expected: http.StatusOK, productName: "renamed"},
+		{name: "error - invalid id", target: "/products/abc/versions/1", expected: http.StatusBadRequest, errCode: handler.ErrCodeInvalidID},
+		{name: "error - invalid version", target: "/products/1/versions/abc", expected: http.StatusBadRequest, errCode: handler.ErrCodeInvalidVersion},
+		{name: "error - version not found", target: "/products/1/versions/3", expected: http.StatusNotFound, errCode: handler.ErrCodeProductVersionNotFound},
+		{name: "error - version of an unknown product", target: "/products/2/versions/1", expected: http.StatusNotFound, errCode: handler.ErrCodeProductVersionNotFound},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			// arrange
+			h := newRouter(repository.NewProductVersioned(repository.NewProductMap(nil, 0), repository.NewProductVersionMap()))
+			res, _ := serve(t, h, http.MethodPost, "/products", productJSON("code"))
+			require.Equal(t, http.StatusCreated, res.Code)
+			res, _ = serve(t, h, http.MethodPatch, "/products/1", `{"name":"renamed"}`)
+			require.Equal(t, http.StatusOK, res.Code)
+
+			// act
+			res, body := serve(t, h, http.MethodGet, c.target, "")
+
+			// assert
+			require.Equal(t, c.expected, res.Code, body)
+			if c.errCode != "" {
+				require.Equal(t, c.errCode, body["code"])
+				return
+			}
+			require.Equal(t, c.productName, body["data"].(map[string]any)["name"])
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"app/internal"
+	"app/platform/web/patch"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// acceptPatch lists the media types accepted by the product PATCH endpoint
+const acceptPatch = "application/json, " + patch.MediaTypeMergePatch + ", " + patch.MediaTypeJSONPatch
+
+// patchProduct applies the merge patch or json patch read from body to the current product, replacing it with the result.
+// The patched product must still have every required field, the violations being returned as by decodeProduct.
+func patchProduct(current *BodyRequestProductJSON, mediaType string, body io.Reader) (internal.ValidationErrors, error) {
+	doc, err := json.Marshal(current)
+	if err != nil {
+		return nil, err
+	}
+
+	document, err := io.ReadAll(body)
+	if err != nil {
+		return nil, ErrInvalidBody
+	}
+
+	var patched []byte
+	switch mediaType {
+	case patch.MediaTypeMergePatch:
+		patched, err = patch.Merge(doc, document)
+	case patch.MediaTypeJSONPatch:
+		patched, err = patch.JSON(doc, document)
+	default:
+		return nil, ErrUnsupportedMediaType
+	}
+	switch {
+	case errors.Is(err, patch.ErrInvalid):
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	case err != nil:
+		return nil, fmt.Errorf("%w: %v", ErrPatchConflict, err)
+	}
+
+	// the fields the patch removed are left zero, a price patched without currency keeping the current one
+	result := BodyRequestProductJSON{Price: internal.Money{Currency: current.Price.Currency}}
+	ve, err := decodeProduct(patched, &result, ProductValidator.Required())
+	if err != nil {
+		return nil, fmt.Errorf("%w: the patched product is not an object", ErrInvalidPatch)
+	}
+	*current = result
+
+	return ve, nil
+}
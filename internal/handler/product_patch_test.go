@@ -0,0 +1,171 @@
+package handler_test
+
+import (
+	"app/internal/handler"
+	"app/internal/repository"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newPatchRouter returns a router over a saved product priced 1.50 EUR, at version 1
+func newPatchRouter(t *testing.T) http.Handler {
+	t.Helper()
+
+	h := newRouter(repository.NewProductMap(nil, 0))
+	res, body := serve(t, h, http.MethodPost, "/products",
+		`{"name":"product","quantity":10,"code_value":"code","is_published":true,"expiration":"2030-01-01","price":{"amount":"1.50","currency":"EUR"}}`)
+	require.Equal(t, http.StatusCreated, res.Code, body)
+	return h
+}
+
+// Tests for DefaultProduct.UpdatePartial
+func TestDefaultProduct_UpdatePartial(t *testing.T) {
+	cases := []struct {
+		name        string
+		contentType string
+		body        string
+	}{
+		{name: "merge patch", contentType: "application/merge-patch+json", body: `{"price":"7.00"}`},
+		{name: "json patch", contentType: "application/json-patch+json", body: `[{"op":"replace","path":"/price","value":"7.00"}]`},
+		{name: "json", contentType: "application/json", body: `{"price":"7.00"}`},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run("success - an amount without currency keeps the current one: "+c.name, func(t *testing.T) {
+			// arrange
+			h := newPatchRouter(t)
+
+			// act
+			res, body := serve(t, h, http.MethodPatch, "/products/1", c.body, "Content-Type", c.contentType)
+
+			// assert
+			require.Equal(t, http.StatusOK, res.Code, body)
+			require.Equal(t, map[string]any{"amount": "7.00", "currency": "EUR"}, body["data"].(map[string]any)["price"])
+		})
+	}
+
+	dispatch := []struct {
+		name        string
+		contentType string
+		body        string
+		expected    string
+	}{
+		{name: "merge patch", contentType: "application/merge-patch+json", body: `{"name":"merged"}`, expected: "merged"},
+		{name: "merge patch with charset", contentType: "application/merge-patch+json; charset=utf-8", body: `{"name":"merged"}`, expected: "merged"},
+		{name: "json patch", contentType: "application/json-patch+json", body: `[{"op":"replace","path":"/name","value":"patched"}]`, expected: "patched"},
+	}
+	for _, c := range dispatch {
+		c := c
+		t.Run("success - the document is applied by its content type: "+c.name, func(t *testing.T) {
+			// arrange
+			h := newPatchRouter(t)
+
+			// act
+			res, body := serve(t, h, http.MethodPatch, "/products/1", c.body, "Content-Type", c.contentType)
+
+			// assert
+			require.Equal(t, http.StatusOK, res.Code, body)
+			require.Equal(t, c.expected, body["data"].(map[string]any)["name"])
+			require.Equal(t, float64(2), body["data"].(map[string]any)["version"])
+			require.Equal(t, `"2"`, res.Header().Get("ETag"))
+		})
+	}
+
+	t.Run("success - if-match of the current version", func(t *testing.T) {
+		// arrange
+		h := newPatchRouter(t)
+
+		// act
+		res, body := serve(t, h, http.MethodPatch, "/products/1", `{"name":"renamed"}`,
+			"Content-Type", "application/merge-patch+json", "If-Match", `"1"`)
+
+		// assert
+		require.Equal(t, http.StatusOK, res.Code, body)
+		require.Equal(t, "renamed", body["data"].(map[string]any)["name"])
+	})
+
+	t.Run("error - a json patch sent as a merge patch is not applied as operations", func(t *testing.T) {
+		// arrange
+		h := newPatchRouter(t)
+
+		// act
+		res, body := serve(t, h, http.MethodPatch, "/products/1", `[{"op":"replace","path":"/name","value":"patched"}]`,
+			"Content-Type", "application/merge-patch+json")
+
+		// assert
+		require.Equal(t, http.StatusBadRequest, res.Code)
+		require.Equal(t, handler.ErrCodeInvalidPatch, body["code"])
+	})
+
+	t.Run("error - unsupported media type", func(t *testing.T) {
+		// arrange
+		h := newPatchRouter(t)
+
+		// act
+		res, body := serve(t, h, http.MethodPatch, "/products/1", `name=renamed`, "Content-Type", "application/x-www-form-urlencoded")
+
+		// assert
+		require.Equal(t, http.StatusUnsupportedMediaType, res.Code)
+		require.Equal(t, handler.ErrCodeUnsupportedMediaType, body["code"])
+		require.Equal(t, "application/json, application/merge-patch+json, application/json-patch+json", res.Header().Get("Accept-Patch"))
+	})
+
+	t.Run("error - a failed test operation", func(t *testing.T) {
+		// arrange
+		h := newPatchRouter(t)
+
+		// act
+		res, body := serve(t, h, http.MethodPatch, "/products/1",
+			`[{"op":"test","path":"/name","value":"other"},{"op":"replace","path":"/name","value":"patched"}]`,
+			"Content-Type", "application/json-patch+json")
+
+		// assert
+		require.Equal(t, http.StatusConflict, res.Code)
+		require.Equal(t, handler.ErrCodePatchConflict, body["code"])
+		_, body = serve(t, h, http.MethodGet, "/products/1", "")
+		require.Equal(t, "product", body["data"].(map[string]any)["name"])
+	})
+
+	removals := []struct {
+		name        string
+		contentType string
+		body        string
+	}{
+		{name: "merge patch", contentType: "application/merge-patch+json", body: `{"name":null}`},
+		{name: "json patch", contentType: "application/json-patch+json", body: `[{"op":"remove","path":"/name"}]`},
+	}
+	for _, c := range removals {
+		c := c
+		t.Run("error - a required field removed: "+c.name, func(t *testing.T) {
+			// arrange
+			h := newPatchRouter(t)
+
+			// act
+			res, body := serve(t, h, http.MethodPatch, "/products/1", c.body, "Content-Type", c.contentType)
+
+			// assert
+			require.Equal(t, http.StatusUnprocessableEntity, res.Code)
+			require.Equal(t, handler.ErrCodeValidation, body["code"])
+			require.Equal(t, map[string]string{"name": "required"}, violations(body))
+		})
+	}
+
+	t.Run("error - if-match of a stale version", func(t *testing.T) {
+		// arrange
+		h := newPatchRouter(t)
+		res, _ := serve(t, h, http.MethodPatch, "/products/1", `{"name":"renamed"}`, "Content-Type", "application/merge-patch+json")
+		require.Equal(t, http.StatusOK, res.Code)
+
+		// act
+		res, body := serve(t, h, http.MethodPatch, "/products/1", `{"name":"stale"}`,
+			"Content-Type", "application/merge-patch+json", "If-Match", `"1"`)
+
+		// assert
+		require.Equal(t, http.StatusPreconditionFailed, res.Code)
+		require.Equal(t, handler.ErrCodePreconditionFailed, body["code"])
+		_, body = serve(t, h, http.MethodGet, "/products/1", "")
+		require.Equal(t, "renamed", body["data"].(map[string]any)["name"])
+	})
+}
@@ -0,0 +1,197 @@
+package handler
+
+import (
+	"app/internal"
+	"app/platform/web/request"
+	"app/platform/web/response"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// BodyRequestReservationJSON is a reservation sent by clients, ttl_seconds being optional
+type BodyRequestReservationJSON struct {
+	Quantity   int    `json:"quantity"`
+	TTLSeconds int    `json:"ttl_seconds"`
+	Reference  string `json:"reference"`
+}
+
+// BodyResponseReservationJSON is a reservation of a product
+type BodyResponseReservationJSON struct {
+	ID        int        `json:"id"`
+	ProductID int        `json:"product_id"`
+	Quantity  int        `json:"quantity"`
+	Status    string     `json:"status"`
+	Reference string     `json:"reference,omitempty"`
+	Actor     string     `json:"actor"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// BodyResponseStockJSON is the stock of a product
+type BodyResponseStockJSON struct {
+	ProductID int `json:"product_id"`
+	Quantity  int `json:"quantity"`
+	Reserved  int `json:"reserved"`
+	Available int `json:"available"`
+}
+
+// Stock returns the quantity of the product, the one held by its active reservations and the available one
+func (d *DefaultProduct) Stock() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			d.responseError(w, ErrInvalidID)
+			return
+		}
+
+		level, err := d.sv.Stock(id)
+		if err != nil {
+			d.responseError(w, err)
+			return
+		}
+
+		response.JSON(w, http.StatusOK, map[string]any{
+			"Message": "Stock found successfully",
+			"data": BodyResponseStockJSON{
+				ProductID: level.ProductID,
+				Quantity:  level.Quantity,
+				Reserved:  level.Reserved,
+				Available: level.Available,
+			},
+		})
+	}
+}
+
+// Reserve holds the quantity in the body from the available stock of the product
+func (d *DefaultProduct) Reserve() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			d.responseError(w, ErrInvalidID)
+			return
+		}
+
+		var body BodyRequestReservationJSON
+		if err := request.JSON(r, &body); err != nil {
+			d.responseError(w, fmt.Errorf("%w: %v", ErrInvalidBody, err))
+			return
+		}
+
+		// the ttl is bounded in seconds, as a bigger one would overflow the duration
+		if body.TTLSeconds < 0 || body.TTLSeconds > int(internal.MaxReservationTTL/time.Second) {
+			var ve internal.ValidationErrors
+			ve.Add("ttl_seconds", internal.RuleRange, fmt.Sprintf("must be between 1 and %d", int(internal.MaxReservationTTL/time.Second)))
+			d.responseError(w, ve)
+			return
+		}
+
+		reservation := internal.Reservation{
+			ProductID: id,
+			Quantity:  body.Quantity,
+			Reference: body.Reference,
+		}
+
+		if err := d.sv.Reserve(r.Context(), &reservation, time.Duration(body.TTLSeconds)*time.Second); err != nil {
+			d.responseError(w, err)
+			return
+		}
+
+		response.JSON(w, http.StatusCreated, map[string]any{
+			"Message": "Reservation created successfully",
+			"data":    reservationJSON(reservation),
+		})
+	}
+}
+
+// Reservations lists the reservations of the product, oldest first
+func (d *DefaultProduct) Reservations() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			d.responseError(w, ErrInvalidID)
+			return
+		}
+
+		reservations, err := d.sv.Reservations(id)
+		if err != nil {
+			d.responseError(w, err)
+			return
+		}
+
+		data := make([]BodyResponseReservationJSON, 0, len(reservations))
+		for _, reservation := range reservations {
+			data = append(data, reservationJSON(reservation))
+		}
+
+		response.JSON(w, http.StatusOK, map[string]any{
+			"Message": "Reservations found successfully",
+			"data":    data,
+		})
+	}
+}
+
+// ConfirmReservation takes the quantity held by the reservation from the stock of the product
+func (d *DefaultProduct) ConfirmReservation() http.HandlerFunc {
+	return d.closeReservation(d.sv.ConfirmReservation, "Reservation confirmed successfully")
+}
+
+// CancelReservation releases the quantity held by the reservation
+func (d *DefaultProduct) CancelReservation() http.HandlerFunc {
+	return d.closeReservation(d.sv.CancelReservation, "Reservation cancelled successfully")
+}
+
+// closeReservation closes the reservation of the url with the service operation
+func (d *DefaultProduct) closeReservation(op func(ctx context.Context, productID int, id int) (internal.Reservation, error), message string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		productID, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			d.responseError(w, ErrInvalidID)
+			return
+		}
+		id, err := strconv.Atoi(chi.URLParam(r, "reservation_id"))
+		if err != nil {
+			d.responseError(w, ErrInvalidID)
+			return
+		}
+
+		reservation, err := op(r.Context(), productID, id)
+		if err != nil {
+			d.responseError(w, err)
+			return
+		}
+
+		response.JSON(w, http.StatusOK, map[string]any{
+			"Message": message,
+			"data":    reservationJSON(reservation),
+		})
+	}
+}
+
+// reservationJSON converts the reservation to its json response
+func reservationJSON(reservation internal.Reservation) BodyResponseReservationJSON {
+	data := BodyResponseReservationJSON{
+		ID:        reservation.ID,
+		ProductID: reservation.ProductID,
+		Quantity:  reservation.Quantity,
+		Status:    string(reservation.Status),
+		Reference: reservation.Reference,
+		Actor:     reservation.Actor,
+		CreatedAt: reservation.CreatedAt,
+		ExpiresAt: reservation.ExpiresAt,
+	}
+	if !reservation.UpdatedAt.IsZero() {
+		data.UpdatedAt = &reservation.UpdatedAt
+	}
+
+	return data
+}
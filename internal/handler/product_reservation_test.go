@@ -0,0 +1,217 @@
+package handler_test
+
+import (
+	"app/internal/handler"
+	"app/internal/repository"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newReserved returns a router over a product with a quantity of 10, reservation 1 holding 4 of it
+func newReserved(t *testing.T) http.Handler {
+	t.Helper()
+
+	h := newRouter(repository.NewProductMap(nil, 0))
+	res, _ := serve(t, h, http.MethodPost, "/products", productJSON("code"))
+	require.Equal(t, http.StatusCreated, res.Code)
+	res, body := serve(t, h, http.MethodPost, "/products/1/reservations", `{"quantity":4,"reference":"order"}`)
+	require.Equal(t, http.StatusCreated, res.Code, body)
+	return h
+}
+
+// stock returns the stock of the product 1 served by the router
+func stock(t *testing.T, h http.Handler) map[string]any {
+	t.Helper()
+
+	res, body := serve(t, h, http.MethodGet, "/products/1/stock", "")
+	require.Equal(t, http.StatusOK, res.Code)
+	return body["data"].(map[string]any)
+}
+
+// Tests for DefaultProduct.Reserve
+func TestDefaultProduct_Reserve(t *testing.T) {
+	t.Run("success - the quantity is held from the available stock", func(t *testing.T) {
+		// arrange
+		h := newRouter(repository.NewProductMap(nil, 0))
+		res, _ := serve(t, h, http.MethodPost, "/products", productJSON("code"))
+		require.Equal(t, http.StatusCreated, res.Code)
+
+		// act
+		res, body := serve(t, h, http.MethodPost, "/products/1/reservations", `{"quantity":4,"ttl_seconds":60,"reference":"order"}`)
+
+		// assert
+		require.Equal(t, http.StatusCreated, res.Code, body)
+		data := body["data"].(map[string]any)
+		require.Equal(t, float64(1), data["id"])
+		require.Equal(t, "active", data["status"])
+		require.Equal(t, "order", data["reference"])
+		require.Equal(t, map[string]any{"product_id": float64(1), "quantity": float64(10), "reserved": float64(4), "available": float64(6)}, stock(t, h))
+	})
+
+	errCases := []struct {
+		name     string
+		target   string
+		body     string
+		expected int
+		errCode  string
+	}{
+		{name: "invalid id", target: "/products/abc/reservations", body: `{"quantity":1}`, expected: http.StatusBadRequest, errCode: handler.ErrCodeInvalidID},
+		{name: "invalid body", target: "/products/1/reservations", body: `{"quantity":"one"}`, expected: http.StatusBadRequest, errCode: handler.ErrCodeInvalidBody},
+		{name: "quantity not positive", target: "/products/1/reservations", body: `{"quantity":0}`, expected: http.StatusUnprocessableEntity, errCode: handler.ErrCodeValidation},
+		{name: "quantity above the available stock", target: "/products/1/reservations", body: `{"quantity":7}`, expected: http.StatusConflict, errCode: handler.ErrCodeStockInsufficient},
+		{name: "product not found", target: "/products/2/reservations", body: `{"quantity":1}`, expected: http.StatusNotFound, errCode: handler.ErrCodeProductNotFound},
+	}
+	for _, c := range errCases {
+		c := c
+		t.Run("error - "+c.name, func(t *testing.T) {
+			// arrange
+			h := newReserved(t)
+
+			// act
+			res, body := serve(t, h, http.MethodPost, c.target, c.body)
+
+			// assert
+			require.Equal(t, c.expected, res.Code, body)
+			require.Equal(t, c.errCode, body["code"])
+			require.Equal(t, float64(4), stock(t, h)["reserved"])
+		})
+	}
+
+	cases := []struct {
+		name string
+		body string
+	}{
+		{name: "negative ttl", body: `{"quantity":1,"ttl_seconds":-1}`},
+		{name: "ttl above a day", body: `{"quantity":1,"ttl_seconds":86401}`},
+		{name: "ttl overflowing the duration", body: `{"quantity":1,"ttl_seconds":18446744074}`},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run("error - "+c.name, func(t *testing.T) {
+			// arrange
+			h := newRouter(repository.NewProductMap(nil, 0))
+			res, _ := serve(t, h, http.MethodPost, "/products", productJSON("code"))
+			require.Equal(t, http.StatusCreated, res.Code)
+
+			// act
+			res, body := serve(t, h, http.MethodPost, "/products/1/reservations", c.body)
+
+			// assert
+			require.Equal(t, http.StatusUnprocessableEntity, res.Code, body)
+			require.Equal(t, handler.ErrCodeValidation, body["code"])
+			require.Equal(t, map[string]string{"ttl_seconds": "range"}, violations(body))
+			res, body = serve(t, h, http.MethodGet, "/products/1/reservations", "")
+			require.Equal(t, http.StatusOK, res.Code)
+			require.Empty(t, body["data"])
+		})
+	}
+}
+
+// Tests for DefaultProduct.ConfirmReservation and DefaultProduct.CancelReservation
+func TestDefaultProduct_CloseReservation(t *testing.T) {
+	cases := []struct {
+		name     string
+		target   string
+		expected int
+		errCode  string
+		status   string
+		// stock is the quantity and the reserved quantity of the product after the request
+		quantity int
+		reserved int
+	}{
+		{name: "success - a confirmed reservation is taken from the stock", target: "/products/1/reservations/1/confirm",
+			expected: http.StatusOK, status: "confirmed", quantity: 6, reserved: 0},
+		{name: "success - a cancelled reservation is released", target: "/products/1/reservations/1/cancel",
+			expected: http.StatusOK, status: "cancelled", quantity: 10, reserved: 0},
+		{name: "error - invalid product id", target: "/products/abc/reservations/1/confirm",
+			expected: http.StatusBadRequest, errCode: handler.ErrCodeInvalidID, quantity: 10, reserved: 4},
+		{name: "error - invalid reservation id", target: "/products/1/reservations/abc/cancel",
+			expected: http.StatusBadRequest, errCode: handler.ErrCodeInvalidID, quantity: 10, reserved: 4},
+		{name: "error - reservation not found", target: "/products/1/reservations/2/confirm",
+			expected: http.StatusNotFound, errCode: handler.ErrCodeReservationNotFound, quantity: 10, reserved: 4},
+		{name: "error - reservation of another product", target: "/products/2/reservations/1/cancel",
+			expected: http.StatusNotFound, errCode: handler.ErrCodeReservationNotFound, quantity: 10, reserved: 4},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			// arrange
+			h := newReserved(t)
+
+			// act
+			res, body := serve(t, h, http.MethodPost, c.target, "")
+
+			// assert
+			require.Equal(t, c.expected, res.Code, body)
+			if c.errCode != "" {
+				require.Equal(t, c.errCode, body["code"])
+			} else {
+				require.Equal(t, c.status, body["data"].(map[string]any)["status"])
+				require.NotEmpty(t, body["data"].(map[string]any)["updated_at"])
+			}
+			level := stock(t, h)
+			require.Equal(t, float64(c.quantity), level["quantity"])
+			require.Equal(t, float64(c.reserved), level["reserved"])
+		})
+	}
+
+	t.Run("error - a closed reservation is not active", func(t *testing.T) {
+		// arrange
+		h := newReserved(t)
+		res, _ := serve(t, h, http.MethodPost, "/products/1/reservations/1/cancel", "")
+		require.Equal(t, http.StatusOK, res.Code)
+
+		// act
+		res, body := serve(t, h, http.MethodPost, "/products/1/reservations/1/confirm", "")
+
+		// assert
+		require.Equal(t, http.StatusConflict, res.Code)
+		require.Equal(t, handler.ErrCodeReservationNotActive, body["code"])
+		require.Equal(t, float64(10), stock(t, h)["quantity"])
+	})
+}
+
+// Tests for DefaultProduct.Reservations
+func TestDefaultProduct_Reservations(t *testing.T) {
+	cases := []struct {
+		name     string
+		target   string
+		expected int
+		errCode  string
+		// statuses are the statuses of the reservations listed, oldest first
+		statuses []string
+	}{
+		{name: "success - the reservations of the product, oldest first", target: "/products/1/reservations",
+			expected: http.StatusOK, statuses: []string{"cancelled", "active"}},
+		{name: "error - invalid id", target: "/products/abc/reservations", expected: http.StatusBadRequest, errCode: handler.ErrCodeInvalidID},
+		{name: "error - product not found", target: "/products/2/reservations", expected: http.StatusNotFound, errCode: handler.ErrCodeProductNotFound},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			// arrange
+			h := newReserved(t)
+			res, _ := serve(t, h, http.MethodPost, "/products/1/reservations/1/cancel", "")
+			require.Equal(t, http.StatusOK, res.Code)
+			res, _ = serve(t, h, http.MethodPost, "/products/1/reservations", `{"quantity":2}`)
+			require.Equal(t, http.StatusCreated, res.Code)
+
+			// act
+			res, body := serve(t, h, http.MethodGet, c.target, "")
+
+			// assert
+			require.Equal(t, c.expected, res.Code, body)
+			if c.errCode != "" {
+				require.Equal(t, c.errCode, body["code"])
+				return
+			}
+			statuses := []string{}
+			for _, reservation := range body["data"].([]any) {
+				statuses = append(statuses, reservation.(map[string]any)["status"].(string))
+			}
+			require.Equal(t, c.statuses, statuses)
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"app/internal"
+	"app/platform/web/request"
+	"app/platform/web/response"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// BodyRequestStockJSON is a stock movement sent by clients, quantity being always positive
+type BodyRequestStockJSON struct {
+	Quantity  int    `json:"quantity"`
+	Reason    string `json:"reason"`
+	Reference string `json:"reference"`
+}
+
+// BodyResponseStockMovementJSON is a movement of the stock ledger of a product
+type BodyResponseStockMovementJSON struct {
+	ID        int       `json:"id"`
+	ProductID int       `json:"product_id"`
+	Delta     int       `json:"delta"`
+	Quantity  int       `json:"quantity"`
+	Reason    string    `json:"reason"`
+	Reference string    `json:"reference,omitempty"`
+	Actor     string    `json:"actor"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// IncrementStock adds the quantity in the body to the stock of the product
+func (d *DefaultProduct) IncrementStock() http.HandlerFunc {
+	return d.moveStock(1, "Stock incremented successfully")
+}
+
+// DecrementStock takes the quantity in the body from the stock of the product, which can not go negative
+func (d *DefaultProduct) DecrementStock() http.HandlerFunc {
+	return d.moveStock(-1, "Stock decremented successfully")
+}
+
+// moveStock moves the stock of the product by the quantity in the body, in the direction of sign
+func (d *DefaultProduct) moveStock(sign int, message string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			d.responseError(w, ErrInvalidID)
+			return
+		}
+
+		var body BodyRequestStockJSON
+		if err := request.JSON(r, &body); err != nil {
+			d.responseError(w, fmt.Errorf("%w: %v", ErrInvalidBody, err))
+			return
+		}
+
+		// the direction is given by the endpoint, a negative quantity would reverse it
+		if body.Quantity <= 0 {
+			var ve internal.ValidationErrors
+			ve.Add("quantity", internal.RuleRange, "must be greater than 0")
+			d.responseError(w, ve)
+			return
+		}
+
+		movement := internal.StockMovement{
+			ProductID: id,
+			Delta:     sign * body.Quantity,
+			Reason:    body.Reason,
+			Reference: body.Reference,
+		}
+
+		product, err := d.sv.MoveStock(r.Context(), &movement)
+		if err != nil {
+			d.responseError(w, err)
+			return
+		}
+
+		data := map[string]any{
+			"product": BodyResponseProductJSON{
+				ID:          product.ID,
+				Name:        product.Name,
+				Quantity:    product.Quantity,
+				CodeValue:   product.CodeValue,
+				IsPublished: product.IsPublished,
+				Expiration:  product.Expiration,
+				Price:       product.Price,
+				Version:     product.Version,
+			},
+			"movement": stockMovementJSON(movement),
+		}
+
+		w.Header().Set("ETag", productETag(product.Version))
+		response.JSON(w, http.StatusOK, map[string]any{
+			"Message": message,
+			"data":    data,
+		})
+	}
+}
+
+// StockMovements lists the stock ledger of the product, oldest first
+func (d *DefaultProduct) StockMovements() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			d.responseError(w, ErrInvalidID)
+			return
+		}
+
+		movements, err := d.sv.StockMovements(id)
+		if err != nil {
+			d.responseError(w, err)
+			return
+		}
+
+		data := make([]BodyResponseStockMovementJSON, 0, len(movements))
+		for _, movement := range movements {
+			data = append(data, stockMovementJSON(movement))
+		}
+
+		response.JSON(w, http.StatusOK, map[string]any{
+			"Message": "Stock movements found successfully",
+			"data":    data,
+		})
+	}
+}
+
+// stockMovementJSON converts the movement to its json response
+func stockMovementJSON(movement internal.StockMovement) BodyResponseStockMovementJSON {
+	return BodyResponseStockMovementJSON{
+		ID:        movement.ID,
+		ProductID: movement.ProductID,
+		Delta:     movement.Delta,
+		Quantity:  movement.Quantity,
+		Reason:    movement.Reason,
+		Reference: movement.Reference,
+		Actor:     movement.Actor,
+		Timestamp: movement.Timestamp,
+	}
+}
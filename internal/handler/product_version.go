@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"app/internal"
+	"app/platform/web/response"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// GetVersion returns the product as it was at the version in the url
+func (d *DefaultProduct) GetVersion() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			d.responseError(w, ErrInvalidID)
+			return
+		}
+
+		version, err := strconv.Atoi(chi.URLParam(r, "version"))
+		if err != nil {
+			d.responseError(w, ErrInvalidVersion)
+			return
+		}
+
+		product, err := d.sv.GetVersion(id, version)
+		if err != nil {
+			d.responseError(w, err)
+			return
+		}
+
+		data := BodyResponseProductJSON{
+			ID:          product.ID,
+			Name:        product.Name,
+			Quantity:    product.Quantity,
+			CodeValue:   product.CodeValue,
+			IsPublished: product.IsPublished,
+			Expiration:  product.Expiration,
+			Price:       product.Price,
+			Version:     product.Version,
+			DeletedAt:   deletedAt(product),
+		}
+
+		response.JSON(w, http.StatusOK, map[string]any{
+			"Message": "Product version found successfully",
+			"data":    data,
+		})
+	}
+}
+
+// Revert updates the product back to the content it had at the version in the url
+func (d *DefaultProduct) Revert() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			d.responseError(w, ErrInvalidID)
+			return
+		}
+
+		version, err := strconv.Atoi(chi.URLParam(r, "version"))
+		if err != nil {
+			d.responseError(w, ErrInvalidVersion)
+			return
+		}
+
+		// a conditional revert is only written over the version the client has seen
+		var current int
+		if r.Header.Get("If-Match") != "" {
+			product, err := d.sv.GetById(id)
+			if err != nil {
+				d.responseError(w, err)
+				return
+			}
+			if !ifMatch(r, product.Version) {
+				d.responseError(w, internal.ErrProductVersionConflict)
+				return
+			}
+			current = product.Version
+		}
+
+		product, err := d.sv.Revert(r.Context(), id, version, current)
+		if err != nil {
+			d.responseError(w, err)
+			return
+		}
+
+		data := BodyResponseProductJSON{
+			ID:          product.ID,
+			Name:        product.Name,
+			Quantity:    product.Quantity,
+			CodeValue:   product.CodeValue,
+			IsPublished: product.IsPublished,
+			Expiration:  product.Expiration,
+			Price:       product.Price,
+			Version:     product.Version,
+		}
+
+		w.Header().Set("ETag", productETag(product.Version))
+		response.JSON(w, http.StatusOK, map[string]any{
+			"Message": "Product reverted successfully",
+			"data":    data,
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is the currency of the amounts written without one
+const DefaultCurrency = "USD"
+
+var (
+	// ErrMoneyFormat is returned when an amount is not a decimal number
+	ErrMoneyFormat = errors.New("money must be a decimal amount")
+	// ErrMoneyPrecision is returned when an amount has more decimals than its currency allows
+	ErrMoneyPrecision = errors.New("money has more decimals than its currency allows")
+	// ErrCurrency is returned when a currency is not a supported ISO-4217 code
+	ErrCurrency = errors.New("currency must be a supported ISO-4217 code")
+)
+
+// currencyExponents holds the number of decimals (minor unit digits) of the supported ISO-4217 currencies
+var currencyExponents = map[string]int{
+	"ARS": 2, "AUD": 2, "BRL": 2, "CAD": 2, "CHF": 2, "CLP": 0, "CNY": 2, "COP": 2,
+	"EUR": 2, "GBP": 2, "INR": 2, "JPY": 0, "KRW": 0, "KWD": 3, "MXN": 2, "PEN": 2,
+	"PYG": 0, "USD": 2, "UYU": 2,
+}
+
+// CurrencyExponent returns the number of decimals of the currency, false if it is not supported
+func CurrencyExponent(currency string) (int, bool) {
+	exp, ok := currencyExponents[currency]
+	return exp, ok
+}
+
+// Money is an amount of a currency, counted in its minor units (e.g. cents) to avoid rounding errors
+type Money struct {
+	// Amount is the amount in minor units of the currency
+	Amount int64
+	// Currency is the ISO-4217 code of the currency
+	Currency string
+}
+
+// ParseMoney parses a decimal amount of the currency, e.g. "12.50".
+// The amount can not have more decimals than the currency allows.
+func ParseMoney(amount, currency string) (Money, error) {
+	exp, ok := CurrencyExponent(currency)
+	if !ok {
+		return Money{}, fmt.Errorf("%w: %q", ErrCurrency, currency)
+	}
+
+	digits, negative := strings.CutPrefix(amount, "-")
+	whole, fraction, _ := strings.Cut(digits, ".")
+	if whole == "" || !isDigits(whole) || !isDigits(fraction) {
+		return Money{}, fmt.Errorf("%w: %q", ErrMoneyFormat, amount)
+	}
+	if len(fraction) > exp {
+		return Money{}, fmt.Errorf("%w: %q has more than %d decimals", ErrMoneyPrecision, amount, exp)
+	}
+
+	units, err := strconv.ParseInt(whole+fraction+strings.Repeat("0", exp-len(fraction)), 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q", ErrMoneyFormat, amount)
+	}
+	if negative {
+		units = -units
+	}
+
+	return Money{Amount: units, Currency: currency}, nil
+}
+
+// isDigits reports whether s only has ascii digits
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// IsZero reports whether m is the zero money, used for missing amounts
+func (m Money) IsZero() bool {
+	return m.Amount == 0 && m.Currency == ""
+}
+
+// IsNegative reports whether the amount is below zero
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// Compare returns -1, 0 or 1 if the amount of m is lower, equal or greater than the one of u.
+// Amounts of different currencies are ordered by currency.
+func (m Money) Compare(u Money) int {
+	if m.Currency != u.Currency {
+		return strings.Compare(m.Currency, u.Currency)
+	}
+	switch {
+	case m.Amount < u.Amount:
+		return -1
+	case m.Amount > u.Amount:
+		return 1
+	}
+	return 0
+}
+
+// String returns the decimal amount, without the currency, e.g. "12.50"
+func (m Money) String() string {
+	exp := currencyExponents[m.Currency]
+
+	sign := ""
+	units := m.Amount
+	if units < 0 {
+		sign = "-"
+		units = -units
+	}
+
+	digits := strconv.FormatInt(units, 10)
+	if exp == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exp {
+		digits = strings.Repeat("0", exp-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exp] + "." + digits[len(digits)-exp:]
+}
+
+// moneyJSON is the json representation of Money
+type moneyJSON struct {
+	Amount   json.RawMessage `json:"amount"`
+	Currency string          `json:"currency"`
+}
+
+// MarshalJSON writes the money as {"amount": "12.50", "currency": "USD"}, null for the zero money
+func (m Money) MarshalJSON() ([]byte, error) {
+	if m.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(struct {
+		Amount   string `json:"amount"`
+		Currency string `json:"currency"`
+	}{m.String(), m.Currency})
+}
+
+// UnmarshalJSON reads the money written as {"amount": "12.50", "currency": "USD"}.
+// A bare amount, as a string or a number, is read in the DefaultCurrency and null is the zero money.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	money, err := ParseMoneyJSON(data, DefaultCurrency)
+	if err != nil {
+		return err
+	}
+	*m = money
+	return nil
+}
+
+// ParseMoneyJSON reads the money written as {"amount": "12.50", "currency": "USD"} like UnmarshalJSON,
+// an amount written without currency being read in the currency given
+func ParseMoneyJSON(data []byte, currency string) (Money, error) {
+	data = bytes.TrimSpace(data)
+	if string(data) == "null" {
+		return Money{}, nil
+	}
+
+	raw := moneyJSON{Amount: data, Currency: currency}
+	if len(data) > 0 && data[0] == '{' {
+		raw.Currency = ""
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return Money{}, fmt.Errorf("%w: %s", ErrMoneyFormat, data)
+		}
+		if raw.Currency == "" {
+			raw.Currency = currency
+		}
+	}
+
+	amount, err := moneyAmount(raw.Amount)
+	if err != nil {
+		return Money{}, err
+	}
+
+	return ParseMoney(amount, raw.Currency)
+}
+
+// moneyAmount returns the text of a json amount, written either as a string or as a number.
+// Numbers are read from their text so they are not rounded as floats.
+func moneyAmount(data json.RawMessage) (string, error) {
+	var amount string
+	if err := json.Unmarshal(data, &amount); err == nil {
+		return amount, nil
+	}
+
+	var number json.Number
+	if err := json.Unmarshal(data, &number); err != nil {
+		return "", fmt.Errorf("%w: %s", ErrMoneyFormat, data)
+	}
+	return number.String(), nil
+}
@@ -0,0 +1,226 @@
+package internal_test
+
+import (
+	"app/internal"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for ParseMoney
+func TestParseMoney(t *testing.T) {
+	cases := []struct {
+		name     string
+		amount   string
+		currency string
+		expected internal.Money
+	}{
+		{name: "two decimals", amount: "12.50", currency: "USD", expected: internal.Money{Amount: 1250, Currency: "USD"}},
+		{name: "fewer decimals than the currency", amount: "12.5", currency: "USD", expected: internal.Money{Amount: 1250, Currency: "USD"}},
+		{name: "no decimals", amount: "12", currency: "USD", expected: internal.Money{Amount: 1200, Currency: "USD"}},
+		{name: "currency without decimals", amount: "1500", currency: "JPY", expected: internal.Money{Amount: 1500, Currency: "JPY"}},
+		{name: "currency with three decimals", amount: "1.234", currency: "KWD", expected: internal.Money{Amount: 1234, Currency: "KWD"}},
+		{name: "negative", amount: "-0.05", currency: "EUR", expected: internal.Money{Amount: -5, Currency: "EUR"}},
+		{name: "zero", amount: "0.00", currency: "USD", expected: internal.Money{Amount: 0, Currency: "USD"}},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run("success - "+c.name, func(t *testing.T) {
+			// act
+			money, err := internal.ParseMoney(c.amount, c.currency)
+
+			// assert
+			require.NoError(t, err)
+			require.Equal(t, c.expected, money)
+		})
+	}
+
+	errCases := []struct {
+		name     string
+		amount   string
+		currency string
+		err      error
+	}{
+		{name: "more decimals than the currency", amount: "12.505", currency: "USD", err: internal.ErrMoneyPrecision},
+		{name: "decimals on a currency without them", amount: "12.5", currency: "JPY", err: internal.ErrMoneyPrecision},
+		{name: "unsupported currency", amount: "12.50", currency: "XYZ", err: internal.ErrCurrency},
+		{name: "empty", amount: "", currency: "USD", err: internal.ErrMoneyFormat},
+		{name: "no whole part", amount: ".50", currency: "USD", err: internal.ErrMoneyFormat},
+		{name: "letters", amount: "12.5a", currency: "USD", err: internal.ErrMoneyFormat},
+		{name: "exponent", amount: "1e3", currency: "USD", err: internal.ErrMoneyFormat},
+		{name: "thousands separator", amount: "1,000.00", currency: "USD", err: internal.ErrMoneyFormat},
+		{name: "overflow", amount: "99999999999999999999", currency: "USD", err: internal.ErrMoneyFormat},
+	}
+	for _, c := range errCases {
+		c := c
+		t.Run("error - "+c.name, func(t *testing.T) {
+			// act
+			money, err := internal.ParseMoney(c.amount, c.currency)
+
+			// assert
+			require.ErrorIs(t, err, c.err)
+			require.True(t, money.IsZero())
+		})
+	}
+}
+
+// Tests for Money
+func TestMoney(t *testing.T) {
+	cases := []struct {
+		name     string
+		money    internal.Money
+		expected string
+	}{
+		{name: "cents are padded", money: internal.Money{Amount: 5, Currency: "USD"}, expected: "0.05"},
+		{name: "zero amount", money: internal.Money{Amount: 0, Currency: "USD"}, expected: "0.00"},
+		{name: "whole amount", money: internal.Money{Amount: 1200, Currency: "USD"}, expected: "12.00"},
+		{name: "negative", money: internal.Money{Amount: -1250, Currency: "EUR"}, expected: "-12.50"},
+		{name: "negative cents", money: internal.Money{Amount: -5, Currency: "EUR"}, expected: "-0.05"},
+		{name: "currency without decimals", money: internal.Money{Amount: 1500, Currency: "JPY"}, expected: "1500"},
+		{name: "currency with three decimals", money: internal.Money{Amount: 7, Currency: "KWD"}, expected: "0.007"},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run("success - string of "+c.name, func(t *testing.T) {
+			// act
+			s := c.money.String()
+
+			// assert
+			require.Equal(t, c.expected, s)
+		})
+	}
+
+	t.Run("success - compare orders by currency, then by amount", func(t *testing.T) {
+		// arrange
+		eur := internal.Money{Amount: 900, Currency: "EUR"}
+		low := internal.Money{Amount: 100, Currency: "USD"}
+		high := internal.Money{Amount: 200, Currency: "USD"}
+
+		// assert
+		require.Equal(t, -1, low.Compare(high))
+		require.Equal(t, 1, high.Compare(low))
+		require.Equal(t, 0, low.Compare(internal.Money{Amount: 100, Currency: "USD"}))
+		require.Equal(t, -1, eur.Compare(low))
+		require.Equal(t, 1, low.Compare(eur))
+	})
+
+	t.Run("success - zero and negative", func(t *testing.T) {
+		// assert
+		require.True(t, internal.Money{}.IsZero())
+		require.False(t, internal.Money{Currency: "USD"}.IsZero())
+		require.True(t, internal.Money{Amount: -1, Currency: "USD"}.IsNegative())
+		require.False(t, internal.Money{Amount: 0, Currency: "USD"}.IsNegative())
+	})
+}
+
+// Tests for the json encoding of Money
+func TestMoney_JSON(t *testing.T) {
+	t.Run("success - written as an object", func(t *testing.T) {
+		// act
+		data, err := json.Marshal(internal.Money{Amount: 1250, Currency: "EUR"})
+
+		// assert
+		require.NoError(t, err)
+		require.JSONEq(t, `{"amount": "12.50", "currency": "EUR"}`, string(data))
+	})
+
+	t.Run("success - the zero money is null", func(t *testing.T) {
+		// act
+		data, err := json.Marshal(internal.Money{})
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, "null", string(data))
+	})
+
+	cases := []struct {
+		name     string
+		input    string
+		expected internal.Money
+	}{
+		{name: "object", input: `{"amount": "12.50", "currency": "EUR"}`, expected: internal.Money{Amount: 1250, Currency: "EUR"}},
+		{name: "object with a number amount", input: `{"amount": 1500, "currency": "JPY"}`, expected: internal.Money{Amount: 1500, Currency: "JPY"}},
+		{name: "object without currency", input: `{"amount": "12.50"}`, expected: internal.Money{Amount: 1250, Currency: internal.DefaultCurrency}},
+		{name: "bare string amount", input: `"12.50"`, expected: internal.Money{Amount: 1250, Currency: internal.DefaultCurrency}},
+		{name: "bare number amount is not rounded", input: `0.29`, expected: internal.Money{Amount: 29, Currency: internal.DefaultCurrency}},
+		{name: "null", input: `null`, expected: internal.Money{}},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run("success - "+c.name, func(t *testing.T) {
+			// arrange
+			money := internal.Money{Amount: 1, Currency: "GBP"}
+
+			// act
+			err := json.Unmarshal([]byte(c.input), &money)
+
+			// assert
+			require.NoError(t, err)
+			require.Equal(t, c.expected, money)
+		})
+	}
+
+	errCases := []struct {
+		name  string
+		input string
+		err   error
+	}{
+		{name: "too many decimals", input: `"12.505"`, err: internal.ErrMoneyPrecision},
+		{name: "unsupported currency", input: `{"amount": "1.00", "currency": "XYZ"}`, err: internal.ErrCurrency},
+		{name: "not an amount", input: `true`, err: internal.ErrMoneyFormat},
+		{name: "object of the wrong shape", input: `{"amount": {}}`, err: internal.ErrMoneyFormat},
+	}
+	for _, c := range errCases {
+		c := c
+		t.Run("error - "+c.name, func(t *testing.T) {
+			// arrange
+			var money internal.Money
+
+			// act
+			err := json.Unmarshal([]byte(c.input), &money)
+
+			// assert
+			require.ErrorIs(t, err, c.err)
+		})
+	}
+
+	t.Run("success - round trip", func(t *testing.T) {
+		// arrange
+		money := internal.Money{Amount: -7, Currency: "KWD"}
+
+		// act
+		data, errMarshal := json.Marshal(money)
+		var decoded internal.Money
+		errUnmarshal := json.Unmarshal(data, &decoded)
+
+		// assert
+		require.NoError(t, errMarshal)
+		require.NoError(t, errUnmarshal)
+		require.Equal(t, money, decoded)
+	})
+}
+
+// Tests for ParseMoneyJSON
+func TestParseMoneyJSON(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    string
+		expected internal.Money
+	}{
+		{name: "bare amount in the given currency", input: `"7.00"`, expected: internal.Money{Amount: 700, Currency: "EUR"}},
+		{name: "object without currency in the given currency", input: `{"amount": "7.00"}`, expected: internal.Money{Amount: 700, Currency: "EUR"}},
+		{name: "object with its own currency", input: `{"amount": "7.00", "currency": "GBP"}`, expected: internal.Money{Amount: 700, Currency: "GBP"}},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run("success - "+c.name, func(t *testing.T) {
+			// act
+			money, err := internal.ParseMoneyJSON([]byte(c.input), "EUR")
+
+			// assert
+			require.NoError(t, err)
+			require.Equal(t, c.expected, money)
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package notifier
+
+import (
+	"app/internal"
+	"context"
+	"log"
+)
+
+// AlertLog writes the alerts to a logger
+type AlertLog struct {
+	logger *log.Logger
+}
+
+// NewAlertLog returns a notifier writing to logger, the standard logger when it is nil
+func NewAlertLog(logger *log.Logger) *AlertLog {
+	if logger == nil {
+		logger = log.Default()
+	}
+
+	return &AlertLog{
+		logger: logger,
+	}
+}
+
+func (n *AlertLog) Notify(ctx context.Context, alert internal.Alert) error {
+	n.logger.Printf("alert %d [%s] product %d: %s", alert.ID, alert.Kind, alert.ProductID, alert.Message)
+	return nil
+}
@@ -0,0 +1,39 @@
+package notifier
+
+import (
+	"app/internal"
+	"context"
+	"sync"
+)
+
+// AlertMemory keeps the alerts in memory, e.g. to check them in tests
+type AlertMemory struct {
+	mu     sync.RWMutex
+	alerts []internal.Alert
+}
+
+func NewAlertMemory() *AlertMemory {
+	return &AlertMemory{
+		alerts: make([]internal.Alert, 0),
+	}
+}
+
+func (n *AlertMemory) Notify(ctx context.Context, alert internal.Alert) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.alerts = append(n.alerts, alert)
+
+	return nil
+}
+
+// Alerts returns the notified alerts, in the order they were notified
+func (n *AlertMemory) Alerts() []internal.Alert {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	alerts := make([]internal.Alert, len(n.alerts))
+	copy(alerts, n.alerts)
+
+	return alerts
+}
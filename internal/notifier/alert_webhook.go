@@ -0,0 +1,82 @@
+package notifier
+
+import (
+	"app/internal"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ErrWebhookStatus is returned when the webhook answers with a status other than 2xx
+var ErrWebhookStatus = errors.New("webhook status")
+
+// defaultWebhookTimeout bounds a notification when no client is given
+const defaultWebhookTimeout = 5 * time.Second
+
+// AlertWebhook posts the alerts as json to a url
+type AlertWebhook struct {
+	url    string
+	client *http.Client
+}
+
+// NewAlertWebhook returns a notifier posting to url with client, a client with a default timeout when it is nil
+func NewAlertWebhook(url string, client *http.Client) *AlertWebhook {
+	if client == nil {
+		client = &http.Client{Timeout: defaultWebhookTimeout}
+	}
+
+	return &AlertWebhook{
+		url:    url,
+		client: client,
+	}
+}
+
+// alertWebhookJSON is the body posted for an alert
+type alertWebhookJSON struct {
+	ID         int           `json:"id"`
+	Kind       string        `json:"kind"`
+	ProductID  int           `json:"product_id"`
+	CodeValue  string        `json:"code_value"`
+	Quantity   int           `json:"quantity"`
+	Expiration internal.Date `json:"expiration"`
+	Message    string        `json:"message"`
+	RaisedAt   time.Time     `json:"raised_at"`
+}
+
+func (n *AlertWebhook) Notify(ctx context.Context, alert internal.Alert) error {
+	body, err := json.Marshal(alertWebhookJSON{
+		ID:         alert.ID,
+		Kind:       string(alert.Kind),
+		ProductID:  alert.ProductID,
+		CodeValue:  alert.CodeValue,
+		Quantity:   alert.Quantity,
+		Expiration: alert.Expiration,
+		Message:    alert.Message,
+		RaisedAt:   alert.RaisedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("alert webhook: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("alert webhook: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("alert webhook: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("alert webhook: %w %d", ErrWebhookStatus, res.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package notifier_test
+
+import (
+	"app/internal"
+	"app/internal/notifier"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for AlertWebhook
+func TestAlertWebhook_Notify(t *testing.T) {
+	t.Run("success - the alert is posted as json", func(t *testing.T) {
+		// arrange
+		var body map[string]any
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, http.MethodPost, r.Method)
+			require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer srv.Close()
+		nt := notifier.NewAlertWebhook(srv.URL, nil)
+
+		// act
+		err := nt.Notify(context.Background(), internal.Alert{ID: 1, Kind: internal.AlertLowStock, ProductID: 2, Quantity: 3})
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, "low_stock", body["kind"])
+		require.Equal(t, float64(2), body["product_id"])
+		require.Equal(t, float64(3), body["quantity"])
+	})
+
+	t.Run("error - webhook answers with a failure status", func(t *testing.T) {
+		// arrange
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer srv.Close()
+		nt := notifier.NewAlertWebhook(srv.URL, nil)
+
+		// act
+		err := nt.Notify(context.Background(), internal.Alert{ID: 1})
+
+		// assert
+		require.ErrorIs(t, err, notifier.ErrWebhookStatus)
+	})
+}
@@ -1,11 +1,25 @@
 package internal
 
+import "time"
+
+// Product is a product of the catalog.
+// Its validate tags are the single source of the product validation rules,
+// the json tags name the fields in the violations reported.
 type Product struct {
-	ID          int
-	Name        string
-	Quantity    int
-	CodeValue   string
-	IsPublished bool
-	Expiration  string
-	Price       float64
+	ID          int    `json:"id"`
+	Name        string `json:"name" validate:"required,max=100"`
+	Quantity    int    `json:"quantity" validate:"present,min=0"`
+	CodeValue   string `json:"code_value" validate:"required,max=50"`
+	IsPublished bool   `json:"is_published"`
+	Expiration  Date   `json:"expiration" validate:"required"`
+	Price       Money  `json:"price" validate:"required"`
+	// Version is set to 1 when the product is saved and incremented by every update
+	Version int `json:"version"`
+	// DeletedAt is when the product was moved to the trash, zero while it is active
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// IsDeleted reports whether the product is in the trash
+func (p Product) IsDeleted() bool {
+	return !p.DeletedAt.IsZero()
 }
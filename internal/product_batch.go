@@ -0,0 +1,40 @@
+package internal
+
+import "fmt"
+
+// MaxBatchSize is the biggest amount of products a batch can hold
+const MaxBatchSize = 1000
+
+// BatchMode is how a batch of products is created
+type BatchMode string
+
+const (
+	// BatchModeAtomic creates every product of the batch or none of them
+	BatchModeAtomic BatchMode = "atomic"
+	// BatchModeBestEffort creates every valid product, reporting the ones that failed
+	BatchModeBestEffort BatchMode = "best_effort"
+)
+
+// BatchResult is the outcome of creating one product of a batch
+type BatchResult struct {
+	// Index is the position of the product in the batch
+	Index int
+	// ID is the id of the created product, zero when it was not created
+	ID int
+	// Err is the reason the product was not created
+	Err error
+}
+
+// BatchItemError is the error of one product that caused a whole batch to fail
+type BatchItemError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchItemError) Error() string {
+	return fmt.Sprintf("item %d: %s", e.Index, e.Err)
+}
+
+func (e *BatchItemError) Unwrap() error {
+	return e.Err
+}
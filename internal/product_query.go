@@ -0,0 +1,47 @@
+package internal
+
+// ProductQuery holds the filters, sorting and pagination used to list products
+type ProductQuery struct {
+	// Name matches products whose name contains the value (case insensitive)
+	Name string
+	// IsPublished matches products with the given published state
+	IsPublished *bool
+	// PriceMin and PriceMax bound the product price (inclusive),
+	// products priced in another currency than the bound do not match
+	PriceMin *Money
+	PriceMax *Money
+	// QuantityMin and QuantityMax bound the product quantity (inclusive)
+	QuantityMin *int
+	QuantityMax *int
+	// ExpirationBefore and ExpirationAfter bound the expiration date (exclusive)
+	ExpirationBefore *Date
+	ExpirationAfter  *Date
+	// Deleted selects the products in the trash instead of the active ones
+	Deleted bool
+	// AfterID matches products whose id is greater than the value,
+	// paging by id keeps its place while products are created or deleted
+	AfterID int
+
+	// Sort lists the keys used to order the result, applied in order
+	Sort []ProductSort
+
+	// Limit and Offset select the page of the result
+	Limit  int
+	Offset int
+}
+
+// ProductSort is a sort key of a ProductQuery
+type ProductSort struct {
+	Field string
+	Desc  bool
+}
+
+// fields a ProductQuery can be sorted by
+const (
+	ProductSortID         = "id"
+	ProductSortName       = "name"
+	ProductSortQuantity   = "quantity"
+	ProductSortCodeValue  = "code_value"
+	ProductSortExpiration = "expiration"
+	ProductSortPrice      = "price"
+)
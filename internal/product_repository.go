@@ -5,11 +5,33 @@ import "errors"
 var (
 	ErrProductCodeAlreadyExists = errors.New("product code already exists")
 	ErrProductNotFound          = errors.New("product not found")
+	// ErrProductVersionConflict is returned when a product was updated since the version being written was read
+	ErrProductVersionConflict = errors.New("product was modified by another request")
 )
 
+// ProductRepository stores the products.
+// Deleted products are kept in a trash until they are restored or purged: only Find with
+// ProductQuery.Deleted, Restore and Purge see them, and their code can be taken by active products.
 type ProductRepository interface {
 	Save(product *Product) error
+	// SaveAll saves every product or none of them.
+	// A product that can not be saved is reported as a *BatchItemError.
+	SaveAll(products []*Product) error
 	GetById(id int) (Product, error)
+	GetByCode(code string) (Product, error)
+	// Update replaces the product and increments its version.
+	// A non-zero Version must match the stored one, otherwise ErrProductVersionConflict is returned.
 	Update(Product *Product) error
+	// Delete moves the active product to the trash
 	Delete(id int) error
+	// Restore moves the product back from the trash.
+	// ErrProductCodeAlreadyExists is returned when an active product took its code meanwhile.
+	Restore(id int) error
+	// Purge removes the product permanently, whether it is active or in the trash
+	Purge(id int) error
+	// AdjustStock atomically adds delta to the quantity of the active product, incrementing its version,
+	// and returns the product. ErrStockInsufficient is returned when the quantity would be negative.
+	AdjustStock(id int, delta int) (Product, error)
+	// Find returns the page of products matching the query and the total of matches
+	Find(query ProductQuery) ([]Product, int, error)
 }
@@ -1,6 +1,10 @@
 package internal
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"time"
+)
 
 var (
 	ErrFieldRequired        = errors.New("field is required")
@@ -8,11 +12,59 @@ var (
 	ErrProductAlreadyExists = errors.New("product already exists")
 
 	ErrProductID = errors.New("product id provided is invalid")
+
+	ErrQueryParam = errors.New("query parameter is invalid")
+
+	ErrBatchSize     = errors.New("batch size is invalid")
+	ErrBatchMode     = errors.New("batch mode is invalid")
+	ErrBatchRejected = errors.New("batch rejected")
 )
 
+// ProductService holds the product use cases.
+// Mutations take the context of the request, carrying the actor recorded in the audit log.
 type ProductService interface {
-	Save(product *Product) error
+	Save(ctx context.Context, product *Product) error
+	// SaveBatch creates the products following the mode, returning the result of each one.
+	// In atomic mode ErrBatchRejected is returned when any product fails and nothing is created.
+	SaveBatch(ctx context.Context, products []*Product, mode BatchMode) ([]BatchResult, error)
+	// ValidateBatch checks the products as SaveBatch would, including the uniqueness of their codes,
+	// without creating any. The results have no id.
+	ValidateBatch(products []*Product) ([]BatchResult, error)
+	// Validate checks the product against the rules Save applies to it, or Update for a product with an id,
+	// without writing it. Every violation is returned as ValidationErrors.
+	Validate(product *Product) error
 	GetById(id int) (Product, error)
-	Update(Product *Product) error
-	Delete(id int) error
+	GetByCode(code string) (Product, error)
+	Update(ctx context.Context, Product *Product) error
+	Delete(ctx context.Context, id int) error
+	Restore(ctx context.Context, id int) error
+	Purge(ctx context.Context, id int) error
+	// Find normalizes the query (e.g. default limit) and returns the page of products matching it and the total of matches
+	Find(query *ProductQuery) ([]Product, int, error)
+	// History returns the audit log of the product, oldest first
+	History(id int) ([]AuditEntry, error)
+	// MoveStock applies the stock movement to the product atomically and records it in the ledger.
+	// A decrement can not take the stock held by the active reservations.
+	MoveStock(ctx context.Context, movement *StockMovement) (Product, error)
+	// StockMovements returns the stock ledger of the product, oldest first
+	StockMovements(id int) ([]StockMovement, error)
+	// Stock returns the quantity of the product, the one held by its active reservations and the available one
+	Stock(id int) (StockLevel, error)
+	// Reserve holds the quantity of the reservation from the available stock of its product for the ttl,
+	// a zero ttl being the default one
+	Reserve(ctx context.Context, reservation *Reservation, ttl time.Duration) error
+	// ConfirmReservation takes the quantity held by the active reservation from the stock of the product
+	ConfirmReservation(ctx context.Context, productID int, id int) (Reservation, error)
+	// CancelReservation releases the quantity held by the active reservation
+	CancelReservation(ctx context.Context, productID int, id int) (Reservation, error)
+	// Reservations returns the reservations of the product, oldest first
+	Reservations(productID int) ([]Reservation, error)
+	// ExpireReservations releases the quantity held by the reservations past their expiration, returning them
+	ExpireReservations(ctx context.Context) ([]Reservation, error)
+	// GetVersion returns the product as it was at the version
+	GetVersion(id int, version int) (Product, error)
+	// Revert updates the product back to the content it had at the version, as a new version.
+	// The quantity is kept, the stock only moving through MoveStock and the reservations.
+	// A non-zero current is the version the product must still be at, otherwise ErrProductVersionConflict is returned.
+	Revert(ctx context.Context, id int, version int, current int) (Product, error)
 }
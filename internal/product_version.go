@@ -0,0 +1,22 @@
+package internal
+
+import "errors"
+
+// ErrProductVersionNotFound is returned when a version of a product is not retained
+var ErrProductVersionNotFound = errors.New("product version not found")
+
+// ProductVersioner is implemented by the product repositories retaining every version of the products
+type ProductVersioner interface {
+	// GetVersion returns the product as it was at the version, including when it was deleted
+	GetVersion(id int, version int) (Product, error)
+}
+
+// ProductVersionStore stores every version of the products
+type ProductVersionStore interface {
+	// Record stores the product as the version it holds
+	Record(product Product) error
+	// GetVersion returns the product as it was at the version, ErrProductVersionNotFound if it was not recorded
+	GetVersion(id int, version int) (Product, error)
+	// Drop removes every version of the product
+	Drop(id int) error
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"app/internal"
+	"sync"
+)
+
+// AuditMap is an in-memory audit store
+type AuditMap struct {
+	mu sync.RWMutex
+	// entries holds the entries of each product, oldest first
+	entries map[int][]internal.AuditEntry
+	lastId  int
+}
+
+func NewAuditMap() *AuditMap {
+	return &AuditMap{
+		entries: make(map[int][]internal.AuditEntry),
+	}
+}
+
+func (am *AuditMap) Record(entry *internal.AuditEntry) error {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+
+	am.lastId++
+
+	entry.ID = am.lastId
+
+	am.entries[entry.ProductID] = append(am.entries[entry.ProductID], *entry)
+
+	return nil
+}
+
+func (am *AuditMap) History(productID int) ([]internal.AuditEntry, error) {
+	am.mu.RLock()
+	defer am.mu.RUnlock()
+
+	entries := make([]internal.AuditEntry, len(am.entries[productID]))
+	copy(entries, am.entries[productID])
+
+	return entries, nil
+}
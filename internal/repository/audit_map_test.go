@@ -0,0 +1,65 @@
+package repository_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for AuditMap
+func TestAuditMap(t *testing.T) {
+	t.Run("success - the entries of a product are returned oldest first", func(t *testing.T) {
+		// arrange
+		am := repository.NewAuditMap()
+		create := internal.AuditEntry{ProductID: 1, Actor: "alice", Operation: internal.AuditOperationCreate,
+			Changes: []internal.AuditChange{{Field: "name", After: "product"}}}
+		other := internal.AuditEntry{ProductID: 2, Actor: "alice", Operation: internal.AuditOperationCreate}
+		update := internal.AuditEntry{ProductID: 1, Actor: "bob", Operation: internal.AuditOperationUpdate,
+			Changes: []internal.AuditChange{{Field: "name", Before: "product", After: "renamed"}}}
+
+		// act
+		require.NoError(t, am.Record(&create))
+		require.NoError(t, am.Record(&other))
+		require.NoError(t, am.Record(&update))
+		entries, err := am.History(1)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, 1, create.ID)
+		require.Equal(t, 2, other.ID)
+		require.Equal(t, 3, update.ID)
+		require.Equal(t, []internal.AuditEntry{create, update}, entries)
+	})
+
+	t.Run("success - the history is a copy", func(t *testing.T) {
+		// arrange
+		am := repository.NewAuditMap()
+		entry := internal.AuditEntry{ProductID: 1, Operation: internal.AuditOperationCreate, Timestamp: time.Now()}
+		require.NoError(t, am.Record(&entry))
+
+		// act
+		entries, _ := am.History(1)
+		entries[0].Actor = "mallory"
+		again, err := am.History(1)
+
+		// assert
+		require.NoError(t, err)
+		require.Empty(t, again[0].Actor)
+	})
+
+	t.Run("success - a product without entries has an empty history", func(t *testing.T) {
+		// arrange
+		am := repository.NewAuditMap()
+
+		// act
+		entries, err := am.History(1)
+
+		// assert
+		require.NoError(t, err)
+		require.NotNil(t, entries)
+		require.Empty(t, entries)
+	})
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"app/internal"
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+// AuditSQLite is an audit store backed by the product_audit table of a sqlite database
+type AuditSQLite struct {
+	db *sql.DB
+}
+
+// NewAuditSQLite returns an audit store over db, which must be migrated (see Migrate)
+func NewAuditSQLite(db *sql.DB) *AuditSQLite {
+	return &AuditSQLite{
+		db: db,
+	}
+}
+
+// auditChangeJSON is a change as stored in the changes column, the values being kept as json
+type auditChangeJSON struct {
+	Field  string          `json:"field"`
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
+
+func (as *AuditSQLite) Record(entry *internal.AuditEntry) error {
+	changes := make([]auditChangeJSON, 0, len(entry.Changes))
+	for _, change := range entry.Changes {
+		before, err := json.Marshal(change.Before)
+		if err != nil {
+			return err
+		}
+		after, err := json.Marshal(change.After)
+		if err != nil {
+			return err
+		}
+		changes = append(changes, auditChangeJSON{Field: change.Field, Before: before, After: after})
+	}
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	result, err := as.db.Exec(
+		"INSERT INTO product_audit (product_id, actor, timestamp, operation, changes) VALUES (?, ?, ?, ?, ?)",
+		entry.ProductID, entry.Actor, entry.Timestamp.UTC().Format(time.RFC3339Nano), string(entry.Operation), string(data),
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	entry.ID = int(id)
+
+	return nil
+}
+
+func (as *AuditSQLite) History(productID int) ([]internal.AuditEntry, error) {
+	rows, err := as.db.Query(
+		"SELECT id, product_id, actor, timestamp, operation, changes FROM product_audit WHERE product_id = ? ORDER BY id",
+		productID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]internal.AuditEntry, 0)
+	for rows.Next() {
+		var entry internal.AuditEntry
+		var timestamp, operation, data string
+		if err := rows.Scan(&entry.ID, &entry.ProductID, &entry.Actor, &timestamp, &operation, &data); err != nil {
+			return nil, err
+		}
+
+		if entry.Timestamp, err = time.Parse(time.RFC3339Nano, timestamp); err != nil {
+			return nil, err
+		}
+		entry.Operation = internal.AuditOperation(operation)
+
+		var changes []auditChangeJSON
+		if err := json.Unmarshal([]byte(data), &changes); err != nil {
+			return nil, err
+		}
+		for _, change := range changes {
+			entry.Changes = append(entry.Changes, internal.AuditChange{Field: change.Field, Before: change.Before, After: change.After})
+		}
+
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
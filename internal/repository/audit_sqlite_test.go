@@ -0,0 +1,89 @@
+package repository_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newAuditSQLite returns an audit store over a new migrated database
+func newAuditSQLite(t *testing.T) *repository.AuditSQLite {
+	t.Helper()
+
+	db := openDB(t)
+	require.NoError(t, repository.Migrate(db))
+	return repository.NewAuditSQLite(db)
+}
+
+// Tests for AuditSQLite
+func TestAuditSQLite(t *testing.T) {
+	t.Run("success - the entries of a product are returned oldest first", func(t *testing.T) {
+		// arrange
+		as := newAuditSQLite(t)
+		timestamp := time.Date(2030, time.January, 1, 12, 30, 0, 500, time.UTC)
+		create := internal.AuditEntry{ProductID: 1, Actor: "alice", Timestamp: timestamp, Operation: internal.AuditOperationCreate,
+			Changes: []internal.AuditChange{{Field: "name", After: "product"}}}
+		other := internal.AuditEntry{ProductID: 2, Actor: "alice", Timestamp: timestamp, Operation: internal.AuditOperationCreate}
+		update := internal.AuditEntry{ProductID: 1, Actor: "bob", Timestamp: timestamp.Add(time.Hour), Operation: internal.AuditOperationUpdate,
+			Changes: []internal.AuditChange{{Field: "quantity", Before: 3, After: 0}}}
+
+		// act
+		require.NoError(t, as.Record(&create))
+		require.NoError(t, as.Record(&other))
+		require.NoError(t, as.Record(&update))
+		entries, err := as.History(1)
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		require.Equal(t, create.ID, entries[0].ID)
+		require.Equal(t, update.ID, entries[1].ID)
+		require.Less(t, create.ID, other.ID)
+		require.Less(t, other.ID, update.ID)
+		require.Equal(t, 1, entries[1].ProductID)
+		require.Equal(t, "bob", entries[1].Actor)
+		require.True(t, timestamp.Add(time.Hour).Equal(entries[1].Timestamp))
+		require.Equal(t, internal.AuditOperationUpdate, entries[1].Operation)
+	})
+
+	t.Run("success - the values of the changes are kept as json", func(t *testing.T) {
+		// arrange
+		as := newAuditSQLite(t)
+		entry := internal.AuditEntry{ProductID: 1, Timestamp: time.Now(), Operation: internal.AuditOperationUpdate,
+			Changes: []internal.AuditChange{
+				{Field: "name", Before: nil, After: "product"},
+				{Field: "price", Before: internal.Money{Amount: 150, Currency: "USD"}, After: internal.Money{Amount: 200, Currency: "USD"}},
+			}}
+		require.NoError(t, as.Record(&entry))
+
+		// act
+		entries, err := as.History(1)
+
+		// assert
+		require.NoError(t, err)
+		changes := entries[0].Changes
+		require.Len(t, changes, 2)
+		require.Equal(t, "name", changes[0].Field)
+		require.JSONEq(t, `null`, string(changes[0].Before.(json.RawMessage)))
+		require.JSONEq(t, `"product"`, string(changes[0].After.(json.RawMessage)))
+		require.Equal(t, "price", changes[1].Field)
+		require.JSONEq(t, `{"amount": "2.00", "currency": "USD"}`, string(changes[1].After.(json.RawMessage)))
+	})
+
+	t.Run("success - a product without entries has an empty history", func(t *testing.T) {
+		// arrange
+		as := newAuditSQLite(t)
+
+		// act
+		entries, err := as.History(1)
+
+		// assert
+		require.NoError(t, err)
+		require.NotNil(t, entries)
+		require.Empty(t, entries)
+	})
+}
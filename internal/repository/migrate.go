@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// migrations are the sql scripts that create and upgrade the schema.
+// Each file is named <version>_<description>.sql and is applied once, in version order.
+//
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// ErrMigrationPending is returned when the schema of a database is behind the embedded migrations
+var ErrMigrationPending = errors.New("migration pending")
+
+// migration is a versioned sql script
+type migration struct {
+	version int
+	name    string
+	script  string
+}
+
+// Migrate applies to db every embedded migration that was not applied yet.
+// Applied versions are recorded in the schema_migrations table.
+func Migrate(db *sql.DB) error {
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		name       TEXT NOT NULL,
+		applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`); err != nil {
+		return fmt.Errorf("migrate: %w", err)
+	}
+
+	current, err := MigrationVersion(db)
+	if err != nil {
+		return err
+	}
+
+	ms, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+
+	for _, m := range ms {
+		if m.version <= current {
+			continue
+		}
+		if err := applyMigration(db, m); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MigrationVersion returns the last migration version applied to db
+func MigrationVersion(db *sql.DB) (version int, err error) {
+	row := db.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_migrations")
+	if err = row.Scan(&version); err != nil {
+		err = fmt.Errorf("migrate: %w", err)
+	}
+	return
+}
+
+// CheckMigrations returns ErrMigrationPending when an embedded migration was not applied to db
+func CheckMigrations(db *sql.DB) error {
+	current, err := MigrationVersion(db)
+	if err != nil {
+		return err
+	}
+
+	ms, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+
+	if latest := ms[len(ms)-1].version; current < latest {
+		return fmt.Errorf("migrate: %w: schema at version %d, latest is %d", ErrMigrationPending, current, latest)
+	}
+
+	return nil
+}
+
+// applyMigration runs the migration script and records its version in a single transaction
+func applyMigration(db *sql.DB, m migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("migrate %s: %w", m.name, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(m.script); err != nil {
+		return fmt.Errorf("migrate %s: %w", m.name, err)
+	}
+	if _, err := tx.Exec("INSERT INTO schema_migrations (version, name) VALUES (?, ?)", m.version, m.name); err != nil {
+		return fmt.Errorf("migrate %s: %w", m.name, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("migrate %s: %w", m.name, err)
+	}
+
+	return nil
+}
+
+// loadMigrations reads the embedded migrations sorted by version
+func loadMigrations() ([]migration, error) {
+	entries, err := fs.ReadDir(migrations, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("migrate: %w", err)
+	}
+
+	ms := make([]migration, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+
+		prefix, _, ok := strings.Cut(name, "_")
+		if !ok {
+			return nil, fmt.Errorf("migrate: invalid migration name %s", name)
+		}
+		version, err := strconv.Atoi(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("migrate: invalid migration version %s", name)
+		}
+
+		script, err := fs.ReadFile(migrations, "migrations/"+name)
+		if err != nil {
+			return nil, fmt.Errorf("migrate: %w", err)
+		}
+
+		ms = append(ms, migration{version: version, name: name, script: string(script)})
+	}
+
+	sort.Slice(ms, func(i, j int) bool {
+		return ms[i].version < ms[j].version
+	})
+
+	return ms, nil
+}
@@ -0,0 +1,119 @@
+package repository_test
+
+import (
+	"app/internal/repository"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// latestMigration is the version of the last embedded migration
+const latestMigration = 9
+
+// openDB opens a new sqlite database in a temporary directory
+func openDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "products.db"))
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// Tests for Migrate
+func TestMigrate(t *testing.T) {
+	t.Run("success - a new database is migrated to the latest version", func(t *testing.T) {
+		// arrange
+		db := openDB(t)
+
+		// act
+		err := repository.Migrate(db)
+
+		// assert
+		require.NoError(t, err)
+		version, err := repository.MigrationVersion(db)
+		require.NoError(t, err)
+		require.Equal(t, latestMigration, version)
+		require.NoError(t, repository.CheckMigrations(db))
+	})
+
+	t.Run("success - every migration is recorded once", func(t *testing.T) {
+		// arrange
+		db := openDB(t)
+		require.NoError(t, repository.Migrate(db))
+
+		// act
+		err := repository.Migrate(db)
+
+		// assert
+		require.NoError(t, err)
+		var count, distinct int
+		require.NoError(t, db.QueryRow("SELECT COUNT(*), COUNT(DISTINCT name) FROM schema_migrations").Scan(&count, &distinct))
+		require.Equal(t, latestMigration, count)
+		require.Equal(t, latestMigration, distinct)
+	})
+
+	t.Run("success - the rows of a database at the first version are upgraded", func(t *testing.T) {
+		// arrange
+		db := openDB(t)
+		_, err := db.Exec(`
+			CREATE TABLE schema_migrations (
+				version    INTEGER PRIMARY KEY,
+				name       TEXT NOT NULL,
+				applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+			);
+			INSERT INTO schema_migrations (version, name) VALUES (1, '0001_create_products.sql');
+			CREATE TABLE products (
+				id           INTEGER PRIMARY KEY AUTOINCREMENT,
+				name         TEXT    NOT NULL,
+				quantity     INTEGER NOT NULL,
+				code_value   TEXT    NOT NULL,
+				is_published INTEGER NOT NULL DEFAULT 0,
+				expiration   TEXT    NOT NULL,
+				price        REAL    NOT NULL
+			);
+			CREATE UNIQUE INDEX idx_products_code_value ON products (code_value);
+			INSERT INTO products (name, quantity, code_value, is_published, expiration, price)
+			VALUES ('legacy', 3, 'legacy', 1, '15/03/2030', 12.5);
+		`)
+		require.NoError(t, err)
+		errPending := repository.CheckMigrations(db)
+
+		// act
+		err = repository.Migrate(db)
+
+		// assert
+		require.ErrorIs(t, errPending, repository.ErrMigrationPending)
+		require.NoError(t, err)
+		var expiration, currency string
+		var amount int64
+		var version int
+		require.NoError(t, db.QueryRow("SELECT expiration, price_amount, price_currency, version FROM products WHERE id = 1").
+			Scan(&expiration, &amount, &currency, &version))
+		require.Equal(t, "2030-03-15", expiration)
+		require.Equal(t, int64(1250), amount)
+		require.Equal(t, "USD", currency)
+		require.Equal(t, 1, version)
+		require.NoError(t, repository.CheckMigrations(db))
+	})
+
+	t.Run("error - a failing migration is not recorded", func(t *testing.T) {
+		// arrange
+		db := openDB(t)
+		// the table the first migration creates already exists
+		_, err := db.Exec("CREATE TABLE products (id INTEGER PRIMARY KEY)")
+		require.NoError(t, err)
+
+		// act
+		err = repository.Migrate(db)
+
+		// assert
+		require.Error(t, err)
+		version, err := repository.MigrationVersion(db)
+		require.NoError(t, err)
+		require.Equal(t, 0, version)
+	})
+}
@@ -0,0 +1,11 @@
+CREATE TABLE products (
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    name         TEXT    NOT NULL,
+    quantity     INTEGER NOT NULL,
+    code_value   TEXT    NOT NULL,
+    is_published INTEGER NOT NULL DEFAULT 0,
+    expiration   TEXT    NOT NULL,
+    price        REAL    NOT NULL
+);
+
+CREATE UNIQUE INDEX idx_products_code_value ON products (code_value);
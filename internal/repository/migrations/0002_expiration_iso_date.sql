@@ -0,0 +1,6 @@
+-- expiration dates are stored as ISO-8601 yyyy-mm-dd text instead of dd/mm/yyyy
+UPDATE products
+SET expiration = substr(expiration, 7, 4) || '-' || substr(expiration, 4, 2) || '-' || substr(expiration, 1, 2)
+WHERE expiration LIKE '__/__/____';
+
+CREATE INDEX idx_products_expiration ON products (expiration);
@@ -0,0 +1,8 @@
+-- prices are stored as an integer amount of minor units plus their ISO-4217 currency instead of a float,
+-- existing prices are rounded to cents of the default currency
+ALTER TABLE products ADD COLUMN price_amount INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE products ADD COLUMN price_currency TEXT NOT NULL DEFAULT 'USD';
+
+UPDATE products SET price_amount = CAST(ROUND(price * 100) AS INTEGER);
+
+ALTER TABLE products DROP COLUMN price;
@@ -0,0 +1,2 @@
+-- version is incremented by every update, letting writers detect they read a stale product
+ALTER TABLE products ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,6 @@
+-- deleted products are kept in a trash, deleted_at holding when they were moved there (RFC 3339, UTC).
+-- Codes are only unique among the active products.
+ALTER TABLE products ADD COLUMN deleted_at TEXT;
+
+DROP INDEX idx_products_code_value;
+CREATE UNIQUE INDEX idx_products_code_value ON products (code_value) WHERE deleted_at IS NULL;
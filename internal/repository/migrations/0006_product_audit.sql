@@ -0,0 +1,11 @@
+-- audit log of the product mutations, changes holding the json list of {field, before, after}
+CREATE TABLE product_audit (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    product_id INTEGER NOT NULL,
+    actor      TEXT    NOT NULL,
+    timestamp  TEXT    NOT NULL,
+    operation  TEXT    NOT NULL,
+    changes    TEXT    NOT NULL
+);
+
+CREATE INDEX idx_product_audit_product_id ON product_audit (product_id);
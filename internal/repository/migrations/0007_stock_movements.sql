@@ -0,0 +1,13 @@
+-- ledger of the stock movements of the products, quantity being the one left after the movement
+CREATE TABLE stock_movements (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    product_id INTEGER NOT NULL,
+    delta      INTEGER NOT NULL,
+    quantity   INTEGER NOT NULL,
+    reason     TEXT    NOT NULL,
+    reference  TEXT    NOT NULL DEFAULT '',
+    actor      TEXT    NOT NULL,
+    timestamp  TEXT    NOT NULL
+);
+
+CREATE INDEX idx_stock_movements_product_id ON stock_movements (product_id);
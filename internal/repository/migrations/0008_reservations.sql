@@ -0,0 +1,15 @@
+-- reservations holding stock of the products, times being in a fixed width layout so they compare as text
+CREATE TABLE reservations (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    product_id INTEGER NOT NULL,
+    quantity   INTEGER NOT NULL,
+    status     TEXT    NOT NULL,
+    reference  TEXT    NOT NULL DEFAULT '',
+    actor      TEXT    NOT NULL,
+    created_at TEXT    NOT NULL,
+    expires_at TEXT    NOT NULL,
+    updated_at TEXT
+);
+
+CREATE INDEX idx_reservations_product_id ON reservations (product_id);
+CREATE INDEX idx_reservations_active ON reservations (expires_at) WHERE status = 'active';
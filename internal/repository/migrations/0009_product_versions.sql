@@ -0,0 +1,14 @@
+-- every version of the products, with the columns of the products table so they are read the same way
+CREATE TABLE product_versions (
+    id             INTEGER NOT NULL,
+    version        INTEGER NOT NULL,
+    name           TEXT    NOT NULL,
+    quantity       INTEGER NOT NULL,
+    code_value     TEXT    NOT NULL,
+    is_published   INTEGER NOT NULL,
+    expiration     TEXT    NOT NULL,
+    price_amount   INTEGER NOT NULL,
+    price_currency TEXT    NOT NULL,
+    deleted_at     TEXT,
+    PRIMARY KEY (id, version)
+);
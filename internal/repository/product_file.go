@@ -0,0 +1,262 @@
+package repository
+
+import (
+	"app/internal"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ProductFile is a product repository persisted to a json file.
+// Products are served from memory and the whole file is rewritten on every mutation.
+// It is safe for concurrent use: mutations and their writes to the file are serialized,
+// and reads wait for the mutation in progress so they never see one rolled back.
+type ProductFile struct {
+	mu   sync.RWMutex
+	path string
+	pm   *ProductMap
+}
+
+// productFileJSON is the content of the file
+type productFileJSON struct {
+	LastID   int                   `json:"last_id"`
+	Products []productFileItemJSON `json:"products"`
+}
+
+type productFileItemJSON struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Quantity    int    `json:"quantity"`
+	CodeValue   string `json:"code_value"`
+	IsPublished bool   `json:"is_published"`
+	// Expiration is written as yyyy-mm-dd, files written as dd/mm/yyyy are still read
+	Expiration internal.Date `json:"expiration"`
+	// Price is written as {"amount", "currency"}, files written with a bare number are still read
+	Price     internal.Money `json:"price"`
+	Version   int            `json:"version"`
+	DeletedAt *time.Time     `json:"deleted_at,omitempty"`
+}
+
+// NewProductFile returns a repository backed by the file at path, loading its products.
+// A missing file is treated as an empty repository and created on the first mutation.
+func NewProductFile(path string) (*ProductFile, error) {
+	pf := &ProductFile{
+		path: path,
+	}
+
+	if err := pf.load(); err != nil {
+		return nil, err
+	}
+
+	return pf, nil
+}
+
+func (pf *ProductFile) Save(product *internal.Product) error {
+	return pf.mutate(func() error {
+		return pf.pm.Save(product)
+	})
+}
+
+func (pf *ProductFile) SaveAll(products []*internal.Product) error {
+	return pf.mutate(func() error {
+		return pf.pm.SaveAll(products)
+	})
+}
+
+func (pf *ProductFile) GetById(id int) (internal.Product, error) {
+	pf.mu.RLock()
+	defer pf.mu.RUnlock()
+
+	return pf.pm.GetById(id)
+}
+
+func (pf *ProductFile) GetByCode(code string) (internal.Product, error) {
+	pf.mu.RLock()
+	defer pf.mu.RUnlock()
+
+	return pf.pm.GetByCode(code)
+}
+
+func (pf *ProductFile) Update(product *internal.Product) error {
+	return pf.mutate(func() error {
+		return pf.pm.Update(product)
+	})
+}
+
+func (pf *ProductFile) Delete(id int) error {
+	return pf.mutate(func() error {
+		return pf.pm.Delete(id)
+	})
+}
+
+func (pf *ProductFile) Restore(id int) error {
+	return pf.mutate(func() error {
+		return pf.pm.Restore(id)
+	})
+}
+
+func (pf *ProductFile) Purge(id int) error {
+	return pf.mutate(func() error {
+		return pf.pm.Purge(id)
+	})
+}
+
+func (pf *ProductFile) AdjustStock(id int, delta int) (product internal.Product, err error) {
+	err = pf.mutate(func() error {
+		product, err = pf.pm.AdjustStock(id, delta)
+		return err
+	})
+	return
+}
+
+func (pf *ProductFile) Find(query internal.ProductQuery) ([]internal.Product, int, error) {
+	pf.mu.RLock()
+	defer pf.mu.RUnlock()
+
+	return pf.pm.Find(query)
+}
+
+// Writable checks the products can be written, creating and removing a temporary file next to the file
+func (pf *ProductFile) Writable() error {
+	tmp, err := os.CreateTemp(filepath.Dir(pf.path), filepath.Base(pf.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("product file: %w", err)
+	}
+	tmp.Close()
+
+	if err := os.Remove(tmp.Name()); err != nil {
+		return fmt.Errorf("product file: %w", err)
+	}
+
+	return nil
+}
+
+// mutate runs fn against the in-memory products and persists the result.
+// If the file can not be written the in-memory products are rolled back.
+func (pf *ProductFile) mutate(fn func() error) error {
+	pf.mu.Lock()
+	defer pf.mu.Unlock()
+
+	db, lastId := pf.pm.snapshot()
+
+	if err := fn(); err != nil {
+		return err
+	}
+
+	if err := pf.write(); err != nil {
+		pf.pm.restore(db, lastId)
+		return err
+	}
+
+	return nil
+}
+
+// load reads the products from the file
+func (pf *ProductFile) load() error {
+	bytes, err := os.ReadFile(pf.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			pf.pm = NewProductMap(nil, 0)
+			return nil
+		}
+		return fmt.Errorf("product file: %w", err)
+	}
+
+	var content productFileJSON
+	if err := json.Unmarshal(bytes, &content); err != nil {
+		return fmt.Errorf("product file: %w", err)
+	}
+
+	db := make(map[int]internal.Product, len(content.Products))
+	for _, item := range content.Products {
+		product := internal.Product{
+			ID:          item.ID,
+			Name:        item.Name,
+			Quantity:    item.Quantity,
+			CodeValue:   item.CodeValue,
+			IsPublished: item.IsPublished,
+			Expiration:  item.Expiration,
+			Price:       item.Price,
+			Version:     item.Version,
+		}
+		if item.DeletedAt != nil {
+			product.DeletedAt = *item.DeletedAt
+		}
+		db[item.ID] = product
+	}
+
+	pf.pm = NewProductMap(db, content.LastID)
+
+	return nil
+}
+
+// write atomically replaces the file with the in-memory products:
+// they are written to a temporary file in the same directory which is then renamed
+func (pf *ProductFile) write() (err error) {
+	db, lastId := pf.pm.snapshot()
+	products := make([]internal.Product, 0, len(db))
+	for _, prod := range db {
+		products = append(products, prod)
+	}
+	sortProducts(products, nil)
+
+	content := productFileJSON{
+		LastID:   lastId,
+		Products: make([]productFileItemJSON, 0, len(products)),
+	}
+	for _, prod := range products {
+		item := productFileItemJSON{
+			ID:          prod.ID,
+			Name:        prod.Name,
+			Quantity:    prod.Quantity,
+			CodeValue:   prod.CodeValue,
+			IsPublished: prod.IsPublished,
+			Expiration:  prod.Expiration,
+			Price:       prod.Price,
+			Version:     prod.Version,
+		}
+		if prod.IsDeleted() {
+			// prod is reused across iterations, its deleted_at is copied
+			deletedAt := prod.DeletedAt
+			item.DeletedAt = &deletedAt
+		}
+		content.Products = append(content.Products, item)
+	}
+
+	bytes, err := json.MarshalIndent(content, "", "  ")
+	if err != nil {
+		return fmt.Errorf("product file: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(pf.path), filepath.Base(pf.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("product file: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err = tmp.Write(bytes); err != nil {
+		tmp.Close()
+		return fmt.Errorf("product file: %w", err)
+	}
+	if err = tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("product file: %w", err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("product file: %w", err)
+	}
+
+	if err = os.Rename(tmp.Name(), pf.path); err != nil {
+		return fmt.Errorf("product file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,237 @@
+package repository_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for ProductFile
+func TestProductFile(t *testing.T) {
+	t.Run("success - an existing file is loaded", func(t *testing.T) {
+		// arrange
+		path := filepath.Join(t.TempDir(), "products.json")
+		content := `{"last_id": 7, "products": [
+			{"id": 7, "name": "legacy", "quantity": 3, "code_value": "legacy", "is_published": true,
+			 "expiration": "15/03/2030", "price": 12.5, "version": 2},
+			{"id": 5, "name": "undated", "quantity": 1, "code_value": "undated", "expiration": "", "price": "1.00", "version": 1}
+		]}`
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+		// act
+		rp, err := repository.NewProductFile(path)
+
+		// assert
+		require.NoError(t, err)
+		product, err := rp.GetById(7)
+		require.NoError(t, err)
+		require.Equal(t, "legacy", product.Name)
+		require.Equal(t, "2030-03-15", product.Expiration.String())
+		require.Equal(t, internal.Money{Amount: 1250, Currency: "USD"}, product.Price)
+		require.Equal(t, 2, product.Version)
+		undated, err := rp.GetById(5)
+		require.NoError(t, err)
+		require.True(t, undated.Expiration.IsZero())
+	})
+
+	t.Run("success - a missing file is an empty repository", func(t *testing.T) {
+		// act
+		rp, err := repository.NewProductFile(filepath.Join(t.TempDir(), "products.json"))
+
+		// assert
+		require.NoError(t, err)
+		_, total, err := rp.Find(internal.ProductQuery{})
+		require.NoError(t, err)
+		require.Equal(t, 0, total)
+	})
+
+	t.Run("success - mutations are persisted across instances", func(t *testing.T) {
+		// arrange
+		path := filepath.Join(t.TempDir(), "products.json")
+		rp, err := repository.NewProductFile(path)
+		require.NoError(t, err)
+		product := internal.Product{Name: "product", CodeValue: "code", Quantity: 1}
+		require.NoError(t, rp.Save(&product))
+		product.Name = "renamed"
+		require.NoError(t, rp.Update(&product))
+
+		// act
+		reloaded, err := repository.NewProductFile(path)
+
+		// assert
+		require.NoError(t, err)
+		found, err := reloaded.GetByCode("code")
+		require.NoError(t, err)
+		require.Equal(t, "renamed", found.Name)
+		require.Equal(t, product.Version, found.Version)
+	})
+
+	t.Run("success - the trash is persisted across instances", func(t *testing.T) {
+		// arrange
+		path := filepath.Join(t.TempDir(), "products.json")
+		rp, err := repository.NewProductFile(path)
+		require.NoError(t, err)
+		deleted := internal.Product{Name: "deleted", CodeValue: "deleted"}
+		restored := internal.Product{Name: "restored", CodeValue: "restored"}
+		require.NoError(t, rp.Save(&deleted))
+		require.NoError(t, rp.Save(&restored))
+		require.NoError(t, rp.Delete(deleted.ID))
+		require.NoError(t, rp.Delete(restored.ID))
+		require.NoError(t, rp.Restore(restored.ID))
+
+		// act
+		reloaded, err := repository.NewProductFile(path)
+
+		// assert
+		require.NoError(t, err)
+		_, err = reloaded.GetById(deleted.ID)
+		require.ErrorIs(t, err, internal.ErrProductNotFound)
+		trash, _, err := reloaded.Find(internal.ProductQuery{Deleted: true})
+		require.NoError(t, err)
+		require.Len(t, trash, 1)
+		require.Equal(t, deleted.ID, trash[0].ID)
+		require.False(t, trash[0].DeletedAt.IsZero())
+		found, err := reloaded.GetById(restored.ID)
+		require.NoError(t, err)
+		require.True(t, found.DeletedAt.IsZero())
+	})
+
+	t.Run("success - ids continue after the last one, even when it was purged", func(t *testing.T) {
+		// arrange
+		path := filepath.Join(t.TempDir(), "products.json")
+		rp, err := repository.NewProductFile(path)
+		require.NoError(t, err)
+		first := internal.Product{Name: "first", CodeValue: "first"}
+		second := internal.Product{Name: "second", CodeValue: "second"}
+		require.NoError(t, rp.Save(&first))
+		require.NoError(t, rp.Save(&second))
+		require.NoError(t, rp.Purge(second.ID))
+
+		// act
+		reloaded, err := repository.NewProductFile(path)
+		require.NoError(t, err)
+		third := internal.Product{Name: "third", CodeValue: "third"}
+		err = reloaded.Save(&third)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, second.ID+1, third.ID)
+	})
+
+	t.Run("error - a failed write rolls the mutation back", func(t *testing.T) {
+		// arrange
+		dir := filepath.Join(t.TempDir(), "data")
+		require.NoError(t, os.Mkdir(dir, 0o755))
+		rp, err := repository.NewProductFile(filepath.Join(dir, "products.json"))
+		require.NoError(t, err)
+		saved := internal.Product{Name: "saved", CodeValue: "saved"}
+		require.NoError(t, rp.Save(&saved))
+		// the temporary file can not be created anymore
+		require.NoError(t, os.RemoveAll(dir))
+
+		// act
+		product := internal.Product{Name: "lost", CodeValue: "lost"}
+		errSave := rp.Save(&product)
+		errDelete := rp.Delete(saved.ID)
+
+		// assert
+		require.Error(t, errSave)
+		require.Error(t, errDelete)
+		_, err = rp.GetByCode("lost")
+		require.ErrorIs(t, err, internal.ErrProductNotFound)
+		found, err := rp.GetById(saved.ID)
+		require.NoError(t, err)
+		require.False(t, found.IsDeleted())
+	})
+
+	t.Run("error - the file is not valid json", func(t *testing.T) {
+		// arrange
+		path := filepath.Join(t.TempDir(), "products.json")
+		require.NoError(t, os.WriteFile(path, []byte("{"), 0o644))
+
+		// act
+		_, err := repository.NewProductFile(path)
+
+		// assert
+		require.Error(t, err)
+	})
+}
+
+// Tests for ProductFile under concurrent use
+func TestProductFile_Concurrent(t *testing.T) {
+	t.Run("save and delete are persisted", func(t *testing.T) {
+		// arrange
+		path := filepath.Join(t.TempDir(), "products.json")
+		rp, err := repository.NewProductFile(path)
+		require.NoError(t, err)
+
+		// act
+		var wg sync.WaitGroup
+		for i := 0; i < concurrentWorkers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				product := internal.Product{Name: "product", CodeValue: fmt.Sprintf("code-%d", i)}
+				if err := rp.Save(&product); err != nil {
+					return
+				}
+				if i%2 == 0 {
+					rp.Delete(product.ID)
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		// assert
+		reloaded, err := repository.NewProductFile(path)
+		require.NoError(t, err)
+		_, total, err := reloaded.Find(internal.ProductQuery{})
+		require.NoError(t, err)
+		require.Equal(t, concurrentWorkers/2, total)
+	})
+
+	t.Run("reads never see a mutation rolled back", func(t *testing.T) {
+		// arrange
+		path := filepath.Join(t.TempDir(), "products.json")
+		rp, err := repository.NewProductFile(path)
+		require.NoError(t, err)
+		// the file can not be replaced by the written one, so every save is rolled back once written
+		require.NoError(t, os.Mkdir(path, 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(path, "content"), nil, 0o644))
+
+		// act
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		seen := 0
+		for i := 0; i < concurrentWorkers; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				product := internal.Product{Name: "product", CodeValue: "code"}
+				rp.Save(&product)
+			}()
+			go func() {
+				defer wg.Done()
+				for j := 0; j < concurrentWorkers; j++ {
+					_, errCode := rp.GetByCode("code")
+					_, total, _ := rp.Find(internal.ProductQuery{})
+					if errCode == nil || total > 0 {
+						mu.Lock()
+						seen++
+						mu.Unlock()
+					}
+				}
+			}()
+		}
+		wg.Wait()
+
+		// assert
+		require.Equal(t, 0, seen)
+	})
+}
@@ -1,72 +1,273 @@
 package repository
 
-import "app/internal"
+import (
+	"app/internal"
+	"sync"
+	"time"
+)
 
+// ProductMap is an in-memory product repository safe for concurrent use.
+// Reads share a read lock while writes, including their code_value uniqueness check, hold the write lock.
 type ProductMap struct {
+	mu     sync.RWMutex
 	db     map[int]internal.Product
 	lastId int
+	// codes indexes the product ids by code_value
+	codes map[string]int
 }
 
 func NewProductMap(db map[int]internal.Product, startingId int) *ProductMap {
+	// the seed is copied, so the caller's map is never mutated nor shared with the repository
+	defaultDb := make(map[int]internal.Product, len(db))
+
+	// ids must never be reused, so the last id can not be behind the seed
+	for id, product := range db {
+		if id > startingId {
+			startingId = id
+		}
+		// products seeded before versioning start at the first version
+		if product.Version == 0 {
+			product.Version = 1
+		}
+		defaultDb[id] = product
+	}
+
 	return &ProductMap{
-		db:     make(map[int]internal.Product),
+		db:     defaultDb,
 		lastId: startingId,
+		codes:  indexCodes(defaultDb),
+	}
+}
+
+// indexCodes builds the code_value index of the active products
+func indexCodes(db map[int]internal.Product) map[string]int {
+	codes := make(map[string]int, len(db))
+	for id, prod := range db {
+		if !prod.IsDeleted() {
+			codes[prod.CodeValue] = id
+		}
 	}
+	return codes
 }
 
 func (pm *ProductMap) Save(product *internal.Product) error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
 
-	for _, prod := range pm.db {
-		if prod.CodeValue == product.CodeValue {
-			return internal.ErrProductCodeAlreadyExists
-		}
+	if _, ok := pm.codes[product.CodeValue]; ok {
+		return internal.ErrProductCodeAlreadyExists
 	}
 
 	pm.lastId++
 
 	product.ID = pm.lastId
+	product.Version = 1
 
 	pm.db[product.ID] = *product
+	pm.codes[product.CodeValue] = product.ID
+
+	return nil
+}
+
+func (pm *ProductMap) SaveAll(products []*internal.Product) error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	// check every code before saving anything
+	codes := make(map[string]bool, len(products))
+	for i, product := range products {
+		if _, ok := pm.codes[product.CodeValue]; ok || codes[product.CodeValue] {
+			return &internal.BatchItemError{Index: i, Err: internal.ErrProductCodeAlreadyExists}
+		}
+		codes[product.CodeValue] = true
+	}
+
+	for _, product := range products {
+		pm.lastId++
+
+		product.ID = pm.lastId
+		product.Version = 1
+
+		pm.db[product.ID] = *product
+		pm.codes[product.CodeValue] = product.ID
+	}
 
 	return nil
 }
 
 func (pm *ProductMap) GetById(id int) (internal.Product, error) {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
 	product, ok := pm.db[id]
 
-	if !ok {
+	if !ok || product.IsDeleted() {
 		return internal.Product{}, internal.ErrProductNotFound
 	}
 
 	return product, nil
 }
 
-func (pm *ProductMap) Update(product *internal.Product) error {
-	_, ok := pm.db[product.ID]
+func (pm *ProductMap) GetByCode(code string) (internal.Product, error) {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	id, ok := pm.codes[code]
 
 	if !ok {
+		return internal.Product{}, internal.ErrProductNotFound
+	}
+
+	return pm.db[id], nil
+}
+
+func (pm *ProductMap) Update(product *internal.Product) error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	current, ok := pm.db[product.ID]
+
+	if !ok || current.IsDeleted() {
 		return internal.ErrProductNotFound
 	}
 
-	for _, prod := range pm.db {
-		if prod.CodeValue == product.CodeValue && prod.ID != product.ID {
-			return internal.ErrProductCodeAlreadyExists
-		}
+	if product.Version != 0 && product.Version != current.Version {
+		return internal.ErrProductVersionConflict
+	}
+
+	if id, ok := pm.codes[product.CodeValue]; ok && id != product.ID {
+		return internal.ErrProductCodeAlreadyExists
 	}
 
+	product.Version = current.Version + 1
+
 	pm.db[product.ID] = *product
+	delete(pm.codes, current.CodeValue)
+	pm.codes[product.CodeValue] = product.ID
 
 	return nil
 }
 
 func (pm *ProductMap) Delete(id int) error {
-	_, ok := pm.db[id]
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	current, ok := pm.db[id]
+
+	if !ok || current.IsDeleted() {
+		return internal.ErrProductNotFound
+	}
+
+	current.DeletedAt = time.Now()
+	current.Version++
+
+	pm.db[id] = current
+	delete(pm.codes, current.CodeValue)
+
+	return nil
+}
+
+func (pm *ProductMap) Restore(id int) error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	current, ok := pm.db[id]
+
+	if !ok || !current.IsDeleted() {
+		return internal.ErrProductNotFound
+	}
+
+	if _, ok := pm.codes[current.CodeValue]; ok {
+		return internal.ErrProductCodeAlreadyExists
+	}
+
+	current.DeletedAt = time.Time{}
+	current.Version++
+
+	pm.db[id] = current
+	pm.codes[current.CodeValue] = id
+
+	return nil
+}
+
+func (pm *ProductMap) Purge(id int) error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	current, ok := pm.db[id]
 
 	if !ok {
 		return internal.ErrProductNotFound
 	}
 
 	delete(pm.db, id)
+	if !current.IsDeleted() {
+		delete(pm.codes, current.CodeValue)
+	}
 
 	return nil
 }
+
+func (pm *ProductMap) AdjustStock(id int, delta int) (internal.Product, error) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	current, ok := pm.db[id]
+
+	if !ok || current.IsDeleted() {
+		return internal.Product{}, internal.ErrProductNotFound
+	}
+
+	if current.Quantity+delta < 0 {
+		return internal.Product{}, internal.ErrStockInsufficient
+	}
+
+	current.Quantity += delta
+	current.Version++
+
+	pm.db[id] = current
+
+	return current, nil
+}
+
+func (pm *ProductMap) Find(query internal.ProductQuery) ([]internal.Product, int, error) {
+	pm.mu.RLock()
+	products := pm.values()
+	pm.mu.RUnlock()
+
+	result, total := findProducts(products, query)
+
+	return result, total, nil
+}
+
+// values returns the stored products in no particular order.
+// The caller must hold the lock.
+func (pm *ProductMap) values() []internal.Product {
+	products := make([]internal.Product, 0, len(pm.db))
+	for _, prod := range pm.db {
+		products = append(products, prod)
+	}
+	return products
+}
+
+// snapshot returns a copy of the stored products and the last id
+func (pm *ProductMap) snapshot() (map[int]internal.Product, int) {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	db := make(map[int]internal.Product, len(pm.db))
+	for id, prod := range pm.db {
+		db[id] = prod
+	}
+	return db, pm.lastId
+}
+
+// restore replaces the stored products and the last id with a snapshot
+func (pm *ProductMap) restore(db map[int]internal.Product, lastId int) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	pm.db = db
+	pm.lastId = lastId
+	pm.codes = indexCodes(db)
+}
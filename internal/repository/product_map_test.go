@@ -0,0 +1,374 @@
+package repository_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// concurrentWorkers is the number of goroutines hammering the repository in each test.
+// Run the tests with -race so the detector can catch unguarded accesses.
+const concurrentWorkers = 100
+
+// Tests for NewProductMap
+func TestNewProductMap(t *testing.T) {
+	t.Run("success - the seed is copied", func(t *testing.T) {
+		// arrange
+		db := map[int]internal.Product{1: {ID: 1, Name: "seed", CodeValue: "seed"}}
+
+		// act
+		rp := repository.NewProductMap(db, 0)
+		product := internal.Product{Name: "product", CodeValue: "code"}
+		require.NoError(t, rp.Save(&product))
+		require.NoError(t, rp.Delete(1))
+
+		// assert
+		require.Equal(t, map[int]internal.Product{1: {ID: 1, Name: "seed", CodeValue: "seed"}}, db)
+		require.Equal(t, 2, product.ID)
+	})
+
+	t.Run("success - seeded products start at the first version", func(t *testing.T) {
+		// arrange
+		db := map[int]internal.Product{1: {ID: 1, Name: "seed", CodeValue: "seed"}}
+
+		// act
+		rp := repository.NewProductMap(db, 0)
+
+		// assert
+		product, err := rp.GetById(1)
+		require.NoError(t, err)
+		require.Equal(t, 1, product.Version)
+	})
+}
+
+// Tests for ProductMap under concurrent use
+func TestProductMap_Concurrent(t *testing.T) {
+	t.Run("save - distinct codes get unique ids", func(t *testing.T) {
+		// arrange
+		rp := repository.NewProductMap(nil, 0)
+
+		// act
+		var wg sync.WaitGroup
+		ids := make([]int, concurrentWorkers)
+		errs := make([]error, concurrentWorkers)
+		for i := 0; i < concurrentWorkers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				product := internal.Product{Name: "product", CodeValue: fmt.Sprintf("code-%d", i)}
+				errs[i] = rp.Save(&product)
+				ids[i] = product.ID
+			}(i)
+		}
+		wg.Wait()
+
+		// assert
+		seen := make(map[int]bool)
+		for i := 0; i < concurrentWorkers; i++ {
+			require.NoError(t, errs[i])
+			require.False(t, seen[ids[i]], "id %d assigned twice", ids[i])
+			seen[ids[i]] = true
+		}
+		_, total, err := rp.Find(internal.ProductQuery{})
+		require.NoError(t, err)
+		require.Equal(t, concurrentWorkers, total)
+	})
+
+	t.Run("save - same code is stored once", func(t *testing.T) {
+		// arrange
+		rp := repository.NewProductMap(nil, 0)
+
+		// act
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		saved, conflicts := 0, 0
+		for i := 0; i < concurrentWorkers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				product := internal.Product{Name: "product", CodeValue: "code"}
+				err := rp.Save(&product)
+
+				mu.Lock()
+				defer mu.Unlock()
+				switch {
+				case err == nil:
+					saved++
+				case errors.Is(err, internal.ErrProductCodeAlreadyExists):
+					conflicts++
+				}
+			}()
+		}
+		wg.Wait()
+
+		// assert
+		require.Equal(t, 1, saved)
+		require.Equal(t, concurrentWorkers-1, conflicts)
+	})
+
+	t.Run("update - taking the same code conflicts", func(t *testing.T) {
+		// arrange
+		rp := repository.NewProductMap(nil, 0)
+		ids := make([]int, concurrentWorkers)
+		for i := range ids {
+			product := internal.Product{Name: "product", CodeValue: fmt.Sprintf("code-%d", i)}
+			require.NoError(t, rp.Save(&product))
+			ids[i] = product.ID
+		}
+
+		// act
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		updated := 0
+		for _, id := range ids {
+			wg.Add(1)
+			go func(id int) {
+				defer wg.Done()
+				product := internal.Product{ID: id, Name: "product", CodeValue: "shared"}
+				if err := rp.Update(&product); err == nil {
+					mu.Lock()
+					updated++
+					mu.Unlock()
+				}
+			}(id)
+		}
+		wg.Wait()
+
+		// assert
+		require.Equal(t, 1, updated)
+	})
+
+	t.Run("update - writers of the same version conflict", func(t *testing.T) {
+		// arrange
+		rp := repository.NewProductMap(nil, 0)
+		saved := internal.Product{Name: "product", CodeValue: "code"}
+		require.NoError(t, rp.Save(&saved))
+
+		// act
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		updated, conflicts := 0, 0
+		for i := 0; i < concurrentWorkers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				product := saved
+				product.Quantity = i
+				err := rp.Update(&product)
+
+				mu.Lock()
+				defer mu.Unlock()
+				switch {
+				case err == nil:
+					updated++
+				case errors.Is(err, internal.ErrProductVersionConflict):
+					conflicts++
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		// assert
+		require.Equal(t, 1, updated)
+		require.Equal(t, concurrentWorkers-1, conflicts)
+		product, err := rp.GetById(saved.ID)
+		require.NoError(t, err)
+		require.Equal(t, saved.Version+1, product.Version)
+	})
+
+	t.Run("delete and restore - the code is taken by a single product", func(t *testing.T) {
+		// arrange
+		rp := repository.NewProductMap(nil, 0)
+		deleted := internal.Product{Name: "product", CodeValue: "code"}
+		require.NoError(t, rp.Save(&deleted))
+		require.NoError(t, rp.Delete(deleted.ID))
+
+		// act
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		taken := 0
+		for i := 0; i < concurrentWorkers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				var err error
+				if i%2 == 0 {
+					err = rp.Restore(deleted.ID)
+				} else {
+					product := internal.Product{Name: "product", CodeValue: "code"}
+					err = rp.Save(&product)
+				}
+				if err == nil {
+					mu.Lock()
+					taken++
+					mu.Unlock()
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		// assert
+		require.Equal(t, 1, taken)
+		product, err := rp.GetByCode("code")
+		require.NoError(t, err)
+		require.False(t, product.IsDeleted())
+	})
+
+	t.Run("adjust stock - decrements never take the quantity below zero", func(t *testing.T) {
+		// arrange
+		rp := repository.NewProductMap(nil, 0)
+		saved := internal.Product{Name: "product", CodeValue: "code", Quantity: concurrentWorkers / 2}
+		require.NoError(t, rp.Save(&saved))
+
+		// act
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		decremented, insufficient := 0, 0
+		for i := 0; i < concurrentWorkers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := rp.AdjustStock(saved.ID, -1)
+
+				mu.Lock()
+				defer mu.Unlock()
+				switch {
+				case err == nil:
+					decremented++
+				case errors.Is(err, internal.ErrStockInsufficient):
+					insufficient++
+				}
+			}()
+		}
+		wg.Wait()
+
+		// assert
+		require.Equal(t, concurrentWorkers/2, decremented)
+		require.Equal(t, concurrentWorkers/2, insufficient)
+		product, err := rp.GetById(saved.ID)
+		require.NoError(t, err)
+		require.Equal(t, 0, product.Quantity)
+	})
+
+	t.Run("get by code - index follows code changes", func(t *testing.T) {
+		// arrange
+		rp := repository.NewProductMap(nil, 0)
+		ids := make([]int, concurrentWorkers)
+		for i := range ids {
+			product := internal.Product{Name: "product", CodeValue: fmt.Sprintf("code-%d", i)}
+			require.NoError(t, rp.Save(&product))
+			ids[i] = product.ID
+		}
+
+		// act
+		var wg sync.WaitGroup
+		for i, id := range ids {
+			wg.Add(1)
+			go func(i, id int) {
+				defer wg.Done()
+				product := internal.Product{ID: id, Name: "product", CodeValue: fmt.Sprintf("renamed-%d", i)}
+				rp.Update(&product)
+				rp.GetByCode(fmt.Sprintf("code-%d", i))
+			}(i, id)
+		}
+		wg.Wait()
+
+		// assert
+		for i, id := range ids {
+			_, err := rp.GetByCode(fmt.Sprintf("code-%d", i))
+			require.ErrorIs(t, err, internal.ErrProductNotFound)
+			product, err := rp.GetByCode(fmt.Sprintf("renamed-%d", i))
+			require.NoError(t, err)
+			require.Equal(t, id, product.ID)
+		}
+	})
+
+	t.Run("save, update, delete and read mixed", func(t *testing.T) {
+		// arrange
+		rp := repository.NewProductMap(nil, 0)
+
+		// act
+		var wg sync.WaitGroup
+		for i := 0; i < concurrentWorkers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				product := internal.Product{Name: "product", CodeValue: fmt.Sprintf("code-%d", i)}
+				if err := rp.Save(&product); err != nil {
+					return
+				}
+
+				product.Quantity = i
+				rp.Update(&product)
+				rp.GetById(product.ID)
+				rp.Find(internal.ProductQuery{Sort: []internal.ProductSort{{Field: internal.ProductSortQuantity}}})
+				if i%2 == 0 {
+					rp.Delete(product.ID)
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		// assert
+		_, total, err := rp.Find(internal.ProductQuery{})
+		require.NoError(t, err)
+		require.Equal(t, concurrentWorkers/2, total)
+	})
+}
+
+// catalogSizes are the catalog sizes benchmarks run against.
+// With the code_value index the cost per operation must not grow with the catalog.
+var catalogSizes = []int{100, 1_000, 10_000, 100_000}
+
+// newCatalog returns a ProductMap holding n products with codes code-0 ... code-(n-1)
+func newCatalog(n int) *repository.ProductMap {
+	db := make(map[int]internal.Product, n)
+	for i := 0; i < n; i++ {
+		db[i+1] = internal.Product{ID: i + 1, Name: "product", CodeValue: fmt.Sprintf("code-%d", i)}
+	}
+	return repository.NewProductMap(db, n)
+}
+
+func BenchmarkProductMap_Save(b *testing.B) {
+	for _, size := range catalogSizes {
+		b.Run(fmt.Sprintf("catalog %d", size), func(b *testing.B) {
+			rp := newCatalog(size)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				product := internal.Product{Name: "product", CodeValue: fmt.Sprintf("new-%d", i)}
+				rp.Save(&product)
+			}
+		})
+	}
+}
+
+func BenchmarkProductMap_Update(b *testing.B) {
+	for _, size := range catalogSizes {
+		b.Run(fmt.Sprintf("catalog %d", size), func(b *testing.B) {
+			rp := newCatalog(size)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				product := internal.Product{ID: 1, Name: "product", CodeValue: fmt.Sprintf("new-%d", i)}
+				rp.Update(&product)
+			}
+		})
+	}
+}
+
+func BenchmarkProductMap_GetByCode(b *testing.B) {
+	for _, size := range catalogSizes {
+		b.Run(fmt.Sprintf("catalog %d", size), func(b *testing.B) {
+			rp := newCatalog(size)
+			code := fmt.Sprintf("code-%d", size-1)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				rp.GetByCode(code)
+			}
+		})
+	}
+}
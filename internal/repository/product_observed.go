@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"app/internal"
+	"time"
+)
+
+// ProductObserver is called after every operation of a ProductObserved with its name, its duration and its error
+type ProductObserver func(operation string, duration time.Duration, err error)
+
+// ProductObserved is a product repository decorator reporting every operation to an observer, e.g. to time them
+type ProductObserved struct {
+	rp      internal.ProductRepository
+	observe ProductObserver
+}
+
+// NewProductObserved returns a decorator of rp reporting its operations to observe
+func NewProductObserved(rp internal.ProductRepository, observe ProductObserver) *ProductObserved {
+	return &ProductObserved{
+		rp:      rp,
+		observe: observe,
+	}
+}
+
+func (po *ProductObserved) Save(product *internal.Product) error {
+	start := time.Now()
+	return po.done("save", start, po.rp.Save(product))
+}
+
+func (po *ProductObserved) SaveAll(products []*internal.Product) error {
+	start := time.Now()
+	return po.done("save_all", start, po.rp.SaveAll(products))
+}
+
+func (po *ProductObserved) GetById(id int) (internal.Product, error) {
+	start := time.Now()
+	product, err := po.rp.GetById(id)
+	return product, po.done("get_by_id", start, err)
+}
+
+func (po *ProductObserved) GetByCode(code string) (internal.Product, error) {
+	start := time.Now()
+	product, err := po.rp.GetByCode(code)
+	return product, po.done("get_by_code", start, err)
+}
+
+func (po *ProductObserved) Update(product *internal.Product) error {
+	start := time.Now()
+	return po.done("update", start, po.rp.Update(product))
+}
+
+func (po *ProductObserved) Delete(id int) error {
+	start := time.Now()
+	return po.done("delete", start, po.rp.Delete(id))
+}
+
+func (po *ProductObserved) Restore(id int) error {
+	start := time.Now()
+	return po.done("restore", start, po.rp.Restore(id))
+}
+
+func (po *ProductObserved) Purge(id int) error {
+	start := time.Now()
+	return po.done("purge", start, po.rp.Purge(id))
+}
+
+func (po *ProductObserved) AdjustStock(id int, delta int) (internal.Product, error) {
+	start := time.Now()
+	product, err := po.rp.AdjustStock(id, delta)
+	return product, po.done("adjust_stock", start, err)
+}
+
+func (po *ProductObserved) Find(query internal.ProductQuery) ([]internal.Product, int, error) {
+	start := time.Now()
+	products, total, err := po.rp.Find(query)
+	return products, total, po.done("find", start, err)
+}
+
+// done reports the operation started at start, returning its error
+func (po *ProductObserved) done(operation string, start time.Time, err error) error {
+	po.observe(operation, time.Since(start), err)
+	return err
+}
@@ -0,0 +1,42 @@
+package repository_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// observation is an operation reported by ProductObserved
+type observation struct {
+	operation string
+	err       error
+}
+
+// Tests for ProductObserved
+func TestProductObserved(t *testing.T) {
+	t.Run("success - every operation is reported with its error", func(t *testing.T) {
+		// arrange
+		var observed []observation
+		rp := repository.NewProductObserved(repository.NewProductMap(nil, 0), func(operation string, duration time.Duration, err error) {
+			require.GreaterOrEqual(t, duration, time.Duration(0))
+			observed = append(observed, observation{operation: operation, err: err})
+		})
+		product := internal.Product{Name: "product", CodeValue: "code"}
+
+		// act
+		require.NoError(t, rp.Save(&product))
+		_, err := rp.GetById(product.ID + 1)
+		_, _, _ = rp.Find(internal.ProductQuery{})
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrProductNotFound)
+		require.Len(t, observed, 3)
+		require.Equal(t, observation{operation: "save"}, observed[0])
+		require.Equal(t, "get_by_id", observed[1].operation)
+		require.ErrorIs(t, observed[1].err, internal.ErrProductNotFound)
+		require.Equal(t, observation{operation: "find"}, observed[2])
+	})
+}
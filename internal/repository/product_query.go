@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"app/internal"
+	"cmp"
+	"sort"
+	"strings"
+)
+
+// findProducts filters, sorts and paginates products following the query
+func findProducts(products []internal.Product, query internal.ProductQuery) ([]internal.Product, int) {
+	result := make([]internal.Product, 0, len(products))
+	for _, product := range products {
+		if matchProduct(product, query) {
+			result = append(result, product)
+		}
+	}
+
+	sortProducts(result, query.Sort)
+
+	total := len(result)
+
+	// pagination
+	if query.Offset >= total {
+		return []internal.Product{}, total
+	}
+	result = result[query.Offset:]
+	if query.Limit > 0 && query.Limit < len(result) {
+		result = result[:query.Limit]
+	}
+
+	return result, total
+}
+
+// matchProduct reports whether the product satisfies every filter of the query
+func matchProduct(p internal.Product, q internal.ProductQuery) bool {
+	if p.IsDeleted() != q.Deleted {
+		return false
+	}
+	if q.AfterID > 0 && p.ID <= q.AfterID {
+		return false
+	}
+	if q.Name != "" && !strings.Contains(strings.ToLower(p.Name), strings.ToLower(q.Name)) {
+		return false
+	}
+	if q.IsPublished != nil && p.IsPublished != *q.IsPublished {
+		return false
+	}
+	if q.PriceMin != nil && (p.Price.Currency != q.PriceMin.Currency || p.Price.Amount < q.PriceMin.Amount) {
+		return false
+	}
+	if q.PriceMax != nil && (p.Price.Currency != q.PriceMax.Currency || p.Price.Amount > q.PriceMax.Amount) {
+		return false
+	}
+	if q.QuantityMin != nil && p.Quantity < *q.QuantityMin {
+		return false
+	}
+	if q.QuantityMax != nil && p.Quantity > *q.QuantityMax {
+		return false
+	}
+
+	if q.ExpirationBefore != nil && !p.Expiration.Before(*q.ExpirationBefore) {
+		return false
+	}
+	if q.ExpirationAfter != nil && !p.Expiration.After(*q.ExpirationAfter) {
+		return false
+	}
+
+	return true
+}
+
+// sortProducts orders the products by the sort keys, falling back to the id
+func sortProducts(products []internal.Product, keys []internal.ProductSort) {
+	sort.SliceStable(products, func(i, j int) bool {
+		for _, key := range keys {
+			c := compareProducts(products[i], products[j], key.Field)
+			if c == 0 {
+				continue
+			}
+			if key.Desc {
+				return c > 0
+			}
+			return c < 0
+		}
+		return products[i].ID < products[j].ID
+	})
+}
+
+// compareProducts returns -1, 0 or 1 comparing a and b by the given field
+func compareProducts(a, b internal.Product, field string) int {
+	switch field {
+	case internal.ProductSortID:
+		return cmp.Compare(a.ID, b.ID)
+	case internal.ProductSortName:
+		return cmp.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	case internal.ProductSortQuantity:
+		return cmp.Compare(a.Quantity, b.Quantity)
+	case internal.ProductSortCodeValue:
+		return cmp.Compare(a.CodeValue, b.CodeValue)
+	case internal.ProductSortExpiration:
+		return a.Expiration.Compare(b.Expiration)
+	case internal.ProductSortPrice:
+		return a.Price.Compare(b.Price)
+	}
+	return 0
+}
@@ -0,0 +1,105 @@
+package repository_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// queryCatalog returns products differing by every field a query filters or sorts on, the fourth one being deleted
+func queryCatalog() []internal.Product {
+	return []internal.Product{
+		{ID: 1, Name: "Apple juice", Quantity: 10, CodeValue: "c", IsPublished: true,
+			Expiration: internal.NewDate(2030, time.January, 10), Price: internal.Money{Amount: 250, Currency: "USD"}},
+		{ID: 2, Name: "banana", Quantity: 5, CodeValue: "a", IsPublished: false,
+			Expiration: internal.NewDate(2030, time.March, 1), Price: internal.Money{Amount: 100, Currency: "USD"}},
+		{ID: 3, Name: "Cherry JUICE", Quantity: 20, CodeValue: "b", IsPublished: true,
+			Expiration: internal.NewDate(2030, time.February, 1), Price: internal.Money{Amount: 900, Currency: "USD"}},
+		{ID: 4, Name: "deleted juice", Quantity: 1, CodeValue: "d", IsPublished: true,
+			Expiration: internal.NewDate(2030, time.January, 1), Price: internal.Money{Amount: 50, Currency: "USD"},
+			DeletedAt: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: 5, Name: "euro", Quantity: 5, CodeValue: "e", IsPublished: true,
+			Expiration: internal.NewDate(2030, time.April, 1), Price: internal.Money{Amount: 300, Currency: "EUR"}},
+	}
+}
+
+// newQueryCatalog returns a ProductMap holding the products of queryCatalog
+func newQueryCatalog() *repository.ProductMap {
+	db := make(map[int]internal.Product)
+	for _, product := range queryCatalog() {
+		db[product.ID] = product
+	}
+	return repository.NewProductMap(db, len(db))
+}
+
+// ids returns the ids of the products, in order
+func ids(products []internal.Product) []int {
+	result := make([]int, 0, len(products))
+	for _, product := range products {
+		result = append(result, product.ID)
+	}
+	return result
+}
+
+// findCase is a query run against the products of queryCatalog, with the ids and the total it must return
+type findCase struct {
+	name     string
+	query    internal.ProductQuery
+	expected []int
+	total    int
+}
+
+// findCases are the queries every repository must answer alike
+func findCases() []findCase {
+	boolPtr := func(b bool) *bool { return &b }
+	intPtr := func(n int) *int { return &n }
+	datePtr := func(d internal.Date) *internal.Date { return &d }
+	usd := func(amount int64) *internal.Money { return &internal.Money{Amount: amount, Currency: "USD"} }
+
+	return []findCase{
+		{name: "no filters - active products by id", query: internal.ProductQuery{}, expected: []int{1, 2, 3, 5}, total: 4},
+		{name: "name - case insensitive substring", query: internal.ProductQuery{Name: "juice"}, expected: []int{1, 3}, total: 2},
+		{name: "is published", query: internal.ProductQuery{IsPublished: boolPtr(false)}, expected: []int{2}, total: 1},
+		{name: "price min - inclusive, other currencies excluded", query: internal.ProductQuery{PriceMin: usd(250)}, expected: []int{1, 3}, total: 2},
+		{name: "price max - inclusive, other currencies excluded", query: internal.ProductQuery{PriceMax: usd(250)}, expected: []int{1, 2}, total: 2},
+		{name: "quantity range - inclusive", query: internal.ProductQuery{QuantityMin: intPtr(5), QuantityMax: intPtr(10)}, expected: []int{1, 2, 5}, total: 3},
+		{name: "expiration before - exclusive", query: internal.ProductQuery{ExpirationBefore: datePtr(internal.NewDate(2030, time.February, 1))}, expected: []int{1}, total: 1},
+		{name: "expiration after - exclusive", query: internal.ProductQuery{ExpirationAfter: datePtr(internal.NewDate(2030, time.February, 1))}, expected: []int{2, 5}, total: 2},
+		{name: "deleted - only the trash", query: internal.ProductQuery{Deleted: true}, expected: []int{4}, total: 1},
+		{name: "sort by price - currency first", query: internal.ProductQuery{Sort: []internal.ProductSort{{Field: internal.ProductSortPrice}}}, expected: []int{5, 2, 1, 3}, total: 4},
+		{name: "sort by price descending - currency first", query: internal.ProductQuery{Sort: []internal.ProductSort{{Field: internal.ProductSortPrice, Desc: true}}}, expected: []int{3, 1, 2, 5}, total: 4},
+		{name: "sort ascending", query: internal.ProductQuery{Sort: []internal.ProductSort{{Field: internal.ProductSortQuantity}}}, expected: []int{2, 5, 1, 3}, total: 4},
+		{name: "sort descending", query: internal.ProductQuery{Sort: []internal.ProductSort{{Field: internal.ProductSortName, Desc: true}}}, expected: []int{5, 3, 2, 1}, total: 4},
+		{name: "sort by code value", query: internal.ProductQuery{Sort: []internal.ProductSort{{Field: internal.ProductSortCodeValue}}}, expected: []int{2, 3, 1, 5}, total: 4},
+		{name: "sort by expiration", query: internal.ProductQuery{Sort: []internal.ProductSort{{Field: internal.ProductSortExpiration}}}, expected: []int{1, 3, 2, 5}, total: 4},
+		{name: "sort ties fall back to the id", query: internal.ProductQuery{Sort: []internal.ProductSort{{Field: internal.ProductSortQuantity, Desc: true}}}, expected: []int{3, 1, 2, 5}, total: 4},
+		{name: "sort keys applied in order", query: internal.ProductQuery{Sort: []internal.ProductSort{{Field: internal.ProductSortQuantity}, {Field: internal.ProductSortID, Desc: true}}}, expected: []int{5, 2, 1, 3}, total: 4},
+		{name: "limit", query: internal.ProductQuery{Limit: 2}, expected: []int{1, 2}, total: 4},
+		{name: "offset", query: internal.ProductQuery{Offset: 1, Limit: 2}, expected: []int{2, 3}, total: 4},
+		{name: "offset past the last product", query: internal.ProductQuery{Offset: 4}, expected: []int{}, total: 4},
+		{name: "after id - exclusive", query: internal.ProductQuery{AfterID: 2, Limit: 1}, expected: []int{3}, total: 2},
+		{name: "after id past the last product", query: internal.ProductQuery{AfterID: 5}, expected: []int{}, total: 0},
+	}
+}
+
+// Tests for ProductMap.Find
+func TestProductMap_Find(t *testing.T) {
+	for _, c := range findCases() {
+		c := c
+		t.Run("success - "+c.name, func(t *testing.T) {
+			// arrange
+			rp := newQueryCatalog()
+
+			// act
+			products, total, err := rp.Find(c.query)
+
+			// assert
+			require.NoError(t, err)
+			require.Equal(t, c.expected, ids(products))
+			require.Equal(t, c.total, total)
+		})
+	}
+}
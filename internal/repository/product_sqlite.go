@@ -0,0 +1,397 @@
+package repository
+
+import (
+	"app/internal"
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// ProductSQLite is a product repository backed by a sqlite database
+type ProductSQLite struct {
+	db *sql.DB
+}
+
+// NewProductSQLite opens the sqlite database at dsn and migrates its schema
+func NewProductSQLite(dsn string) (*ProductSQLite, error) {
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	// sqlite serializes writers, a single connection avoids "database is locked" errors
+	db.SetMaxOpenConns(1)
+
+	if err := Migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &ProductSQLite{
+		db: db,
+	}, nil
+}
+
+// Close closes the database
+func (ps *ProductSQLite) Close() error {
+	return ps.db.Close()
+}
+
+// Ping checks the database is reachable
+func (ps *ProductSQLite) Ping(ctx context.Context) error {
+	return ps.db.PingContext(ctx)
+}
+
+// DB returns the migrated database, shared with the stores kept next to the products (e.g. AuditSQLite)
+func (ps *ProductSQLite) DB() *sql.DB {
+	return ps.db
+}
+
+func (ps *ProductSQLite) Save(product *internal.Product) error {
+	result, err := ps.db.Exec(
+		"INSERT INTO products (name, quantity, code_value, is_published, expiration, price_amount, price_currency) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		product.Name, product.Quantity, product.CodeValue, product.IsPublished, product.Expiration.String(), product.Price.Amount, product.Price.Currency,
+	)
+	if err != nil {
+		return mapSQLiteError(err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	product.ID = int(id)
+	product.Version = 1
+
+	return nil
+}
+
+func (ps *ProductSQLite) SaveAll(products []*internal.Product) error {
+	tx, err := ps.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	statement, err := tx.Prepare("INSERT INTO products (name, quantity, code_value, is_published, expiration, price_amount, price_currency) VALUES (?, ?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		return err
+	}
+	defer statement.Close()
+
+	ids := make([]int, len(products))
+	for i, product := range products {
+		result, err := statement.Exec(product.Name, product.Quantity, product.CodeValue, product.IsPublished, product.Expiration.String(), product.Price.Amount, product.Price.Currency)
+		if err != nil {
+			return &internal.BatchItemError{Index: i, Err: mapSQLiteError(err)}
+		}
+
+		id, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		ids[i] = int(id)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	// ids are only assigned once the batch is committed
+	for i, product := range products {
+		product.ID = ids[i]
+		product.Version = 1
+	}
+
+	return nil
+}
+
+func (ps *ProductSQLite) GetById(id int) (internal.Product, error) {
+	row := ps.db.QueryRow(
+		"SELECT "+productSQLiteColumns+" FROM products WHERE id = ? AND deleted_at IS NULL",
+		id,
+	)
+
+	product, err := scanProduct(row)
+	if err != nil {
+		return internal.Product{}, mapSQLiteError(err)
+	}
+
+	return product, nil
+}
+
+func (ps *ProductSQLite) GetByCode(code string) (internal.Product, error) {
+	row := ps.db.QueryRow(
+		"SELECT "+productSQLiteColumns+" FROM products WHERE code_value = ? AND deleted_at IS NULL",
+		code,
+	)
+
+	product, err := scanProduct(row)
+	if err != nil {
+		return internal.Product{}, mapSQLiteError(err)
+	}
+
+	return product, nil
+}
+
+func (ps *ProductSQLite) Update(product *internal.Product) error {
+	var version int
+	err := ps.db.QueryRow(
+		"UPDATE products SET name = ?, quantity = ?, code_value = ?, is_published = ?, expiration = ?, price_amount = ?, price_currency = ?, version = version + 1 "+
+			"WHERE id = ? AND deleted_at IS NULL AND (? = 0 OR version = ?) RETURNING version",
+		product.Name, product.Quantity, product.CodeValue, product.IsPublished, product.Expiration.String(), product.Price.Amount, product.Price.Currency,
+		product.ID, product.Version, product.Version,
+	).Scan(&version)
+
+	// no row was updated: either the product does not exist or its version changed
+	if errors.Is(err, sql.ErrNoRows) {
+		if _, err := ps.GetById(product.ID); err != nil {
+			return err
+		}
+		return internal.ErrProductVersionConflict
+	}
+	if err != nil {
+		return mapSQLiteError(err)
+	}
+
+	product.Version = version
+
+	return nil
+}
+
+func (ps *ProductSQLite) Delete(id int) error {
+	result, err := ps.db.Exec(
+		"UPDATE products SET deleted_at = ?, version = version + 1 WHERE id = ? AND deleted_at IS NULL",
+		time.Now().UTC().Format(time.RFC3339Nano), id,
+	)
+	if err != nil {
+		return mapSQLiteError(err)
+	}
+
+	return checkAffected(result)
+}
+
+func (ps *ProductSQLite) Restore(id int) error {
+	// the unique index only covers active products, restoring a taken code violates it
+	result, err := ps.db.Exec("UPDATE products SET deleted_at = NULL, version = version + 1 WHERE id = ? AND deleted_at IS NOT NULL", id)
+	if err != nil {
+		return mapSQLiteError(err)
+	}
+
+	return checkAffected(result)
+}
+
+func (ps *ProductSQLite) Purge(id int) error {
+	result, err := ps.db.Exec("DELETE FROM products WHERE id = ?", id)
+	if err != nil {
+		return mapSQLiteError(err)
+	}
+
+	return checkAffected(result)
+}
+
+func (ps *ProductSQLite) AdjustStock(id int, delta int) (internal.Product, error) {
+	row := ps.db.QueryRow(
+		"UPDATE products SET quantity = quantity + ?, version = version + 1 "+
+			"WHERE id = ? AND deleted_at IS NULL AND quantity + ? >= 0 RETURNING "+productSQLiteColumns,
+		delta, id, delta,
+	)
+
+	product, err := scanProduct(row)
+
+	// no row was updated: either the product does not exist or the quantity would be negative
+	if errors.Is(err, sql.ErrNoRows) {
+		if _, err := ps.GetById(id); err != nil {
+			return internal.Product{}, err
+		}
+		return internal.Product{}, internal.ErrStockInsufficient
+	}
+	if err != nil {
+		return internal.Product{}, mapSQLiteError(err)
+	}
+
+	return product, nil
+}
+
+func (ps *ProductSQLite) Find(query internal.ProductQuery) ([]internal.Product, int, error) {
+	where, args := productSQLiteWhere(query)
+
+	// total
+	var total int
+	if err := ps.db.QueryRow("SELECT COUNT(*) FROM products"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	// page
+	statement := "SELECT " + productSQLiteColumns + " FROM products" + where + productSQLiteOrderBy(query.Sort)
+	if query.Limit > 0 {
+		statement += " LIMIT ? OFFSET ?"
+		args = append(args, query.Limit, query.Offset)
+	} else if query.Offset > 0 {
+		statement += " LIMIT -1 OFFSET ?"
+		args = append(args, query.Offset)
+	}
+
+	rows, err := ps.db.Query(statement, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	products := make([]internal.Product, 0)
+	for rows.Next() {
+		product, err := scanProduct(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		products = append(products, product)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return products, total, nil
+}
+
+// productSQLiteColumns are the columns scanned by scanProduct
+const productSQLiteColumns = "id, name, quantity, code_value, is_published, expiration, price_amount, price_currency, version, deleted_at"
+
+// productSQLiteSortColumns maps the query sort fields to their columns.
+// Prices are ordered by currency first, as internal.Money.Compare does.
+var productSQLiteSortColumns = map[string][]string{
+	internal.ProductSortID:         {"id"},
+	internal.ProductSortName:       {"name COLLATE NOCASE"},
+	internal.ProductSortQuantity:   {"quantity"},
+	internal.ProductSortCodeValue:  {"code_value"},
+	internal.ProductSortExpiration: {"expiration"},
+	internal.ProductSortPrice:      {"price_currency", "price_amount"},
+}
+
+// productSQLiteWhere builds the where clause and its arguments from the query filters
+func productSQLiteWhere(q internal.ProductQuery) (string, []any) {
+	conditions := []string{"deleted_at IS NULL"}
+	if q.Deleted {
+		conditions[0] = "deleted_at IS NOT NULL"
+	}
+	var args []any
+
+	if q.AfterID > 0 {
+		conditions = append(conditions, "id > ?")
+		args = append(args, q.AfterID)
+	}
+	if q.Name != "" {
+		conditions = append(conditions, "instr(lower(name), lower(?)) > 0")
+		args = append(args, q.Name)
+	}
+	if q.IsPublished != nil {
+		conditions = append(conditions, "is_published = ?")
+		args = append(args, *q.IsPublished)
+	}
+	if q.PriceMin != nil {
+		conditions = append(conditions, "price_currency = ? AND price_amount >= ?")
+		args = append(args, q.PriceMin.Currency, q.PriceMin.Amount)
+	}
+	if q.PriceMax != nil {
+		conditions = append(conditions, "price_currency = ? AND price_amount <= ?")
+		args = append(args, q.PriceMax.Currency, q.PriceMax.Amount)
+	}
+	if q.QuantityMin != nil {
+		conditions = append(conditions, "quantity >= ?")
+		args = append(args, *q.QuantityMin)
+	}
+	if q.QuantityMax != nil {
+		conditions = append(conditions, "quantity <= ?")
+		args = append(args, *q.QuantityMax)
+	}
+	// expiration is stored as yyyy-mm-dd, so text comparison follows the dates
+	if q.ExpirationBefore != nil {
+		conditions = append(conditions, "expiration < ?")
+		args = append(args, q.ExpirationBefore.String())
+	}
+	if q.ExpirationAfter != nil {
+		conditions = append(conditions, "expiration > ?")
+		args = append(args, q.ExpirationAfter.String())
+	}
+
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// productSQLiteOrderBy builds the order by clause from the sort keys, falling back to the id
+func productSQLiteOrderBy(keys []internal.ProductSort) string {
+	var terms []string
+	for _, key := range keys {
+		columns, ok := productSQLiteSortColumns[key.Field]
+		if !ok {
+			continue
+		}
+		for _, column := range columns {
+			if key.Desc {
+				column += " DESC"
+			}
+			terms = append(terms, column)
+		}
+	}
+	terms = append(terms, "id")
+
+	return " ORDER BY " + strings.Join(terms, ", ")
+}
+
+// scanner is implemented by *sql.Row and *sql.Rows
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanProduct(s scanner) (product internal.Product, err error) {
+	var expiration string
+	var deletedAt sql.NullString
+	err = s.Scan(
+		&product.ID, &product.Name, &product.Quantity, &product.CodeValue,
+		&product.IsPublished, &expiration, &product.Price.Amount, &product.Price.Currency, &product.Version, &deletedAt,
+	)
+	if err != nil {
+		return
+	}
+
+	if deletedAt.Valid {
+		if product.DeletedAt, err = time.Parse(time.RFC3339Nano, deletedAt.String); err != nil {
+			return
+		}
+	}
+
+	// the zero date is stored as an empty string
+	if expiration != "" {
+		product.Expiration, err = internal.ParseDate(expiration)
+	}
+	return
+}
+
+// checkAffected returns internal.ErrProductNotFound when the statement did not affect any row
+func checkAffected(result sql.Result) error {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return internal.ErrProductNotFound
+	}
+	return nil
+}
+
+// mapSQLiteError translates database errors into the repository errors
+func mapSQLiteError(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return internal.ErrProductNotFound
+	}
+
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+		return internal.ErrProductCodeAlreadyExists
+	}
+
+	return err
+}
@@ -0,0 +1,251 @@
+package repository_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// expiration is the expiration of the products saved by the tests
+var expiration = internal.NewDate(2030, time.January, 1)
+
+// newProductSQLite returns a repository over a new database in a temporary directory
+func newProductSQLite(t *testing.T) *repository.ProductSQLite {
+	t.Helper()
+
+	rp, err := repository.NewProductSQLite(filepath.Join(t.TempDir(), "products.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { rp.Close() })
+	return rp
+}
+
+// Tests for ProductSQLite
+func TestProductSQLite(t *testing.T) {
+	t.Run("success - save and get", func(t *testing.T) {
+		// arrange
+		rp := newProductSQLite(t)
+		product := internal.Product{Name: "product", Quantity: 3, CodeValue: "code", IsPublished: true,
+			Expiration: internal.NewDate(2030, time.May, 4), Price: internal.Money{Amount: 1250, Currency: "EUR"}}
+
+		// act
+		err := rp.Save(&product)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, 1, product.ID)
+		require.Equal(t, 1, product.Version)
+		byId, err := rp.GetById(product.ID)
+		require.NoError(t, err)
+		require.Equal(t, product, byId)
+		byCode, err := rp.GetByCode("code")
+		require.NoError(t, err)
+		require.Equal(t, product, byCode)
+	})
+
+	t.Run("success - save all in a single transaction", func(t *testing.T) {
+		// arrange
+		rp := newProductSQLite(t)
+		products := []*internal.Product{{Name: "first", CodeValue: "first", Expiration: expiration}, {Name: "second", CodeValue: "second", Expiration: expiration}}
+
+		// act
+		err := rp.SaveAll(products)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, 1, products[0].ID)
+		require.Equal(t, 2, products[1].ID)
+	})
+
+	t.Run("success - a product without expiration is read back", func(t *testing.T) {
+		// arrange
+		rp := newProductSQLite(t)
+		product := internal.Product{Name: "product", CodeValue: "code"}
+		require.NoError(t, rp.Save(&product))
+
+		// act
+		found, err := rp.GetById(product.ID)
+
+		// assert
+		require.NoError(t, err)
+		require.True(t, found.Expiration.IsZero())
+	})
+
+	t.Run("success - update increments the version", func(t *testing.T) {
+		// arrange
+		rp := newProductSQLite(t)
+		product := internal.Product{Name: "product", CodeValue: "code", Expiration: expiration}
+		require.NoError(t, rp.Save(&product))
+		product.Name = "renamed"
+
+		// act
+		err := rp.Update(&product)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, 2, product.Version)
+		found, err := rp.GetById(product.ID)
+		require.NoError(t, err)
+		require.Equal(t, "renamed", found.Name)
+	})
+
+	t.Run("success - delete, restore and purge", func(t *testing.T) {
+		// arrange
+		rp := newProductSQLite(t)
+		product := internal.Product{Name: "product", CodeValue: "code", Expiration: expiration}
+		require.NoError(t, rp.Save(&product))
+
+		// act
+		errDelete := rp.Delete(product.ID)
+		_, errDeleted := rp.GetById(product.ID)
+		trash, _, _ := rp.Find(internal.ProductQuery{Deleted: true})
+		errRestore := rp.Restore(product.ID)
+		restored, _ := rp.GetById(product.ID)
+		errPurge := rp.Purge(product.ID)
+		_, errGet := rp.GetById(product.ID)
+
+		// assert
+		require.NoError(t, errDelete)
+		require.ErrorIs(t, errDeleted, internal.ErrProductNotFound)
+		require.Len(t, trash, 1)
+		require.True(t, trash[0].IsDeleted())
+		require.NoError(t, errRestore)
+		require.False(t, restored.IsDeleted())
+		require.Equal(t, 3, restored.Version)
+		require.NoError(t, errPurge)
+		require.ErrorIs(t, errGet, internal.ErrProductNotFound)
+	})
+
+	t.Run("success - a deleted product frees its code", func(t *testing.T) {
+		// arrange
+		rp := newProductSQLite(t)
+		deleted := internal.Product{Name: "deleted", CodeValue: "code", Expiration: expiration}
+		require.NoError(t, rp.Save(&deleted))
+		require.NoError(t, rp.Delete(deleted.ID))
+
+		// act
+		product := internal.Product{Name: "product", CodeValue: "code", Expiration: expiration}
+		errSave := rp.Save(&product)
+		errRestore := rp.Restore(deleted.ID)
+
+		// assert
+		require.NoError(t, errSave)
+		require.ErrorIs(t, errRestore, internal.ErrProductCodeAlreadyExists)
+	})
+
+	t.Run("success - adjust stock", func(t *testing.T) {
+		// arrange
+		rp := newProductSQLite(t)
+		product := internal.Product{Name: "product", CodeValue: "code", Quantity: 2, Expiration: expiration}
+		require.NoError(t, rp.Save(&product))
+
+		// act
+		adjusted, err := rp.AdjustStock(product.ID, -2)
+		_, errInsufficient := rp.AdjustStock(product.ID, -1)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, 0, adjusted.Quantity)
+		require.Equal(t, 2, adjusted.Version)
+		require.ErrorIs(t, errInsufficient, internal.ErrStockInsufficient)
+	})
+
+	t.Run("error - code already exists", func(t *testing.T) {
+		// arrange
+		rp := newProductSQLite(t)
+		first := internal.Product{Name: "first", CodeValue: "first", Expiration: expiration}
+		second := internal.Product{Name: "second", CodeValue: "second", Expiration: expiration}
+		require.NoError(t, rp.Save(&first))
+		require.NoError(t, rp.Save(&second))
+
+		// act
+		duplicate := internal.Product{Name: "duplicate", CodeValue: "first", Expiration: expiration}
+		errSave := rp.Save(&duplicate)
+		second.CodeValue = "first"
+		errUpdate := rp.Update(&second)
+
+		// assert
+		require.ErrorIs(t, errSave, internal.ErrProductCodeAlreadyExists)
+		require.ErrorIs(t, errUpdate, internal.ErrProductCodeAlreadyExists)
+	})
+
+	t.Run("error - a batch with a taken code saves nothing", func(t *testing.T) {
+		// arrange
+		rp := newProductSQLite(t)
+		products := []*internal.Product{{Name: "first", CodeValue: "code", Expiration: expiration}, {Name: "second", CodeValue: "code", Expiration: expiration}}
+
+		// act
+		err := rp.SaveAll(products)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrProductCodeAlreadyExists)
+		_, total, err := rp.Find(internal.ProductQuery{})
+		require.NoError(t, err)
+		require.Equal(t, 0, total)
+	})
+
+	t.Run("error - update of a stale version", func(t *testing.T) {
+		// arrange
+		rp := newProductSQLite(t)
+		product := internal.Product{Name: "product", CodeValue: "code", Expiration: expiration}
+		require.NoError(t, rp.Save(&product))
+		stale := product
+		require.NoError(t, rp.Update(&product))
+
+		// act
+		err := rp.Update(&stale)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrProductVersionConflict)
+	})
+
+	t.Run("error - product not found", func(t *testing.T) {
+		// arrange
+		rp := newProductSQLite(t)
+
+		// act
+		_, errGet := rp.GetById(1)
+		_, errCode := rp.GetByCode("code")
+		errUpdate := rp.Update(&internal.Product{ID: 1, Name: "product", CodeValue: "code", Expiration: expiration})
+		errDelete := rp.Delete(1)
+		errPurge := rp.Purge(1)
+		_, errAdjust := rp.AdjustStock(1, 1)
+
+		// assert
+		require.ErrorIs(t, errGet, internal.ErrProductNotFound)
+		require.ErrorIs(t, errCode, internal.ErrProductNotFound)
+		require.ErrorIs(t, errUpdate, internal.ErrProductNotFound)
+		require.ErrorIs(t, errDelete, internal.ErrProductNotFound)
+		require.ErrorIs(t, errPurge, internal.ErrProductNotFound)
+		require.ErrorIs(t, errAdjust, internal.ErrProductNotFound)
+	})
+}
+
+// Tests for ProductSQLite.Find, which must answer the queries as ProductMap does
+func TestProductSQLite_Find(t *testing.T) {
+	for _, c := range findCases() {
+		c := c
+		t.Run("success - "+c.name, func(t *testing.T) {
+			// arrange
+			rp := newProductSQLite(t)
+			for _, product := range queryCatalog() {
+				product := product
+				require.NoError(t, rp.Save(&product))
+				if product.IsDeleted() {
+					require.NoError(t, rp.Delete(product.ID))
+				}
+			}
+
+			// act
+			products, total, err := rp.Find(c.query)
+
+			// assert
+			require.NoError(t, err)
+			require.Equal(t, c.expected, ids(products))
+			require.Equal(t, c.total, total)
+		})
+	}
+}
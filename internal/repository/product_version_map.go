@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"app/internal"
+	"sync"
+)
+
+// ProductVersionMap is an in-memory product version store
+type ProductVersionMap struct {
+	mu sync.RWMutex
+	// versions holds the versions of each product, oldest first
+	versions map[int][]internal.Product
+}
+
+func NewProductVersionMap() *ProductVersionMap {
+	return &ProductVersionMap{
+		versions: make(map[int][]internal.Product),
+	}
+}
+
+func (vm *ProductVersionMap) Record(product internal.Product) error {
+	vm.mu.Lock()
+	defer vm.mu.Unlock()
+
+	vm.versions[product.ID] = append(vm.versions[product.ID], product)
+
+	return nil
+}
+
+func (vm *ProductVersionMap) GetVersion(id int, version int) (internal.Product, error) {
+	vm.mu.RLock()
+	defer vm.mu.RUnlock()
+
+	for _, product := range vm.versions[id] {
+		if product.Version == version {
+			return product, nil
+		}
+	}
+
+	return internal.Product{}, internal.ErrProductVersionNotFound
+}
+
+func (vm *ProductVersionMap) Drop(id int) error {
+	vm.mu.Lock()
+	defer vm.mu.Unlock()
+
+	delete(vm.versions, id)
+
+	return nil
+}
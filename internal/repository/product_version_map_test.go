@@ -0,0 +1,47 @@
+package repository_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for ProductVersionMap
+func TestProductVersionMap(t *testing.T) {
+	t.Run("success - every recorded version is returned", func(t *testing.T) {
+		// arrange
+		vm := repository.NewProductVersionMap()
+		require.NoError(t, vm.Record(internal.Product{ID: 1, Name: "v1", Version: 1}))
+		require.NoError(t, vm.Record(internal.Product{ID: 2, Name: "other", Version: 1}))
+		require.NoError(t, vm.Record(internal.Product{ID: 1, Name: "v2", Version: 2}))
+
+		// act
+		first, errFirst := vm.GetVersion(1, 1)
+		second, errSecond := vm.GetVersion(1, 2)
+
+		// assert
+		require.NoError(t, errFirst)
+		require.Equal(t, "v1", first.Name)
+		require.NoError(t, errSecond)
+		require.Equal(t, "v2", second.Name)
+	})
+
+	t.Run("error - the versions of a dropped product are not found", func(t *testing.T) {
+		// arrange
+		vm := repository.NewProductVersionMap()
+		require.NoError(t, vm.Record(internal.Product{ID: 1, Name: "v1", Version: 1}))
+		require.NoError(t, vm.Record(internal.Product{ID: 2, Name: "other", Version: 1}))
+
+		// act
+		err := vm.Drop(1)
+
+		// assert
+		require.NoError(t, err)
+		_, err = vm.GetVersion(1, 1)
+		require.ErrorIs(t, err, internal.ErrProductVersionNotFound)
+		_, err = vm.GetVersion(2, 1)
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"app/internal"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// ProductVersionSQLite is a product version store backed by the product_versions table of a sqlite database
+type ProductVersionSQLite struct {
+	db *sql.DB
+}
+
+// NewProductVersionSQLite returns a product version store over db, which must be migrated (see Migrate)
+func NewProductVersionSQLite(db *sql.DB) *ProductVersionSQLite {
+	return &ProductVersionSQLite{
+		db: db,
+	}
+}
+
+func (vs *ProductVersionSQLite) Record(product internal.Product) error {
+	var deletedAt sql.NullString
+	if product.IsDeleted() {
+		deletedAt = sql.NullString{String: product.DeletedAt.UTC().Format(time.RFC3339Nano), Valid: true}
+	}
+
+	_, err := vs.db.Exec(
+		"INSERT INTO product_versions ("+productSQLiteColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		product.ID, product.Name, product.Quantity, product.CodeValue, product.IsPublished, product.Expiration.String(),
+		product.Price.Amount, product.Price.Currency, product.Version, deletedAt,
+	)
+
+	return err
+}
+
+func (vs *ProductVersionSQLite) GetVersion(id int, version int) (internal.Product, error) {
+	row := vs.db.QueryRow(
+		"SELECT "+productSQLiteColumns+" FROM product_versions WHERE id = ? AND version = ?",
+		id, version,
+	)
+
+	product, err := scanProduct(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return internal.Product{}, internal.ErrProductVersionNotFound
+	}
+	if err != nil {
+		return internal.Product{}, err
+	}
+
+	return product, nil
+}
+
+func (vs *ProductVersionSQLite) Drop(id int) error {
+	_, err := vs.db.Exec("DELETE FROM product_versions WHERE id = ?", id)
+
+	return err
+}
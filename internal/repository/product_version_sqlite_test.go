@@ -0,0 +1,62 @@
+package repository_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newProductVersionSQLite returns a product version store over a new migrated database
+func newProductVersionSQLite(t *testing.T) *repository.ProductVersionSQLite {
+	t.Helper()
+
+	db := openDB(t)
+	require.NoError(t, repository.Migrate(db))
+	return repository.NewProductVersionSQLite(db)
+}
+
+// Tests for ProductVersionSQLite
+func TestProductVersionSQLite(t *testing.T) {
+	t.Run("success - every field of the recorded version is returned", func(t *testing.T) {
+		// arrange
+		vs := newProductVersionSQLite(t)
+		deletedAt := time.Date(2030, time.January, 1, 12, 30, 0, 500, time.UTC)
+		product := internal.Product{ID: 1, Name: "product", Quantity: 3, CodeValue: "code", IsPublished: true,
+			Expiration: internal.NewDate(2030, time.May, 4), Price: internal.Money{Amount: 1250, Currency: "EUR"}, Version: 2, DeletedAt: deletedAt}
+		require.NoError(t, vs.Record(internal.Product{ID: 1, Name: "v1", CodeValue: "code", Version: 1}))
+
+		// act
+		err := vs.Record(product)
+
+		// assert
+		require.NoError(t, err)
+		found, err := vs.GetVersion(1, 2)
+		require.NoError(t, err)
+		require.Equal(t, product, found)
+		first, err := vs.GetVersion(1, 1)
+		require.NoError(t, err)
+		require.Equal(t, "v1", first.Name)
+		require.True(t, first.Expiration.IsZero())
+		require.False(t, first.IsDeleted())
+	})
+
+	t.Run("error - the versions of a dropped product are not found", func(t *testing.T) {
+		// arrange
+		vs := newProductVersionSQLite(t)
+		require.NoError(t, vs.Record(internal.Product{ID: 1, Name: "v1", CodeValue: "code", Version: 1}))
+		require.NoError(t, vs.Record(internal.Product{ID: 2, Name: "other", CodeValue: "other", Version: 1}))
+
+		// act
+		err := vs.Drop(1)
+
+		// assert
+		require.NoError(t, err)
+		_, err = vs.GetVersion(1, 1)
+		require.ErrorIs(t, err, internal.ErrProductVersionNotFound)
+		_, err = vs.GetVersion(2, 1)
+		require.NoError(t, err)
+	})
+}
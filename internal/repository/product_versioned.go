@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"app/internal"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ProductVersioned is a product repository decorator recording in vs every version of the products
+// written through it, so they can be fetched with GetVersion
+type ProductVersioned struct {
+	rp internal.ProductRepository
+	vs internal.ProductVersionStore
+	// mu serializes the mutations, so each one is recorded with the version it wrote
+	mu sync.RWMutex
+}
+
+// NewProductVersioned returns a decorator of rp recording the versions of the products in vs
+func NewProductVersioned(rp internal.ProductRepository, vs internal.ProductVersionStore) *ProductVersioned {
+	return &ProductVersioned{
+		rp: rp,
+		vs: vs,
+	}
+}
+
+func (pv *ProductVersioned) Save(product *internal.Product) error {
+	pv.mu.Lock()
+	defer pv.mu.Unlock()
+
+	if err := pv.rp.Save(product); err != nil {
+		return err
+	}
+
+	pv.record(*product)
+
+	return nil
+}
+
+func (pv *ProductVersioned) SaveAll(products []*internal.Product) error {
+	pv.mu.Lock()
+	defer pv.mu.Unlock()
+
+	if err := pv.rp.SaveAll(products); err != nil {
+		return err
+	}
+
+	for _, product := range products {
+		pv.record(*product)
+	}
+
+	return nil
+}
+
+func (pv *ProductVersioned) GetById(id int) (internal.Product, error) {
+	return pv.rp.GetById(id)
+}
+
+func (pv *ProductVersioned) GetByCode(code string) (internal.Product, error) {
+	return pv.rp.GetByCode(code)
+}
+
+func (pv *ProductVersioned) Update(product *internal.Product) error {
+	pv.mu.Lock()
+	defer pv.mu.Unlock()
+
+	if err := pv.rp.Update(product); err != nil {
+		return err
+	}
+
+	pv.record(*product)
+
+	return nil
+}
+
+func (pv *ProductVersioned) Delete(id int) error {
+	pv.mu.Lock()
+	defer pv.mu.Unlock()
+
+	// deleted products can not be read back, so the version is derived from the one before
+	before, err := pv.rp.GetById(id)
+	if err != nil {
+		return err
+	}
+
+	if err := pv.rp.Delete(id); err != nil {
+		return err
+	}
+
+	before.Version++
+	before.DeletedAt = time.Now()
+	pv.record(before)
+
+	return nil
+}
+
+func (pv *ProductVersioned) Restore(id int) error {
+	pv.mu.Lock()
+	defer pv.mu.Unlock()
+
+	if err := pv.rp.Restore(id); err != nil {
+		return err
+	}
+
+	product, err := pv.rp.GetById(id)
+	if err != nil {
+		return err
+	}
+	pv.record(product)
+
+	return nil
+}
+
+func (pv *ProductVersioned) Purge(id int) error {
+	pv.mu.Lock()
+	defer pv.mu.Unlock()
+
+	if err := pv.rp.Purge(id); err != nil {
+		return err
+	}
+
+	// a purged product can not be reverted, its versions are dropped with it
+	if err := pv.vs.Drop(id); err != nil {
+		slog.Error("versions: dropping the versions of the purged product", "product_id", id, "error", err)
+	}
+
+	return nil
+}
+
+func (pv *ProductVersioned) AdjustStock(id int, delta int) (internal.Product, error) {
+	pv.mu.Lock()
+	defer pv.mu.Unlock()
+
+	product, err := pv.rp.AdjustStock(id, delta)
+	if err != nil {
+		return internal.Product{}, err
+	}
+
+	pv.record(product)
+
+	return product, nil
+}
+
+func (pv *ProductVersioned) Find(query internal.ProductQuery) ([]internal.Product, int, error) {
+	return pv.rp.Find(query)
+}
+
+// GetVersion returns the product at the version.
+// Products written before the decorator was in place only have their current version.
+func (pv *ProductVersioned) GetVersion(id int, version int) (internal.Product, error) {
+	pv.mu.RLock()
+	product, err := pv.vs.GetVersion(id, version)
+	pv.mu.RUnlock()
+	if !errors.Is(err, internal.ErrProductVersionNotFound) {
+		return product, err
+	}
+
+	product, err = pv.rp.GetById(id)
+	if err == nil && product.Version == version {
+		return product, nil
+	}
+	if err != nil && err != internal.ErrProductNotFound {
+		return internal.Product{}, err
+	}
+
+	return internal.Product{}, internal.ErrProductVersionNotFound
+}
+
+// record stores the product as the version it holds.
+// The mutation is already done, so a failure to record it is logged instead of returned.
+// The caller must hold the lock.
+func (pv *ProductVersioned) record(product internal.Product) {
+	if err := pv.vs.Record(product); err != nil {
+		slog.Error("versions: recording the version", "product_id", product.ID, "version", product.Version, "error", err)
+	}
+}
@@ -0,0 +1,87 @@
+package repository_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for ProductVersioned
+func TestProductVersioned_GetVersion(t *testing.T) {
+	t.Run("success - every written version is retained", func(t *testing.T) {
+		// arrange
+		rp := repository.NewProductVersioned(repository.NewProductMap(nil, 0), repository.NewProductVersionMap())
+		product := internal.Product{Name: "v1", CodeValue: "code"}
+		require.NoError(t, rp.Save(&product))
+		product.Name = "v2"
+		require.NoError(t, rp.Update(&product))
+		require.NoError(t, rp.Delete(product.ID))
+
+		// act
+		first, err1 := rp.GetVersion(product.ID, 1)
+		second, err2 := rp.GetVersion(product.ID, 2)
+		deleted, err3 := rp.GetVersion(product.ID, 3)
+
+		// assert
+		require.NoError(t, err1)
+		require.Equal(t, "v1", first.Name)
+		require.NoError(t, err2)
+		require.Equal(t, "v2", second.Name)
+		require.NoError(t, err3)
+		require.True(t, deleted.IsDeleted())
+	})
+
+	t.Run("success - products written before the decorator have their current version", func(t *testing.T) {
+		// arrange
+		db := map[int]internal.Product{1: {ID: 1, Name: "seed", CodeValue: "code", Version: 4}}
+		rp := repository.NewProductVersioned(repository.NewProductMap(db, 0), repository.NewProductVersionMap())
+
+		// act
+		product, err := rp.GetVersion(1, 4)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, "seed", product.Name)
+	})
+
+	t.Run("success - the versions stored in sqlite outlive the decorator", func(t *testing.T) {
+		// arrange
+		ps := newProductSQLite(t)
+		rp := repository.NewProductVersioned(ps, repository.NewProductVersionSQLite(ps.DB()))
+		product := internal.Product{Name: "v1", CodeValue: "code", Expiration: expiration}
+		require.NoError(t, rp.Save(&product))
+		for i := 2; i <= 60; i++ {
+			product.Name = fmt.Sprintf("v%d", i)
+			require.NoError(t, rp.Update(&product))
+		}
+
+		// act
+		reopened := repository.NewProductVersioned(ps, repository.NewProductVersionSQLite(ps.DB()))
+		first, errFirst := reopened.GetVersion(product.ID, 1)
+		last, errLast := reopened.GetVersion(product.ID, 60)
+
+		// assert
+		require.NoError(t, errFirst)
+		require.Equal(t, "v1", first.Name)
+		require.Equal(t, expiration, first.Expiration)
+		require.NoError(t, errLast)
+		require.Equal(t, "v60", last.Name)
+	})
+
+	t.Run("error - version is not retained", func(t *testing.T) {
+		// arrange
+		rp := repository.NewProductVersioned(repository.NewProductMap(nil, 0), repository.NewProductVersionMap())
+		product := internal.Product{Name: "v1", CodeValue: "code"}
+		require.NoError(t, rp.Save(&product))
+		require.NoError(t, rp.Purge(product.ID))
+
+		// act
+		_, err := rp.GetVersion(product.ID, 1)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrProductVersionNotFound)
+	})
+}
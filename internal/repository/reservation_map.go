@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"app/internal"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ReservationMap is an in-memory reservation store
+type ReservationMap struct {
+	mu           sync.RWMutex
+	reservations map[int]internal.Reservation
+	lastId       int
+}
+
+func NewReservationMap() *ReservationMap {
+	return &ReservationMap{
+		reservations: make(map[int]internal.Reservation),
+	}
+}
+
+func (rm *ReservationMap) Save(reservation *internal.Reservation) error {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	rm.lastId++
+
+	reservation.ID = rm.lastId
+
+	rm.reservations[reservation.ID] = *reservation
+
+	return nil
+}
+
+func (rm *ReservationMap) GetById(id int) (internal.Reservation, error) {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	reservation, ok := rm.reservations[id]
+	if !ok {
+		return internal.Reservation{}, internal.ErrReservationNotFound
+	}
+
+	return reservation, nil
+}
+
+func (rm *ReservationMap) Close(id int, status internal.ReservationStatus, at time.Time) (internal.Reservation, error) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	reservation, ok := rm.reservations[id]
+	if !ok {
+		return internal.Reservation{}, internal.ErrReservationNotFound
+	}
+	if !reservation.IsActive(at) {
+		return internal.Reservation{}, internal.ErrReservationNotActive
+	}
+
+	reservation.Status = status
+	reservation.UpdatedAt = at
+	rm.reservations[id] = reservation
+
+	return reservation, nil
+}
+
+func (rm *ReservationMap) Reserved(productID int, at time.Time) (int, error) {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	reserved := 0
+	for _, reservation := range rm.reservations {
+		if reservation.ProductID == productID && reservation.IsActive(at) {
+			reserved += reservation.Quantity
+		}
+	}
+
+	return reserved, nil
+}
+
+func (rm *ReservationMap) Expire(at time.Time) ([]internal.Reservation, error) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	expired := make([]internal.Reservation, 0)
+	for id, reservation := range rm.reservations {
+		if reservation.Status != internal.ReservationActive || reservation.IsActive(at) {
+			continue
+		}
+		reservation.Status = internal.ReservationExpired
+		reservation.UpdatedAt = at
+		rm.reservations[id] = reservation
+		expired = append(expired, reservation)
+	}
+	sortReservations(expired)
+
+	return expired, nil
+}
+
+func (rm *ReservationMap) Reservations(productID int) ([]internal.Reservation, error) {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	reservations := make([]internal.Reservation, 0)
+	for _, reservation := range rm.reservations {
+		if reservation.ProductID == productID {
+			reservations = append(reservations, reservation)
+		}
+	}
+	sortReservations(reservations)
+
+	return reservations, nil
+}
+
+// sortReservations sorts the reservations by id, which is their creation order
+func sortReservations(reservations []internal.Reservation) {
+	sort.Slice(reservations, func(i, j int) bool {
+		return reservations[i].ID < reservations[j].ID
+	})
+}
@@ -0,0 +1,111 @@
+package repository_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for ReservationMap
+func TestReservationMap(t *testing.T) {
+	now := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// newReservation returns an active reservation of product 1 expiring after the ttl
+	newReservation := func(quantity int, ttl time.Duration) *internal.Reservation {
+		return &internal.Reservation{
+			ProductID: 1,
+			Quantity:  quantity,
+			Status:    internal.ReservationActive,
+			CreatedAt: now,
+			ExpiresAt: now.Add(ttl),
+		}
+	}
+
+	t.Run("reserved - only active reservations before their expiration hold stock", func(t *testing.T) {
+		// arrange
+		rs := repository.NewReservationMap()
+		require.NoError(t, rs.Save(newReservation(1, time.Minute)))
+		require.NoError(t, rs.Save(newReservation(2, time.Hour)))
+		cancelled := newReservation(4, time.Hour)
+		require.NoError(t, rs.Save(cancelled))
+		_, err := rs.Close(cancelled.ID, internal.ReservationCancelled, now)
+		require.NoError(t, err)
+
+		// act
+		reserved, err1 := rs.Reserved(1, now)
+		later, err2 := rs.Reserved(1, now.Add(time.Minute))
+
+		// assert
+		require.NoError(t, err1)
+		require.Equal(t, 3, reserved)
+		require.NoError(t, err2)
+		require.Equal(t, 2, later)
+	})
+
+	t.Run("expire - reservations past their expiration are closed", func(t *testing.T) {
+		// arrange
+		rs := repository.NewReservationMap()
+		expiring := newReservation(1, time.Minute)
+		require.NoError(t, rs.Save(expiring))
+		require.NoError(t, rs.Save(newReservation(2, time.Hour)))
+
+		// act
+		expired, err := rs.Expire(now.Add(time.Minute))
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, expired, 1)
+		require.Equal(t, expiring.ID, expired[0].ID)
+		require.Equal(t, internal.ReservationExpired, expired[0].Status)
+		_, err = rs.Close(expiring.ID, internal.ReservationConfirmed, now)
+		require.ErrorIs(t, err, internal.ErrReservationNotActive)
+	})
+
+	t.Run("close - a reservation is closed once under concurrent use", func(t *testing.T) {
+		// arrange
+		rs := repository.NewReservationMap()
+		reservation := newReservation(1, time.Hour)
+		require.NoError(t, rs.Save(reservation))
+
+		// act
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		closed := 0
+		for i := 0; i < concurrentWorkers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				status := internal.ReservationConfirmed
+				if i%2 == 0 {
+					status = internal.ReservationCancelled
+				}
+				if _, err := rs.Close(reservation.ID, status, now); err == nil {
+					mu.Lock()
+					closed++
+					mu.Unlock()
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		// assert
+		require.Equal(t, 1, closed)
+	})
+
+	t.Run("error - reservation not found", func(t *testing.T) {
+		// arrange
+		rs := repository.NewReservationMap()
+
+		// act
+		_, err1 := rs.GetById(1)
+		_, err2 := rs.Close(1, internal.ReservationCancelled, now)
+
+		// assert
+		require.ErrorIs(t, err1, internal.ErrReservationNotFound)
+		require.ErrorIs(t, err2, internal.ErrReservationNotFound)
+	})
+}
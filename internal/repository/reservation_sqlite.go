@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"app/internal"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// reservationTimeLayout is the layout times of the reservations are stored in.
+// Unlike time.RFC3339Nano it has a fixed width, so expirations compare as text.
+const reservationTimeLayout = "2006-01-02T15:04:05.000000000Z"
+
+// reservationColumns are the columns scanned by scanReservation
+const reservationColumns = "id, product_id, quantity, status, reference, actor, created_at, expires_at, updated_at"
+
+// ReservationSQLite is a reservation store backed by the reservations table of a sqlite database
+type ReservationSQLite struct {
+	db *sql.DB
+}
+
+// NewReservationSQLite returns a reservation store over db, which must be migrated (see Migrate)
+func NewReservationSQLite(db *sql.DB) *ReservationSQLite {
+	return &ReservationSQLite{
+		db: db,
+	}
+}
+
+func (rs *ReservationSQLite) Save(reservation *internal.Reservation) error {
+	result, err := rs.db.Exec(
+		"INSERT INTO reservations (product_id, quantity, status, reference, actor, created_at, expires_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		reservation.ProductID, reservation.Quantity, reservation.Status, reservation.Reference, reservation.Actor,
+		formatReservationTime(reservation.CreatedAt), formatReservationTime(reservation.ExpiresAt),
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	reservation.ID = int(id)
+
+	return nil
+}
+
+func (rs *ReservationSQLite) GetById(id int) (internal.Reservation, error) {
+	row := rs.db.QueryRow("SELECT "+reservationColumns+" FROM reservations WHERE id = ?", id)
+
+	reservation, err := scanReservation(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return internal.Reservation{}, internal.ErrReservationNotFound
+	}
+
+	return reservation, err
+}
+
+func (rs *ReservationSQLite) Close(id int, status internal.ReservationStatus, at time.Time) (internal.Reservation, error) {
+	row := rs.db.QueryRow(
+		"UPDATE reservations SET status = ?, updated_at = ? WHERE id = ? AND status = ? AND expires_at > ? RETURNING "+reservationColumns,
+		status, formatReservationTime(at), id, internal.ReservationActive, formatReservationTime(at),
+	)
+
+	reservation, err := scanReservation(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		// nothing was closed, either the reservation does not exist or it is not active
+		if _, err := rs.GetById(id); err != nil {
+			return internal.Reservation{}, err
+		}
+		return internal.Reservation{}, internal.ErrReservationNotActive
+	}
+
+	return reservation, err
+}
+
+func (rs *ReservationSQLite) Reserved(productID int, at time.Time) (int, error) {
+	var reserved int
+	err := rs.db.QueryRow(
+		"SELECT COALESCE(SUM(quantity), 0) FROM reservations WHERE product_id = ? AND status = ? AND expires_at > ?",
+		productID, internal.ReservationActive, formatReservationTime(at),
+	).Scan(&reserved)
+
+	return reserved, err
+}
+
+func (rs *ReservationSQLite) Expire(at time.Time) ([]internal.Reservation, error) {
+	rows, err := rs.db.Query(
+		"UPDATE reservations SET status = ?, updated_at = ? WHERE status = ? AND expires_at <= ? RETURNING "+reservationColumns,
+		internal.ReservationExpired, formatReservationTime(at), internal.ReservationActive, formatReservationTime(at),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	reservations, err := scanReservations(rows)
+	if err != nil {
+		return nil, err
+	}
+	// the rows returned by an update are in no particular order
+	sortReservations(reservations)
+
+	return reservations, nil
+}
+
+func (rs *ReservationSQLite) Reservations(productID int) ([]internal.Reservation, error) {
+	rows, err := rs.db.Query("SELECT "+reservationColumns+" FROM reservations WHERE product_id = ? ORDER BY id", productID)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanReservations(rows)
+}
+
+// scanReservations scans every row into a reservation, closing the rows
+func scanReservations(rows *sql.Rows) ([]internal.Reservation, error) {
+	defer rows.Close()
+
+	reservations := make([]internal.Reservation, 0)
+	for rows.Next() {
+		reservation, err := scanReservation(rows)
+		if err != nil {
+			return nil, err
+		}
+		reservations = append(reservations, reservation)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return reservations, nil
+}
+
+// scanReservation scans the reservationColumns of the row into a reservation
+func scanReservation(s scanner) (internal.Reservation, error) {
+	var reservation internal.Reservation
+	var createdAt, expiresAt string
+	var updatedAt sql.NullString
+	err := s.Scan(
+		&reservation.ID, &reservation.ProductID, &reservation.Quantity, &reservation.Status,
+		&reservation.Reference, &reservation.Actor, &createdAt, &expiresAt, &updatedAt,
+	)
+	if err != nil {
+		return internal.Reservation{}, err
+	}
+
+	if reservation.CreatedAt, err = time.Parse(reservationTimeLayout, createdAt); err != nil {
+		return internal.Reservation{}, err
+	}
+	if reservation.ExpiresAt, err = time.Parse(reservationTimeLayout, expiresAt); err != nil {
+		return internal.Reservation{}, err
+	}
+	if updatedAt.Valid {
+		if reservation.UpdatedAt, err = time.Parse(reservationTimeLayout, updatedAt.String); err != nil {
+			return internal.Reservation{}, err
+		}
+	}
+
+	return reservation, nil
+}
+
+// formatReservationTime formats the time in the reservationTimeLayout
+func formatReservationTime(t time.Time) string {
+	return t.UTC().Format(reservationTimeLayout)
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"app/internal"
+	"sync"
+)
+
+// StockLedgerMap is an in-memory stock ledger
+type StockLedgerMap struct {
+	mu sync.RWMutex
+	// movements holds the movements of each product, oldest first
+	movements map[int][]internal.StockMovement
+	lastId    int
+}
+
+func NewStockLedgerMap() *StockLedgerMap {
+	return &StockLedgerMap{
+		movements: make(map[int][]internal.StockMovement),
+	}
+}
+
+func (sl *StockLedgerMap) Record(movement *internal.StockMovement) error {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+
+	sl.lastId++
+
+	movement.ID = sl.lastId
+
+	sl.movements[movement.ProductID] = append(sl.movements[movement.ProductID], *movement)
+
+	return nil
+}
+
+func (sl *StockLedgerMap) Movements(productID int) ([]internal.StockMovement, error) {
+	sl.mu.RLock()
+	defer sl.mu.RUnlock()
+
+	movements := make([]internal.StockMovement, len(sl.movements[productID]))
+	copy(movements, sl.movements[productID])
+
+	return movements, nil
+}
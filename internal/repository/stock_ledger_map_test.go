@@ -0,0 +1,46 @@
+package repository_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for StockLedgerMap
+func TestStockLedgerMap(t *testing.T) {
+	t.Run("success - the movements of a product are returned oldest first", func(t *testing.T) {
+		// arrange
+		sl := repository.NewStockLedgerMap()
+		purchase := internal.StockMovement{ProductID: 1, Delta: 10, Quantity: 10, Reason: "purchase"}
+		other := internal.StockMovement{ProductID: 2, Delta: 1, Quantity: 1, Reason: "purchase"}
+		sale := internal.StockMovement{ProductID: 1, Delta: -3, Quantity: 7, Reason: "sale", Reference: "order-1"}
+
+		// act
+		require.NoError(t, sl.Record(&purchase))
+		require.NoError(t, sl.Record(&other))
+		require.NoError(t, sl.Record(&sale))
+		movements, err := sl.Movements(1)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, 1, purchase.ID)
+		require.Equal(t, 2, other.ID)
+		require.Equal(t, 3, sale.ID)
+		require.Equal(t, []internal.StockMovement{purchase, sale}, movements)
+	})
+
+	t.Run("success - a product without movements has an empty ledger", func(t *testing.T) {
+		// arrange
+		sl := repository.NewStockLedgerMap()
+
+		// act
+		movements, err := sl.Movements(1)
+
+		// assert
+		require.NoError(t, err)
+		require.NotNil(t, movements)
+		require.Empty(t, movements)
+	})
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"app/internal"
+	"database/sql"
+	"time"
+)
+
+// StockLedgerSQLite is a stock ledger backed by the stock_movements table of a sqlite database
+type StockLedgerSQLite struct {
+	db *sql.DB
+}
+
+// NewStockLedgerSQLite returns a stock ledger over db, which must be migrated (see Migrate)
+func NewStockLedgerSQLite(db *sql.DB) *StockLedgerSQLite {
+	return &StockLedgerSQLite{
+		db: db,
+	}
+}
+
+func (sl *StockLedgerSQLite) Record(movement *internal.StockMovement) error {
+	result, err := sl.db.Exec(
+		"INSERT INTO stock_movements (product_id, delta, quantity, reason, reference, actor, timestamp) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		movement.ProductID, movement.Delta, movement.Quantity, movement.Reason, movement.Reference, movement.Actor,
+		movement.Timestamp.UTC().Format(time.RFC3339Nano),
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	movement.ID = int(id)
+
+	return nil
+}
+
+func (sl *StockLedgerSQLite) Movements(productID int) ([]internal.StockMovement, error) {
+	rows, err := sl.db.Query(
+		"SELECT id, product_id, delta, quantity, reason, reference, actor, timestamp FROM stock_movements WHERE product_id = ? ORDER BY id",
+		productID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	movements := make([]internal.StockMovement, 0)
+	for rows.Next() {
+		var movement internal.StockMovement
+		var timestamp string
+		err := rows.Scan(
+			&movement.ID, &movement.ProductID, &movement.Delta, &movement.Quantity,
+			&movement.Reason, &movement.Reference, &movement.Actor, &timestamp,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if movement.Timestamp, err = time.Parse(time.RFC3339Nano, timestamp); err != nil {
+			return nil, err
+		}
+
+		movements = append(movements, movement)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return movements, nil
+}
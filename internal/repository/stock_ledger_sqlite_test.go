@@ -0,0 +1,56 @@
+package repository_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newStockLedgerSQLite returns a stock ledger over a new migrated database
+func newStockLedgerSQLite(t *testing.T) *repository.StockLedgerSQLite {
+	t.Helper()
+
+	db := openDB(t)
+	require.NoError(t, repository.Migrate(db))
+	return repository.NewStockLedgerSQLite(db)
+}
+
+// Tests for StockLedgerSQLite
+func TestStockLedgerSQLite(t *testing.T) {
+	t.Run("success - the movements of a product are returned oldest first", func(t *testing.T) {
+		// arrange
+		sl := newStockLedgerSQLite(t)
+		timestamp := time.Date(2030, time.January, 1, 12, 0, 0, 0, time.UTC)
+		purchase := internal.StockMovement{ProductID: 1, Delta: 10, Quantity: 10, Reason: "purchase", Actor: "alice", Timestamp: timestamp}
+		other := internal.StockMovement{ProductID: 2, Delta: 1, Quantity: 1, Reason: "purchase", Actor: "alice", Timestamp: timestamp}
+		sale := internal.StockMovement{ProductID: 1, Delta: -3, Quantity: 7, Reason: "sale", Reference: "order-1", Actor: "bob", Timestamp: timestamp.Add(time.Minute)}
+
+		// act
+		require.NoError(t, sl.Record(&purchase))
+		require.NoError(t, sl.Record(&other))
+		require.NoError(t, sl.Record(&sale))
+		movements, err := sl.Movements(1)
+
+		// assert
+		require.NoError(t, err)
+		require.Less(t, purchase.ID, other.ID)
+		require.Less(t, other.ID, sale.ID)
+		require.Equal(t, []internal.StockMovement{purchase, sale}, movements)
+	})
+
+	t.Run("success - a product without movements has an empty ledger", func(t *testing.T) {
+		// arrange
+		sl := newStockLedgerSQLite(t)
+
+		// act
+		movements, err := sl.Movements(1)
+
+		// assert
+		require.NoError(t, err)
+		require.NotNil(t, movements)
+		require.Empty(t, movements)
+	})
+}
@@ -0,0 +1,64 @@
+package internal
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrReservationNotFound = errors.New("reservation not found")
+	// ErrReservationNotActive is returned when confirming or cancelling a reservation no longer holding stock
+	ErrReservationNotActive = errors.New("reservation is not active")
+)
+
+// MaxReservationTTL is the longest a reservation can hold stock
+const MaxReservationTTL = 24 * time.Hour
+
+// ReservationStatus is the state of a reservation, only active ones hold stock
+type ReservationStatus string
+
+const (
+	ReservationActive    ReservationStatus = "active"
+	ReservationConfirmed ReservationStatus = "confirmed"
+	ReservationCancelled ReservationStatus = "cancelled"
+	ReservationExpired   ReservationStatus = "expired"
+)
+
+// Reservation holds a quantity of a product until it is confirmed, which takes it from the stock,
+// cancelled or it expires, which release it
+type Reservation struct {
+	ID        int
+	ProductID int
+	Quantity  int
+	Status    ReservationStatus
+	// Reference identifies the document behind the reservation, e.g. a checkout
+	Reference string
+	Actor     string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	// UpdatedAt is when the reservation left the active status, zero while it is active
+	UpdatedAt time.Time
+}
+
+// IsActive reports whether the reservation holds stock at the time,
+// an active reservation past its expiration not holding it anymore even if the sweeper did not release it yet
+func (r Reservation) IsActive(at time.Time) bool {
+	return r.Status == ReservationActive && at.Before(r.ExpiresAt)
+}
+
+// ReservationStore stores the reservations of the products
+type ReservationStore interface {
+	// Save stores the reservation, setting its id
+	Save(reservation *Reservation) error
+	// GetById returns the reservation, ErrReservationNotFound when there is none
+	GetById(id int) (Reservation, error)
+	// Close moves the reservation out of the active status, at being the time of the change.
+	// ErrReservationNotActive is returned when it is not active at that time, so a reservation is closed once.
+	Close(id int, status ReservationStatus, at time.Time) (Reservation, error)
+	// Reserved returns the quantity held by the reservations of the product active at the time
+	Reserved(productID int, at time.Time) (int, error)
+	// Expire closes as expired the active reservations past their expiration at the time, returning them
+	Expire(at time.Time) ([]Reservation, error)
+	// Reservations returns the reservations of the product, oldest first
+	Reservations(productID int) ([]Reservation, error)
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"app/internal"
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ConfigAlertMonitor is the configuration of an AlertMonitor
+type ConfigAlertMonitor struct {
+	// Interval is the time between two scans of the products
+	Interval time.Duration
+	// LowStockThreshold raises a low stock alert for the products with a quantity below it, zero disables them
+	LowStockThreshold int
+	// ExpirationDays raises an expiration alert for the products expiring within the days, negative disables them
+	ExpirationDays int
+}
+
+// alertKey identifies the condition of a product an alert is raised for
+type alertKey struct {
+	kind      internal.AlertKind
+	productID int
+}
+
+// AlertMonitor periodically scans the products, raising an alert through the notifier
+// when a product enters a low stock or expiration condition and resolving it when the product leaves it
+type AlertMonitor struct {
+	rp  internal.ProductRepository
+	nt  internal.AlertNotifier
+	cfg ConfigAlertMonitor
+
+	mu sync.RWMutex
+	// raised holds the alerts of the conditions found by the last scan
+	raised map[alertKey]internal.Alert
+	// unsent holds the raised alerts the notifier did not emit yet
+	unsent map[alertKey]bool
+	lastId int
+}
+
+func NewAlertMonitor(rp internal.ProductRepository, nt internal.AlertNotifier, cfg ConfigAlertMonitor) *AlertMonitor {
+	return &AlertMonitor{
+		rp:     rp,
+		nt:     nt,
+		cfg:    cfg,
+		raised: make(map[alertKey]internal.Alert),
+		unsent: make(map[alertKey]bool),
+	}
+}
+
+// Run scans the products right away then every interval until the context is done
+func (m *AlertMonitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := m.Scan(ctx); err != nil {
+			slog.Error("alert monitor: scanning the products", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Scan raises the alerts of the conditions the products entered since the last scan and resolves the ones they left.
+// An alert is notified once: when the notifier fails, it is notified again by the next scans while it stays raised.
+func (m *AlertMonitor) Scan(ctx context.Context) error {
+	found, err := m.conditions()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+
+	m.mu.Lock()
+	for key, alert := range found {
+		// an alert already raised keeps its id and time, the product being the current one
+		if previous, ok := m.raised[key]; ok {
+			alert.ID, alert.RaisedAt = previous.ID, previous.RaisedAt
+			found[key] = alert
+			continue
+		}
+		m.lastId++
+		alert.ID = m.lastId
+		alert.RaisedAt = now
+		found[key] = alert
+		m.unsent[key] = true
+	}
+	m.raised = found
+
+	unsent := make([]internal.Alert, 0, len(m.unsent))
+	for key := range m.unsent {
+		// an alert resolved before it was notified is dropped
+		alert, ok := found[key]
+		if !ok {
+			delete(m.unsent, key)
+			continue
+		}
+		unsent = append(unsent, alert)
+	}
+	m.mu.Unlock()
+
+	// the notifier may be slow, e.g. a webhook, so it is called without holding the lock
+	sortAlerts(unsent)
+	for _, alert := range unsent {
+		if err := m.nt.Notify(ctx, alert); err != nil {
+			slog.Warn("alert monitor: notifying the alert, it is retried by the next scan", "alert_id", alert.ID, "error", err)
+			continue
+		}
+
+		m.mu.Lock()
+		delete(m.unsent, alertKey{alert.Kind, alert.ProductID})
+		m.mu.Unlock()
+	}
+
+	return nil
+}
+
+func (m *AlertMonitor) Alerts(kind internal.AlertKind) ([]internal.Alert, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	alerts := make([]internal.Alert, 0, len(m.raised))
+	for _, alert := range m.raised {
+		if kind == "" || alert.Kind == kind {
+			alerts = append(alerts, alert)
+		}
+	}
+	sortAlerts(alerts)
+
+	return alerts, nil
+}
+
+// conditions returns the alerts of the conditions the products are currently in, without id nor time
+func (m *AlertMonitor) conditions() (map[alertKey]internal.Alert, error) {
+	found := make(map[alertKey]internal.Alert)
+
+	if m.cfg.LowStockThreshold > 0 {
+		below := m.cfg.LowStockThreshold - 1
+		products, _, err := m.rp.Find(internal.ProductQuery{QuantityMax: &below})
+		if err != nil {
+			return nil, err
+		}
+		for _, product := range products {
+			found[alertKey{internal.AlertLowStock, product.ID}] = newAlert(internal.AlertLowStock, product,
+				fmt.Sprintf("quantity %d is below the threshold of %d", product.Quantity, m.cfg.LowStockThreshold))
+		}
+	}
+
+	if m.cfg.ExpirationDays >= 0 {
+		today := internal.Today()
+		// the bound is exclusive, so products expiring on the last day are included
+		before := today.AddDays(m.cfg.ExpirationDays + 1)
+		products, _, err := m.rp.Find(internal.ProductQuery{ExpirationBefore: &before})
+		if err != nil {
+			return nil, err
+		}
+		for _, product := range products {
+			// products without an expiration date never expire, though they sort before any bound
+			if product.Expiration.IsZero() {
+				continue
+			}
+			message := fmt.Sprintf("expires on %s", product.Expiration)
+			if product.Expiration.Before(today) {
+				message = fmt.Sprintf("expired on %s", product.Expiration)
+			}
+			found[alertKey{internal.AlertExpiration, product.ID}] = newAlert(internal.AlertExpiration, product, message)
+		}
+	}
+
+	return found, nil
+}
+
+// newAlert returns an alert of the kind for the product
+func newAlert(kind internal.AlertKind, product internal.Product, message string) internal.Alert {
+	return internal.Alert{
+		Kind:       kind,
+		ProductID:  product.ID,
+		CodeValue:  product.CodeValue,
+		Quantity:   product.Quantity,
+		Expiration: product.Expiration,
+		Message:    message,
+	}
+}
+
+// sortAlerts sorts the alerts by id, which is the order they were raised in
+func sortAlerts(alerts []internal.Alert) {
+	sort.Slice(alerts, func(i, j int) bool {
+		return alerts[i].ID < alerts[j].ID
+	})
+}
@@ -0,0 +1,218 @@
+package service_test
+
+import (
+	"app/internal"
+	"app/internal/notifier"
+	"app/internal/repository"
+	"app/internal/service"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// failingNotifier fails the first notifications, then emits the alerts through AlertMemory
+type failingNotifier struct {
+	*notifier.AlertMemory
+	failures int
+}
+
+func (fn *failingNotifier) Notify(ctx context.Context, alert internal.Alert) error {
+	if fn.failures > 0 {
+		fn.failures--
+		return errors.New("notifier unavailable")
+	}
+	return fn.AlertMemory.Notify(ctx, alert)
+}
+
+// newMonitoredCatalog returns a ProductMap holding the products, which do not expire unless they say so
+func newMonitoredCatalog(t *testing.T, products ...internal.Product) *repository.ProductMap {
+	t.Helper()
+
+	rp := repository.NewProductMap(nil, 0)
+	for _, product := range products {
+		product := product
+		if product.Expiration.IsZero() {
+			product.Expiration = internal.Today().AddDays(365)
+		}
+		require.NoError(t, rp.Save(&product))
+	}
+	return rp
+}
+
+// Tests for AlertMonitor.Scan
+func TestAlertMonitor_Scan(t *testing.T) {
+	lowStock := service.ConfigAlertMonitor{LowStockThreshold: 5, ExpirationDays: -1}
+	expiration := service.ConfigAlertMonitor{ExpirationDays: 3}
+
+	t.Run("success - low stock below the threshold only", func(t *testing.T) {
+		// arrange
+		rp := newMonitoredCatalog(t,
+			internal.Product{Name: "below", CodeValue: "below", Quantity: 4},
+			internal.Product{Name: "at", CodeValue: "at", Quantity: 5},
+			internal.Product{Name: "empty", CodeValue: "empty", Quantity: 0},
+		)
+		nt := notifier.NewAlertMemory()
+		m := service.NewAlertMonitor(rp, nt, lowStock)
+
+		// act
+		err := m.Scan(context.Background())
+
+		// assert
+		require.NoError(t, err)
+		alerts := nt.Alerts()
+		require.Len(t, alerts, 2)
+		codes := []string{alerts[0].CodeValue, alerts[1].CodeValue}
+		require.ElementsMatch(t, []string{"below", "empty"}, codes)
+		require.Equal(t, internal.AlertLowStock, alerts[0].Kind)
+		raised, _ := m.Alerts("")
+		require.Equal(t, alerts, raised)
+	})
+
+	t.Run("success - expiration within the days, the last one included", func(t *testing.T) {
+		// arrange
+		today := internal.Today()
+		rp := newMonitoredCatalog(t,
+			internal.Product{Name: "expired", CodeValue: "expired", Quantity: 1, Expiration: today.AddDays(-1)},
+			internal.Product{Name: "today", CodeValue: "today", Quantity: 1, Expiration: today},
+			internal.Product{Name: "last day", CodeValue: "last", Quantity: 1, Expiration: today.AddDays(3)},
+			internal.Product{Name: "after", CodeValue: "after", Quantity: 1, Expiration: today.AddDays(4)},
+		)
+		nt := notifier.NewAlertMemory()
+		m := service.NewAlertMonitor(rp, nt, expiration)
+
+		// act
+		err := m.Scan(context.Background())
+
+		// assert
+		require.NoError(t, err)
+		alerts, _ := m.Alerts(internal.AlertExpiration)
+		messages := make(map[string]string)
+		for _, alert := range alerts {
+			messages[alert.CodeValue] = alert.Message
+		}
+		require.Equal(t, map[string]string{
+			"expired": "expired on " + today.AddDays(-1).String(),
+			"today":   "expires on " + today.String(),
+			"last":    "expires on " + today.AddDays(3).String(),
+		}, messages)
+	})
+
+	t.Run("success - products without an expiration date never expire", func(t *testing.T) {
+		// arrange
+		rp := newMonitoredCatalog(t, internal.Product{Name: "expired", CodeValue: "expired", Quantity: 1, Expiration: internal.Today().AddDays(-1)})
+		undated := internal.Product{Name: "undated", CodeValue: "undated", Quantity: 1}
+		require.NoError(t, rp.Save(&undated))
+		nt := notifier.NewAlertMemory()
+		m := service.NewAlertMonitor(rp, nt, expiration)
+
+		// act
+		err := m.Scan(context.Background())
+
+		// assert
+		require.NoError(t, err)
+		alerts, _ := m.Alerts(internal.AlertExpiration)
+		require.Len(t, alerts, 1)
+		require.Equal(t, "expired", alerts[0].CodeValue)
+	})
+
+	t.Run("success - disabled conditions raise nothing", func(t *testing.T) {
+		// arrange
+		rp := newMonitoredCatalog(t, internal.Product{Name: "product", CodeValue: "code", Quantity: 0, Expiration: internal.Today()})
+		nt := notifier.NewAlertMemory()
+		m := service.NewAlertMonitor(rp, nt, service.ConfigAlertMonitor{LowStockThreshold: 0, ExpirationDays: -1})
+
+		// act
+		err := m.Scan(context.Background())
+
+		// assert
+		require.NoError(t, err)
+		require.Empty(t, nt.Alerts())
+	})
+
+	t.Run("success - an alert is raised and notified once while the condition holds", func(t *testing.T) {
+		// arrange
+		rp := newMonitoredCatalog(t, internal.Product{Name: "product", CodeValue: "code", Quantity: 4})
+		nt := notifier.NewAlertMemory()
+		m := service.NewAlertMonitor(rp, nt, lowStock)
+		require.NoError(t, m.Scan(context.Background()))
+		first, _ := m.Alerts("")
+		_, err := rp.AdjustStock(1, -1)
+		require.NoError(t, err)
+
+		// act
+		err = m.Scan(context.Background())
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, nt.Alerts(), 1)
+		alerts, _ := m.Alerts("")
+		require.Len(t, alerts, 1)
+		require.Equal(t, first[0].ID, alerts[0].ID)
+		require.Equal(t, first[0].RaisedAt, alerts[0].RaisedAt)
+		require.Equal(t, 3, alerts[0].Quantity)
+	})
+
+	t.Run("success - an alert is resolved when the condition is left and raised again when it is entered", func(t *testing.T) {
+		// arrange
+		rp := newMonitoredCatalog(t, internal.Product{Name: "product", CodeValue: "code", Quantity: 4})
+		nt := notifier.NewAlertMemory()
+		m := service.NewAlertMonitor(rp, nt, lowStock)
+		require.NoError(t, m.Scan(context.Background()))
+		_, err := rp.AdjustStock(1, 10)
+		require.NoError(t, err)
+
+		// act
+		errResolve := m.Scan(context.Background())
+		resolved, _ := m.Alerts("")
+		_, err = rp.AdjustStock(1, -10)
+		require.NoError(t, err)
+		errRaise := m.Scan(context.Background())
+
+		// assert
+		require.NoError(t, errResolve)
+		require.Empty(t, resolved)
+		require.NoError(t, errRaise)
+		notified := nt.Alerts()
+		require.Len(t, notified, 2)
+		require.NotEqual(t, notified[0].ID, notified[1].ID)
+	})
+
+	t.Run("success - an alert the notifier failed to emit is notified by the next scan", func(t *testing.T) {
+		// arrange
+		rp := newMonitoredCatalog(t, internal.Product{Name: "product", CodeValue: "code", Quantity: 4})
+		nt := &failingNotifier{AlertMemory: notifier.NewAlertMemory(), failures: 1}
+		m := service.NewAlertMonitor(rp, nt, lowStock)
+
+		// act
+		errFailed := m.Scan(context.Background())
+		failed := nt.Alerts()
+		errRetried := m.Scan(context.Background())
+		errAgain := m.Scan(context.Background())
+
+		// assert
+		require.NoError(t, errFailed)
+		require.Empty(t, failed)
+		require.NoError(t, errRetried)
+		require.NoError(t, errAgain)
+		require.Len(t, nt.Alerts(), 1)
+	})
+
+	t.Run("success - an alert resolved before it was notified is dropped", func(t *testing.T) {
+		// arrange
+		rp := newMonitoredCatalog(t, internal.Product{Name: "product", CodeValue: "code", Quantity: 4})
+		nt := &failingNotifier{AlertMemory: notifier.NewAlertMemory(), failures: 1}
+		m := service.NewAlertMonitor(rp, nt, lowStock)
+		require.NoError(t, m.Scan(context.Background()))
+		_, err := rp.AdjustStock(1, 10)
+		require.NoError(t, err)
+
+		// act
+		err = m.Scan(context.Background())
+
+		// assert
+		require.NoError(t, err)
+		require.Empty(t, nt.Alerts())
+	})
+}
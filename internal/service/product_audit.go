@@ -0,0 +1,77 @@
+package service
+
+import (
+	"app/internal"
+	"context"
+	"log/slog"
+	"time"
+)
+
+// audit records a mutation of the product in the audit log.
+// The mutation is already done, so a failure to record it is logged instead of returned.
+func (pd *ProductDefault) audit(ctx context.Context, op internal.AuditOperation, productID int, changes []internal.AuditChange) {
+	if pd.au == nil {
+		return
+	}
+
+	entry := internal.AuditEntry{
+		ProductID: productID,
+		Actor:     internal.ActorFromContext(ctx),
+		Timestamp: time.Now().UTC(),
+		Operation: op,
+		Changes:   changes,
+	}
+	if err := pd.au.Record(&entry); err != nil {
+		slog.Error("audit: recording the mutation", "operation", op, "product_id", productID, "error", err)
+	}
+}
+
+// productChanges returns the fields that differ between the product before and after a mutation.
+// A nil before is a product that did not exist, so every field is reported.
+func productChanges(before, after *internal.Product) []internal.AuditChange {
+	type field struct {
+		name          string
+		before, after any
+	}
+	fields := []field{
+		{"name", nil, after.Name},
+		{"quantity", nil, after.Quantity},
+		{"code_value", nil, after.CodeValue},
+		{"is_published", nil, after.IsPublished},
+		{"expiration", nil, after.Expiration},
+		{"price", nil, after.Price},
+	}
+	if before != nil {
+		values := []any{before.Name, before.Quantity, before.CodeValue, before.IsPublished, before.Expiration, before.Price}
+		for i := range fields {
+			fields[i].before = values[i]
+		}
+	}
+
+	changes := make([]internal.AuditChange, 0, len(fields))
+	for _, f := range fields {
+		if before != nil && f.before == f.after {
+			continue
+		}
+		changes = append(changes, internal.AuditChange{Field: f.name, Before: f.before, After: f.after})
+	}
+	return changes
+}
+
+func (pd *ProductDefault) History(id int) ([]internal.AuditEntry, error) {
+	entries := []internal.AuditEntry{}
+	if pd.au != nil {
+		var err error
+		if entries, err = pd.au.History(id); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(entries) == 0 {
+		if err := pd.productExists(id); err != nil {
+			return nil, err
+		}
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,208 @@
+package service_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"app/internal/service"
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newAuditedService returns a service over an empty ProductMap recording its mutations in an AuditMap
+func newAuditedService() *service.ProductDefault {
+	rp := repository.NewProductMap(nil, 0)
+	return service.NewProductDefault(rp, repository.NewAuditMap(), nil, repository.NewReservationMap())
+}
+
+// racingRepository runs race, once, right before the first update, as another request would
+type racingRepository struct {
+	*repository.ProductMap
+	race func()
+}
+
+func (r *racingRepository) Update(product *internal.Product) error {
+	if r.race != nil {
+		race := r.race
+		r.race = nil
+		race()
+	}
+	return r.ProductMap.Update(product)
+}
+
+// failingAudit is an audit store failing to record the entries
+type failingAudit struct {
+	*repository.AuditMap
+}
+
+func (fa failingAudit) Record(entry *internal.AuditEntry) error {
+	return errors.New("audit unavailable")
+}
+
+// Tests for the audit log of ProductDefault
+func TestProductDefault_History(t *testing.T) {
+	t.Run("success - a creation records every field", func(t *testing.T) {
+		// arrange
+		sv := newAuditedService()
+		product := newProduct("code")
+		ctx := internal.ContextWithActor(context.Background(), "alice")
+
+		// act
+		require.NoError(t, sv.Save(ctx, product))
+		entries, err := sv.History(product.ID)
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		require.Equal(t, "alice", entries[0].Actor)
+		require.Equal(t, internal.AuditOperationCreate, entries[0].Operation)
+		require.Equal(t, []internal.AuditChange{
+			{Field: "name", After: "product"},
+			{Field: "quantity", After: 10},
+			{Field: "code_value", After: "code"},
+			{Field: "is_published", After: true},
+			{Field: "expiration", After: product.Expiration},
+			{Field: "price", After: product.Price},
+		}, entries[0].Changes)
+	})
+
+	t.Run("success - an update records the changed fields only", func(t *testing.T) {
+		// arrange
+		sv := newAuditedService()
+		product := newProduct("code")
+		require.NoError(t, sv.Save(context.Background(), product))
+		updated := *product
+		updated.Name = "renamed"
+		updated.Price = internal.Money{Amount: 200, Currency: "USD"}
+
+		// act
+		require.NoError(t, sv.Update(context.Background(), &updated))
+		entries, err := sv.History(product.ID)
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		require.Equal(t, internal.AnonymousActor, entries[1].Actor)
+		require.Equal(t, internal.AuditOperationUpdate, entries[1].Operation)
+		require.Equal(t, []internal.AuditChange{
+			{Field: "name", Before: "product", After: "renamed"},
+			{Field: "price", Before: product.Price, After: updated.Price},
+		}, entries[1].Changes)
+	})
+
+	t.Run("success - an update racing another one records the product it replaced", func(t *testing.T) {
+		// arrange
+		rp := &racingRepository{ProductMap: repository.NewProductMap(nil, 0)}
+		sv := service.NewProductDefault(rp, repository.NewAuditMap(), nil, repository.NewReservationMap())
+		product := newProduct("code")
+		require.NoError(t, sv.Save(context.Background(), product))
+		rp.race = func() {
+			other := *product
+			other.Name = "other"
+			require.NoError(t, rp.ProductMap.Update(&other))
+		}
+		updated := *product
+		updated.Version = 0
+		updated.Name = "renamed"
+
+		// act
+		err := sv.Update(context.Background(), &updated)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, 3, updated.Version)
+		entries, _ := sv.History(product.ID)
+		require.Equal(t, []internal.AuditChange{{Field: "name", Before: "other", After: "renamed"}}, entries[1].Changes)
+	})
+
+	t.Run("success - deletion and restoration are recorded", func(t *testing.T) {
+		// arrange
+		sv := newAuditedService()
+		product := newProduct("code")
+		require.NoError(t, sv.Save(context.Background(), product))
+
+		// act
+		require.NoError(t, sv.Delete(context.Background(), product.ID))
+		require.NoError(t, sv.Restore(context.Background(), product.ID))
+		entries, err := sv.History(product.ID)
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, entries, 3)
+		require.Equal(t, internal.AuditOperationDelete, entries[1].Operation)
+		require.Equal(t, []internal.AuditChange{{Field: "deleted", Before: false, After: true}}, entries[1].Changes)
+		require.Equal(t, internal.AuditOperationRestore, entries[2].Operation)
+	})
+
+	t.Run("error - a failure to record is logged as an error", func(t *testing.T) {
+		// arrange
+		var logs bytes.Buffer
+		defaultLogger := slog.Default()
+		slog.SetDefault(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelError})))
+		t.Cleanup(func() { slog.SetDefault(defaultLogger) })
+		rp := repository.NewProductMap(nil, 0)
+		sv := service.NewProductDefault(rp, failingAudit{repository.NewAuditMap()}, nil, repository.NewReservationMap())
+
+		// act
+		err := sv.Save(context.Background(), newProduct("code"))
+
+		// assert
+		require.NoError(t, err)
+		require.Contains(t, logs.String(), "level=ERROR")
+		require.Contains(t, logs.String(), "audit unavailable")
+	})
+
+	t.Run("error - a stale update is not recorded", func(t *testing.T) {
+		// arrange
+		sv := newAuditedService()
+		product := newProduct("code")
+		require.NoError(t, sv.Save(context.Background(), product))
+		stale := *product
+		updated := *product
+		updated.Name = "renamed"
+		require.NoError(t, sv.Update(context.Background(), &updated))
+
+		// act
+		stale.Name = "stale"
+		err := sv.Update(context.Background(), &stale)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrProductVersionConflict)
+		require.Equal(t, product.Version, stale.Version)
+		entries, _ := sv.History(product.ID)
+		require.Len(t, entries, 2)
+	})
+
+	t.Run("error - product not found", func(t *testing.T) {
+		// arrange
+		sv := newAuditedService()
+
+		// act
+		_, errHistory := sv.History(1)
+		errUpdate := sv.Update(context.Background(), newProduct("code"))
+
+		// assert
+		require.ErrorIs(t, errHistory, internal.ErrProductNotFound)
+		require.ErrorIs(t, errUpdate, internal.ErrProductNotFound)
+	})
+
+	t.Run("error - product not found without audit log", func(t *testing.T) {
+		// arrange
+		sv, _ := newService()
+		product := newProduct("code")
+		require.NoError(t, sv.Save(context.Background(), product))
+
+		// act
+		entries, errFound := sv.History(product.ID)
+		_, errNotFound := sv.History(product.ID + 1)
+
+		// assert
+		require.NoError(t, errFound)
+		require.Empty(t, entries)
+		require.ErrorIs(t, errNotFound, internal.ErrProductNotFound)
+	})
+}
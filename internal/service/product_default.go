@@ -2,23 +2,45 @@ package service
 
 import (
 	"app/internal"
+	"app/platform/web/validate"
+	"context"
 	"errors"
 	"fmt"
-	"time"
+	"sync"
 )
 
+// productValidator holds the validation rules declared on internal.Product
+var productValidator = validate.MustNew(internal.Product{})
+
 type ProductDefault struct {
 	rp internal.ProductRepository
+	// au records the mutations of the products, nil disables the audit log
+	au internal.AuditStore
+	// sl records the stock movements of the products, nil disables the ledger
+	sl internal.StockLedger
+	// rs holds the reservations of the products, it is required as they hold stock
+	rs internal.ReservationStore
+	// stock serializes the operations checking the available stock of the products before changing it
+	stock sync.Mutex
 }
 
-func NewProductDefault(rp internal.ProductRepository) *ProductDefault {
+// NewProductDefault returns a service over rp, recording its mutations in au and its stock movements in sl when they are not nil.
+// It panics if rs is nil.
+func NewProductDefault(rp internal.ProductRepository, au internal.AuditStore, sl internal.StockLedger, rs internal.ReservationStore) *ProductDefault {
+	if rs == nil {
+		panic("service: the reservation store is required")
+	}
+
 	return &ProductDefault{
 		rp: rp,
+		au: au,
+		sl: sl,
+		rs: rs,
 	}
 }
 
-func (pd *ProductDefault) Save(product *internal.Product) error {
-	if err := pd.validateProduct(product); err != nil {
+func (pd *ProductDefault) Save(ctx context.Context, product *internal.Product) error {
+	if err := pd.validateNewProduct(product); err != nil {
 		return err
 	}
 
@@ -27,35 +49,157 @@ func (pd *ProductDefault) Save(product *internal.Product) error {
 	if err != nil {
 		switch err {
 		case internal.ErrProductCodeAlreadyExists:
-			err = fmt.Errorf("%w: code_value", internal.ErrProductCodeAlreadyExists)
+			err = internal.NewFieldError(internal.ErrProductCodeAlreadyExists, "code_value")
 		}
+		return err
 	}
 
-	return err
+	pd.audit(ctx, internal.AuditOperationCreate, product.ID, productChanges(nil, product))
 
+	return nil
 }
 
-func (pd *ProductDefault) validateProduct(p *internal.Product) error {
+func (pd *ProductDefault) SaveBatch(ctx context.Context, products []*internal.Product, mode internal.BatchMode) ([]internal.BatchResult, error) {
 	switch {
-	case p.Name == "":
-		return fmt.Errorf("%w: name", internal.ErrFieldRequired)
-	case p.Quantity == 0:
-		return fmt.Errorf("%w: quantity", internal.ErrFieldRequired)
-	case p.CodeValue == "":
-		return fmt.Errorf("%w: code_value", internal.ErrFieldRequired)
-	case p.Expiration == "":
-		return fmt.Errorf("%w: expiration", internal.ErrFieldRequired)
-	case p.Price == 0:
-		return fmt.Errorf("%w: price", internal.ErrFieldRequired)
+	case len(products) == 0 || len(products) > internal.MaxBatchSize:
+		return nil, fmt.Errorf("%w: must have between 1 and %d products", internal.ErrBatchSize, internal.MaxBatchSize)
+	case mode != internal.BatchModeAtomic && mode != internal.BatchModeBestEffort:
+		return nil, fmt.Errorf("%w: %s", internal.ErrBatchMode, mode)
+	}
+
+	results := make([]internal.BatchResult, len(products))
+	for i := range products {
+		results[i].Index = i
+	}
+
+	if mode == internal.BatchModeBestEffort {
+		for i, product := range products {
+			if err := pd.Save(ctx, product); err != nil {
+				results[i].Err = err
+				continue
+			}
+			results[i].ID = product.ID
+		}
+		return results, nil
+	}
+
+	// atomic: every product must be valid before any is saved
+	rejected := false
+	for i, product := range products {
+		if err := pd.validateNewProduct(product); err != nil {
+			results[i].Err = err
+			rejected = true
+		}
+	}
+	if rejected {
+		return results, internal.ErrBatchRejected
+	}
+
+	if err := pd.rp.SaveAll(products); err != nil {
+		var itemErr *internal.BatchItemError
+		if !errors.As(err, &itemErr) {
+			return nil, err
+		}
+
+		switch {
+		case errors.Is(itemErr.Err, internal.ErrProductCodeAlreadyExists):
+			results[itemErr.Index].Err = internal.NewFieldError(internal.ErrProductCodeAlreadyExists, "code_value")
+		default:
+			results[itemErr.Index].Err = itemErr.Err
+		}
+		return results, internal.ErrBatchRejected
+	}
+
+	for i, product := range products {
+		results[i].ID = product.ID
+		pd.audit(ctx, internal.AuditOperationCreate, product.ID, productChanges(nil, product))
+	}
+
+	return results, nil
+}
+
+func (pd *ProductDefault) ValidateBatch(products []*internal.Product) ([]internal.BatchResult, error) {
+	if len(products) == 0 || len(products) > internal.MaxBatchSize {
+		return nil, fmt.Errorf("%w: must have between 1 and %d products", internal.ErrBatchSize, internal.MaxBatchSize)
+	}
+
+	results := make([]internal.BatchResult, len(products))
+	codes := make(map[string]bool, len(products))
+	for i, product := range products {
+		results[i].Index = i
+
+		if err := pd.validateNewProduct(product); err != nil {
+			results[i].Err = err
+			continue
+		}
+		if _, err := pd.rp.GetByCode(product.CodeValue); err == nil || codes[product.CodeValue] {
+			results[i].Err = internal.NewFieldError(internal.ErrProductCodeAlreadyExists, "code_value")
+			continue
+		} else if !errors.Is(err, internal.ErrProductNotFound) {
+			return nil, err
+		}
+		codes[product.CodeValue] = true
 	}
 
-	_, err := time.Parse("02/02/2006", p.Expiration)
+	return results, nil
+}
 
+func (pd *ProductDefault) Validate(product *internal.Product) error {
+	if product.ID == 0 {
+		return pd.validateNewProduct(product)
+	}
+	return pd.validateProduct(product)
+}
+
+// validateProduct checks every field of the product against the rules declared on internal.Product,
+// reporting all the violations as internal.ValidationErrors
+func (pd *ProductDefault) validateProduct(p *internal.Product) error {
+	ve, err := pd.productViolations(p)
 	if err != nil {
-		return fmt.Errorf("%w: expiration", internal.ErrFieldFormat)
+		return err
 	}
 
-	return nil
+	return ve.Err()
+}
+
+// validateNewProduct is like validateProduct, also rejecting products that are already expired
+func (pd *ProductDefault) validateNewProduct(p *internal.Product) error {
+	ve, err := pd.productViolations(p)
+	if err != nil {
+		return err
+	}
+	if !p.Expiration.IsZero() && p.Expiration.Before(internal.Today()) {
+		ve.Add("expiration", internal.RuleRange, "must not be in the past")
+	}
+
+	return ve.Err()
+}
+
+// productViolations returns the violations of the rules declared on internal.Product by the product
+func (pd *ProductDefault) productViolations(p *internal.Product) (internal.ValidationErrors, error) {
+	errs, err := productValidator.Struct(p)
+	if err != nil {
+		return nil, err
+	}
+
+	var ve internal.ValidationErrors
+	for _, e := range errs {
+		ve.Add(e.Field, e.Rule, e.Message)
+	}
+	if !p.Price.IsZero() {
+		if _, ok := internal.CurrencyExponent(p.Price.Currency); !ok {
+			ve.Add("price", internal.RuleFormat, "currency must be a supported ISO-4217 code")
+		}
+		switch {
+		case p.Price.IsNegative():
+			ve.Add("price", internal.RuleRange, "must be greater than 0")
+		case p.Price.Amount == 0:
+			// a price of 0 is as missing as no price at all
+			ve.Add("price", internal.RuleRequired, "field is required")
+		}
+	}
+
+	return ve, nil
 }
 
 func (pd *ProductDefault) GetById(id int) (internal.Product, error) {
@@ -64,40 +208,182 @@ func (pd *ProductDefault) GetById(id int) (internal.Product, error) {
 	if err != nil {
 		switch {
 		case errors.Is(err, internal.ErrProductNotFound):
-			err = fmt.Errorf("%w: id", internal.ErrProductID)
+			err = internal.NewFieldError(internal.ErrProductID, "id")
 		}
 	}
 
 	return prod, err
 }
 
-func (pd *ProductDefault) Update(product *internal.Product) error {
+// productExists returns a not found error when the product is not active.
+// The records of a product (audit log, ledger, reservations) are read without it, so it tells an unknown id
+// from a product without records; products with records are known to exist, or to have existed.
+func (pd *ProductDefault) productExists(id int) error {
+	_, err := pd.rp.GetById(id)
+	if errors.Is(err, internal.ErrProductNotFound) {
+		return internal.NewFieldError(internal.ErrProductNotFound, "id")
+	}
+	return err
+}
+
+func (pd *ProductDefault) GetByCode(code string) (internal.Product, error) {
+	if code == "" {
+		return internal.Product{}, internal.NewFieldError(internal.ErrFieldRequired, "code_value")
+	}
+
+	prod, err := pd.rp.GetByCode(code)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, internal.ErrProductNotFound):
+			err = internal.NewFieldError(internal.ErrProductNotFound, "code_value")
+		}
+	}
+
+	return prod, err
+}
+
+func (pd *ProductDefault) Update(ctx context.Context, product *internal.Product) error {
 
 	if err := pd.validateProduct(product); err != nil {
 		return err
 	}
 
-	err := pd.rp.Update(product)
+	before, err := pd.update(product)
 
 	if err != nil {
 		switch err {
 		case internal.ErrProductNotFound:
-			err = fmt.Errorf("%w: id", internal.ErrProductNotFound)
+			err = internal.NewFieldError(internal.ErrProductNotFound, "id")
 		}
+		return err
 	}
 
-	return err
+	pd.audit(ctx, internal.AuditOperationUpdate, product.ID, productChanges(&before, product))
+
+	return nil
+}
+
+// update replaces the product, returning the one it replaced when the audit log needs it.
+// The replaced product is read and written as one operation: the update is bound to the version read,
+// so it is retried when another update came first, unless the caller asked for a version itself.
+func (pd *ProductDefault) update(product *internal.Product) (internal.Product, error) {
+	if pd.au == nil {
+		return internal.Product{}, pd.rp.Update(product)
+	}
+
+	version := product.Version
+	for {
+		before, err := pd.rp.GetById(product.ID)
+		if err != nil {
+			return internal.Product{}, err
+		}
+		if version == 0 {
+			product.Version = before.Version
+		}
+
+		err = pd.rp.Update(product)
+		switch {
+		case err == nil:
+			return before, nil
+		case errors.Is(err, internal.ErrProductVersionConflict) && version == 0:
+			continue
+		}
+		product.Version = version
+		return internal.Product{}, err
+	}
 }
 
-func (pd *ProductDefault) Delete(id int) error {
+func (pd *ProductDefault) Delete(ctx context.Context, id int) error {
 	err := pd.rp.Delete(id)
 
 	if err != nil {
 		switch err {
 		case internal.ErrProductNotFound:
-			err = fmt.Errorf("%w: id", internal.ErrProductNotFound)
+			err = internal.NewFieldError(internal.ErrProductNotFound, "id")
 		}
+		return err
 	}
 
-	return err
+	pd.audit(ctx, internal.AuditOperationDelete, id, []internal.AuditChange{{Field: "deleted", Before: false, After: true}})
+
+	return nil
+}
+
+func (pd *ProductDefault) Restore(ctx context.Context, id int) error {
+	err := pd.rp.Restore(id)
+
+	if err != nil {
+		switch err {
+		case internal.ErrProductNotFound:
+			err = internal.NewFieldError(internal.ErrProductNotFound, "id")
+		case internal.ErrProductCodeAlreadyExists:
+			err = internal.NewFieldError(internal.ErrProductCodeAlreadyExists, "code_value")
+		}
+		return err
+	}
+
+	pd.audit(ctx, internal.AuditOperationRestore, id, []internal.AuditChange{{Field: "deleted", Before: true, After: false}})
+
+	return nil
+}
+
+func (pd *ProductDefault) Purge(ctx context.Context, id int) error {
+	err := pd.rp.Purge(id)
+
+	if err != nil {
+		switch err {
+		case internal.ErrProductNotFound:
+			err = internal.NewFieldError(internal.ErrProductNotFound, "id")
+		}
+		return err
+	}
+
+	// the last values of the purged product are the ones recorded by the previous entries
+	pd.audit(ctx, internal.AuditOperationPurge, id, nil)
+
+	return nil
+}
+
+const (
+	// defaultPageLimit is the page size used when the query does not provide one
+	defaultPageLimit = 20
+	// maxPageLimit is the biggest page size a query can request
+	maxPageLimit = 100
+)
+
+func (pd *ProductDefault) Find(query *internal.ProductQuery) ([]internal.Product, int, error) {
+	if err := pd.validateQuery(query); err != nil {
+		return nil, 0, err
+	}
+
+	return pd.rp.Find(*query)
+}
+
+func (pd *ProductDefault) validateQuery(q *internal.ProductQuery) error {
+	switch {
+	case q.Limit < 0 || q.Limit > maxPageLimit:
+		return internal.NewFieldError(internal.ErrQueryParam, "limit")
+	case q.Offset < 0:
+		return internal.NewFieldError(internal.ErrQueryParam, "offset")
+	case q.PriceMin != nil && q.PriceMax != nil && q.PriceMin.Compare(*q.PriceMax) > 0:
+		return internal.NewFieldError(internal.ErrQueryParam, "price_min")
+	case q.QuantityMin != nil && q.QuantityMax != nil && *q.QuantityMin > *q.QuantityMax:
+		return internal.NewFieldError(internal.ErrQueryParam, "quantity_min")
+	}
+
+	for _, key := range q.Sort {
+		switch key.Field {
+		case internal.ProductSortID, internal.ProductSortName, internal.ProductSortQuantity,
+			internal.ProductSortCodeValue, internal.ProductSortExpiration, internal.ProductSortPrice:
+		default:
+			return internal.NewFieldError(internal.ErrQueryParam, "sort")
+		}
+	}
+
+	if q.Limit == 0 {
+		q.Limit = defaultPageLimit
+	}
+
+	return nil
 }
@@ -0,0 +1,355 @@
+package service_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"app/internal/service"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newService returns a service over an empty ProductMap, without audit log nor stock ledger
+func newService() (*service.ProductDefault, *repository.ProductMap) {
+	rp := repository.NewProductMap(nil, 0)
+	return service.NewProductDefault(rp, nil, nil, repository.NewReservationMap()), rp
+}
+
+// newProduct returns a valid product with the code
+func newProduct(code string) *internal.Product {
+	return &internal.Product{
+		Name:        "product",
+		Quantity:    10,
+		CodeValue:   code,
+		IsPublished: true,
+		Expiration:  internal.NewDate(2030, time.January, 1),
+		Price:       internal.Money{Amount: 150, Currency: "USD"},
+	}
+}
+
+// Tests for ProductDefault.Find
+func TestProductDefault_Find(t *testing.T) {
+	t.Run("success - the limit defaults to 20", func(t *testing.T) {
+		// arrange
+		sv, rp := newService()
+		for i := 0; i < 25; i++ {
+			require.NoError(t, rp.Save(&internal.Product{Name: "product", CodeValue: string(rune('a' + i))}))
+		}
+		query := internal.ProductQuery{}
+
+		// act
+		products, total, err := sv.Find(&query)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, 20, query.Limit)
+		require.Len(t, products, 20)
+		require.Equal(t, 25, total)
+	})
+
+	t.Run("success - the limit can be 100", func(t *testing.T) {
+		// arrange
+		sv, _ := newService()
+		query := internal.ProductQuery{Limit: 100}
+
+		// act
+		_, _, err := sv.Find(&query)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, 100, query.Limit)
+	})
+
+	priceMin := internal.Money{Amount: 200, Currency: "USD"}
+	priceMax := internal.Money{Amount: 100, Currency: "USD"}
+	quantityMin, quantityMax := 10, 5
+	cases := []struct {
+		name  string
+		query internal.ProductQuery
+		field string
+	}{
+		{name: "limit above 100", query: internal.ProductQuery{Limit: 101}, field: "limit"},
+		{name: "negative limit", query: internal.ProductQuery{Limit: -1}, field: "limit"},
+		{name: "negative offset", query: internal.ProductQuery{Offset: -1}, field: "offset"},
+		{name: "price min above price max", query: internal.ProductQuery{PriceMin: &priceMin, PriceMax: &priceMax}, field: "price_min"},
+		{name: "quantity min above quantity max", query: internal.ProductQuery{QuantityMin: &quantityMin, QuantityMax: &quantityMax}, field: "quantity_min"},
+		{name: "unknown sort field", query: internal.ProductQuery{Sort: []internal.ProductSort{{Field: "color"}}}, field: "sort"},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run("error - "+c.name, func(t *testing.T) {
+			// arrange
+			sv, _ := newService()
+
+			// act
+			_, _, err := sv.Find(&c.query)
+
+			// assert
+			require.ErrorIs(t, err, internal.ErrQueryParam)
+			var fieldErr *internal.FieldError
+			require.ErrorAs(t, err, &fieldErr)
+			require.Equal(t, c.field, fieldErr.Field)
+		})
+	}
+}
+
+// Tests for ProductDefault.SaveBatch
+func TestProductDefault_SaveBatch(t *testing.T) {
+	t.Run("success - atomic creates every product", func(t *testing.T) {
+		// arrange
+		sv, rp := newService()
+		products := []*internal.Product{newProduct("a"), newProduct("b")}
+
+		// act
+		results, err := sv.SaveBatch(context.Background(), products, internal.BatchModeAtomic)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, []internal.BatchResult{{Index: 0, ID: 1}, {Index: 1, ID: 2}}, results)
+		_, total, _ := rp.Find(internal.ProductQuery{})
+		require.Equal(t, 2, total)
+	})
+
+	t.Run("success - best effort creates the valid products and reports the others", func(t *testing.T) {
+		// arrange
+		sv, rp := newService()
+		require.NoError(t, rp.Save(newProduct("taken")))
+		invalid := newProduct("invalid")
+		invalid.Name = ""
+		products := []*internal.Product{newProduct("a"), invalid, newProduct("taken"), newProduct("b")}
+
+		// act
+		results, err := sv.SaveBatch(context.Background(), products, internal.BatchModeBestEffort)
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, results, 4)
+		require.NotZero(t, results[0].ID)
+		require.ErrorIs(t, results[1].Err, internal.ErrValidation)
+		require.ErrorIs(t, results[2].Err, internal.ErrProductCodeAlreadyExists)
+		require.NotZero(t, results[3].ID)
+		_, total, _ := rp.Find(internal.ProductQuery{})
+		require.Equal(t, 3, total)
+	})
+
+	t.Run("error - atomic with an invalid product creates nothing", func(t *testing.T) {
+		// arrange
+		sv, rp := newService()
+		invalid := newProduct("invalid")
+		invalid.Price = internal.Money{Amount: -1, Currency: "USD"}
+		products := []*internal.Product{newProduct("a"), invalid}
+
+		// act
+		results, err := sv.SaveBatch(context.Background(), products, internal.BatchModeAtomic)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrBatchRejected)
+		require.NoError(t, results[0].Err)
+		require.ErrorIs(t, results[1].Err, internal.ErrValidation)
+		_, total, _ := rp.Find(internal.ProductQuery{})
+		require.Equal(t, 0, total)
+	})
+
+	t.Run("error - atomic with a taken code rolls the saved products back", func(t *testing.T) {
+		// arrange
+		sv, rp := newService()
+		require.NoError(t, rp.Save(newProduct("taken")))
+		products := []*internal.Product{newProduct("a"), newProduct("b"), newProduct("taken")}
+
+		// act
+		results, err := sv.SaveBatch(context.Background(), products, internal.BatchModeAtomic)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrBatchRejected)
+		require.ErrorIs(t, results[2].Err, internal.ErrProductCodeAlreadyExists)
+		_, total, _ := rp.Find(internal.ProductQuery{})
+		require.Equal(t, 1, total)
+		_, err = rp.GetByCode("a")
+		require.ErrorIs(t, err, internal.ErrProductNotFound)
+	})
+
+	t.Run("error - batch size", func(t *testing.T) {
+		// arrange
+		sv, _ := newService()
+		products := make([]*internal.Product, internal.MaxBatchSize+1)
+		for i := range products {
+			products[i] = newProduct(fmt.Sprintf("code-%d", i))
+		}
+
+		// act
+		_, errEmpty := sv.SaveBatch(context.Background(), nil, internal.BatchModeAtomic)
+		_, errBig := sv.SaveBatch(context.Background(), products, internal.BatchModeAtomic)
+
+		// assert
+		require.ErrorIs(t, errEmpty, internal.ErrBatchSize)
+		require.ErrorIs(t, errBig, internal.ErrBatchSize)
+	})
+
+	t.Run("error - unknown mode", func(t *testing.T) {
+		// arrange
+		sv, _ := newService()
+
+		// act
+		_, err := sv.SaveBatch(context.Background(), []*internal.Product{newProduct("a")}, "all_or_nothing")
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrBatchMode)
+	})
+}
+
+// Tests for ProductDefault.ValidateBatch
+func TestProductDefault_ValidateBatch(t *testing.T) {
+	t.Run("success - the products are checked without being created", func(t *testing.T) {
+		// arrange
+		sv, rp := newService()
+		require.NoError(t, rp.Save(newProduct("taken")))
+		invalid := newProduct("invalid")
+		invalid.Name = ""
+		products := []*internal.Product{newProduct("a"), invalid, newProduct("taken"), newProduct("a")}
+
+		// act
+		results, err := sv.ValidateBatch(products)
+
+		// assert
+		require.NoError(t, err)
+		require.NoError(t, results[0].Err)
+		require.Zero(t, results[0].ID)
+		require.ErrorIs(t, results[1].Err, internal.ErrValidation)
+		require.ErrorIs(t, results[2].Err, internal.ErrProductCodeAlreadyExists)
+		require.ErrorIs(t, results[3].Err, internal.ErrProductCodeAlreadyExists)
+		_, total, _ := rp.Find(internal.ProductQuery{})
+		require.Equal(t, 1, total)
+	})
+}
+
+// Tests for ProductDefault.Validate
+func TestProductDefault_Validate(t *testing.T) {
+	t.Run("success - a product with a price", func(t *testing.T) {
+		// arrange
+		sv, _ := newService()
+
+		// act
+		err := sv.Validate(newProduct("code"))
+
+		// assert
+		require.NoError(t, err)
+	})
+
+	cases := []struct {
+		name     string
+		price    internal.Money
+		expected internal.ValidationErrors
+	}{
+		{name: "price missing", price: internal.Money{},
+			expected: internal.ValidationErrors{{Field: "price", Rule: internal.RuleRequired, Message: "field is required"}}},
+		{name: "price of 0", price: internal.Money{Amount: 0, Currency: "USD"},
+			expected: internal.ValidationErrors{{Field: "price", Rule: internal.RuleRequired, Message: "field is required"}}},
+		{name: "negative price", price: internal.Money{Amount: -1, Currency: "USD"},
+			expected: internal.ValidationErrors{{Field: "price", Rule: internal.RuleRange, Message: "must be greater than 0"}}},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run("error - "+c.name, func(t *testing.T) {
+			// arrange
+			sv, _ := newService()
+			product := newProduct("code")
+			product.Price = c.price
+
+			// act
+			err := sv.Validate(product)
+
+			// assert
+			var ve internal.ValidationErrors
+			require.ErrorAs(t, err, &ve)
+			require.Equal(t, c.expected, ve)
+		})
+	}
+}
+
+// Tests for ProductDefault.Revert
+func TestProductDefault_Revert(t *testing.T) {
+	// newVersionedService returns a service over a repository retaining the versions, with the product saved and renamed
+	newVersionedService := func(t *testing.T) (*service.ProductDefault, *internal.Product) {
+		rp := repository.NewProductVersioned(repository.NewProductMap(nil, 0), repository.NewProductVersionMap())
+		sv := service.NewProductDefault(rp, nil, nil, repository.NewReservationMap())
+		product := newProduct("code")
+		require.NoError(t, sv.Save(context.Background(), product))
+		product.Name = "renamed"
+		require.NoError(t, sv.Update(context.Background(), product))
+		return sv, product
+	}
+
+	t.Run("success - the content of the version is written as a new version", func(t *testing.T) {
+		// arrange
+		sv, product := newVersionedService(t)
+
+		// act
+		reverted, err := sv.Revert(context.Background(), product.ID, 1, 0)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, "product", reverted.Name)
+		require.Equal(t, 3, reverted.Version)
+	})
+
+	t.Run("success - a conditional revert over the current version", func(t *testing.T) {
+		// arrange
+		sv, product := newVersionedService(t)
+
+		// act
+		reverted, err := sv.Revert(context.Background(), product.ID, 1, 2)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, 3, reverted.Version)
+	})
+
+	t.Run("success - the current quantity is kept", func(t *testing.T) {
+		// arrange
+		rp := repository.NewProductVersioned(repository.NewProductMap(nil, 0), repository.NewProductVersionMap())
+		sv := service.NewProductDefault(rp, nil, repository.NewStockLedgerMap(), repository.NewReservationMap())
+		product := newProduct("code")
+		require.NoError(t, sv.Save(context.Background(), product))
+		reservation := internal.Reservation{ProductID: product.ID, Quantity: 9}
+		require.NoError(t, sv.Reserve(context.Background(), &reservation, 0))
+		_, err := sv.ConfirmReservation(context.Background(), product.ID, reservation.ID)
+		require.NoError(t, err)
+
+		// act
+		reverted, err := sv.Revert(context.Background(), product.ID, 1, 0)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, 1, reverted.Quantity)
+		movements, err := sv.StockMovements(product.ID)
+		require.NoError(t, err)
+		require.Len(t, movements, 1)
+	})
+
+	t.Run("error - a conditional revert over a version that is not the current one", func(t *testing.T) {
+		// arrange
+		sv, product := newVersionedService(t)
+
+		// act
+		_, err := sv.Revert(context.Background(), product.ID, 2, 1)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrProductVersionConflict)
+		current, _ := sv.GetById(product.ID)
+		require.Equal(t, 2, current.Version)
+	})
+
+	t.Run("error - version not found", func(t *testing.T) {
+		// arrange
+		sv, product := newVersionedService(t)
+
+		// act
+		_, err := sv.Revert(context.Background(), product.ID, 9, 0)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrProductVersionNotFound)
+	})
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"app/internal"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultReservationTTL is how long a reservation holds stock when no ttl is given
+const DefaultReservationTTL = 15 * time.Minute
+
+// reservationReason is the reason of the stock movements of the confirmed reservations
+const reservationReason = "reservation"
+
+func (pd *ProductDefault) Reserve(ctx context.Context, reservation *internal.Reservation, ttl time.Duration) error {
+	var ve internal.ValidationErrors
+	if reservation.Quantity <= 0 {
+		ve.Add("quantity", internal.RuleRange, "must be greater than 0")
+	}
+	if ttl < 0 || ttl > internal.MaxReservationTTL {
+		ve.Add("ttl_seconds", internal.RuleRange, fmt.Sprintf("must be between 1 and %d", int(internal.MaxReservationTTL.Seconds())))
+	}
+	if utf8.RuneCountInString(reservation.Reference) > maxStockTextLength {
+		ve.Add("reference", internal.RuleLength, "must be at most 100 long")
+	}
+	if err := ve.Err(); err != nil {
+		return err
+	}
+
+	if ttl == 0 {
+		ttl = DefaultReservationTTL
+	}
+
+	pd.stock.Lock()
+	defer pd.stock.Unlock()
+
+	now := time.Now().UTC()
+
+	level, err := pd.stockLevel(reservation.ProductID, now)
+	if err != nil {
+		return err
+	}
+	if level.Available < reservation.Quantity {
+		return internal.NewFieldError(internal.ErrStockInsufficient, "quantity")
+	}
+
+	reservation.Status = internal.ReservationActive
+	reservation.Actor = internal.ActorFromContext(ctx)
+	reservation.CreatedAt = now
+	reservation.ExpiresAt = now.Add(ttl)
+
+	return pd.rs.Save(reservation)
+}
+
+func (pd *ProductDefault) ConfirmReservation(ctx context.Context, productID int, id int) (internal.Reservation, error) {
+	pd.stock.Lock()
+	defer pd.stock.Unlock()
+
+	reservation, err := pd.reservation(productID, id)
+	if err != nil {
+		return internal.Reservation{}, err
+	}
+
+	now := time.Now().UTC()
+	if !reservation.IsActive(now) {
+		return internal.Reservation{}, internal.NewFieldError(internal.ErrReservationNotActive, "status")
+	}
+
+	// the reservation is active and the lock is held, so it is closed right after the stock is taken
+	movement := internal.StockMovement{
+		ProductID: productID,
+		Delta:     -reservation.Quantity,
+		Reason:    reservationReason,
+		Reference: fmt.Sprintf("reservation %d", reservation.ID),
+	}
+	if _, err := pd.moveStock(ctx, &movement); err != nil {
+		return internal.Reservation{}, err
+	}
+
+	confirmed, err := pd.rs.Close(id, internal.ReservationConfirmed, now)
+	if err != nil {
+		// the reservation still holds the stock, which is moved back so it is not counted twice
+		back := internal.StockMovement{
+			ProductID: productID,
+			Delta:     reservation.Quantity,
+			Reason:    reservationReason,
+			Reference: fmt.Sprintf("reservation %d not confirmed", reservation.ID),
+		}
+		if _, errBack := pd.moveStock(ctx, &back); errBack != nil {
+			slog.Error("reservations: stock taken but reservation not confirmed", "product_id", productID, "reservation_id", id, "error", errBack)
+			err = errors.Join(err, fmt.Errorf("moving the stock back: %w", errBack))
+		}
+		return internal.Reservation{}, fmt.Errorf("reservations: confirming reservation %d: %w", id, err)
+	}
+
+	return confirmed, nil
+}
+
+func (pd *ProductDefault) CancelReservation(ctx context.Context, productID int, id int) (internal.Reservation, error) {
+	pd.stock.Lock()
+	defer pd.stock.Unlock()
+
+	if _, err := pd.reservation(productID, id); err != nil {
+		return internal.Reservation{}, err
+	}
+
+	reservation, err := pd.rs.Close(id, internal.ReservationCancelled, time.Now().UTC())
+	if err != nil {
+		if errors.Is(err, internal.ErrReservationNotActive) {
+			err = internal.NewFieldError(internal.ErrReservationNotActive, "status")
+		}
+		return internal.Reservation{}, err
+	}
+
+	return reservation, nil
+}
+
+func (pd *ProductDefault) Reservations(productID int) ([]internal.Reservation, error) {
+	reservations, err := pd.rs.Reservations(productID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(reservations) == 0 {
+		if err := pd.productExists(productID); err != nil {
+			return nil, err
+		}
+	}
+
+	// reservations past their expiration are expired even if the sweeper did not close them yet
+	now := time.Now()
+	for i, reservation := range reservations {
+		if reservation.Status == internal.ReservationActive && !reservation.IsActive(now) {
+			reservations[i].Status = internal.ReservationExpired
+			reservations[i].UpdatedAt = reservation.ExpiresAt
+		}
+	}
+
+	return reservations, nil
+}
+
+func (pd *ProductDefault) ExpireReservations(ctx context.Context) ([]internal.Reservation, error) {
+	pd.stock.Lock()
+	defer pd.stock.Unlock()
+
+	return pd.rs.Expire(time.Now().UTC())
+}
+
+// reservation returns the reservation of the product, reservations of other products being not found
+func (pd *ProductDefault) reservation(productID int, id int) (internal.Reservation, error) {
+	reservation, err := pd.rs.GetById(id)
+	if err == nil && reservation.ProductID != productID {
+		err = internal.ErrReservationNotFound
+	}
+	if err != nil {
+		if errors.Is(err, internal.ErrReservationNotFound) {
+			err = internal.NewFieldError(internal.ErrReservationNotFound, "reservation_id")
+		}
+		return internal.Reservation{}, err
+	}
+
+	return reservation, nil
+}
@@ -0,0 +1,262 @@
+package service_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"app/internal/service"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newReservedProduct returns a service over a saved product with 10 in stock
+func newReservedProduct(t *testing.T) (*service.ProductDefault, *internal.Product) {
+	t.Helper()
+
+	sv, _ := newService()
+	product := newProduct("code")
+	require.NoError(t, sv.Save(context.Background(), product))
+	return sv, product
+}
+
+// Tests for the reservations of ProductDefault
+func TestProductDefault_Reserve(t *testing.T) {
+	t.Run("success - the available stock is the quantity minus the active reservations", func(t *testing.T) {
+		// arrange
+		sv, product := newReservedProduct(t)
+		first := internal.Reservation{ProductID: product.ID, Quantity: 3}
+		second := internal.Reservation{ProductID: product.ID, Quantity: 2}
+		cancelled := internal.Reservation{ProductID: product.ID, Quantity: 4}
+		require.NoError(t, sv.Reserve(context.Background(), &first, 0))
+		require.NoError(t, sv.Reserve(context.Background(), &second, time.Hour))
+		require.NoError(t, sv.Reserve(context.Background(), &cancelled, 0))
+		_, err := sv.CancelReservation(context.Background(), product.ID, cancelled.ID)
+		require.NoError(t, err)
+
+		// act
+		level, err := sv.Stock(product.ID)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, internal.StockLevel{ProductID: product.ID, Quantity: 10, Reserved: 5, Available: 5}, level)
+		require.Equal(t, internal.ReservationActive, first.Status)
+		require.Equal(t, first.CreatedAt.Add(service.DefaultReservationTTL), first.ExpiresAt)
+	})
+
+	t.Run("success - the reservations are listed oldest first", func(t *testing.T) {
+		// arrange
+		sv, product := newReservedProduct(t)
+		first := internal.Reservation{ProductID: product.ID, Quantity: 1}
+		second := internal.Reservation{ProductID: product.ID, Quantity: 2}
+		require.NoError(t, sv.Reserve(context.Background(), &first, 0))
+		require.NoError(t, sv.Reserve(context.Background(), &second, 0))
+
+		// act
+		reservations, err := sv.Reservations(product.ID)
+
+		// assert
+		require.NoError(t, err)
+		require.Len(t, reservations, 2)
+		require.Equal(t, first.ID, reservations[0].ID)
+		require.Equal(t, second.ID, reservations[1].ID)
+	})
+
+	t.Run("error - not enough available stock", func(t *testing.T) {
+		// arrange
+		sv, product := newReservedProduct(t)
+		held := internal.Reservation{ProductID: product.ID, Quantity: 8}
+		require.NoError(t, sv.Reserve(context.Background(), &held, 0))
+
+		// act
+		reservation := internal.Reservation{ProductID: product.ID, Quantity: 3}
+		err := sv.Reserve(context.Background(), &reservation, 0)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrStockInsufficient)
+		require.Zero(t, reservation.ID)
+		level, _ := sv.Stock(product.ID)
+		require.Equal(t, 2, level.Available)
+	})
+
+	t.Run("error - a decrement can not take the reserved stock", func(t *testing.T) {
+		// arrange
+		sv, product := newReservedProduct(t)
+		held := internal.Reservation{ProductID: product.ID, Quantity: 8}
+		require.NoError(t, sv.Reserve(context.Background(), &held, 0))
+
+		// act
+		_, err := sv.MoveStock(context.Background(), &internal.StockMovement{ProductID: product.ID, Delta: -3, Reason: "sale"})
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrStockInsufficient)
+	})
+
+	cases := []struct {
+		name        string
+		reservation internal.Reservation
+		ttl         time.Duration
+		field       string
+	}{
+		{name: "zero quantity", reservation: internal.Reservation{Quantity: 0}, field: "quantity"},
+		{name: "ttl above the maximum", reservation: internal.Reservation{Quantity: 1}, ttl: 25 * time.Hour, field: "ttl_seconds"},
+		{name: "negative ttl", reservation: internal.Reservation{Quantity: 1}, ttl: -time.Second, field: "ttl_seconds"},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run("error - "+c.name, func(t *testing.T) {
+			// arrange
+			sv, product := newReservedProduct(t)
+			c.reservation.ProductID = product.ID
+
+			// act
+			err := sv.Reserve(context.Background(), &c.reservation, c.ttl)
+
+			// assert
+			var ve internal.ValidationErrors
+			require.ErrorAs(t, err, &ve)
+			require.Equal(t, c.field, ve[0].Field)
+		})
+	}
+
+	t.Run("error - product not found", func(t *testing.T) {
+		// arrange
+		sv, _ := newService()
+
+		// act
+		err := sv.Reserve(context.Background(), &internal.Reservation{ProductID: 1, Quantity: 1}, 0)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrProductNotFound)
+	})
+}
+
+// failingReservations is a reservation store failing to close the reservations
+type failingReservations struct {
+	*repository.ReservationMap
+}
+
+func (fr failingReservations) Close(id int, status internal.ReservationStatus, at time.Time) (internal.Reservation, error) {
+	return internal.Reservation{}, errors.New("reservations unavailable")
+}
+
+// Tests for ProductDefault.ConfirmReservation
+func TestProductDefault_ConfirmReservation(t *testing.T) {
+	t.Run("success - the stock is taken exactly once", func(t *testing.T) {
+		// arrange
+		sv, product := newReservedProduct(t)
+		reservation := internal.Reservation{ProductID: product.ID, Quantity: 4}
+		require.NoError(t, sv.Reserve(context.Background(), &reservation, 0))
+
+		// act
+		confirmed, err := sv.ConfirmReservation(context.Background(), product.ID, reservation.ID)
+		_, errAgain := sv.ConfirmReservation(context.Background(), product.ID, reservation.ID)
+		_, errCancel := sv.CancelReservation(context.Background(), product.ID, reservation.ID)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, internal.ReservationConfirmed, confirmed.Status)
+		require.ErrorIs(t, errAgain, internal.ErrReservationNotActive)
+		require.ErrorIs(t, errCancel, internal.ErrReservationNotActive)
+		level, _ := sv.Stock(product.ID)
+		require.Equal(t, internal.StockLevel{ProductID: product.ID, Quantity: 6, Reserved: 0, Available: 6}, level)
+	})
+
+	t.Run("error - a reservation that can not be closed gives its stock back", func(t *testing.T) {
+		// arrange
+		sv := service.NewProductDefault(repository.NewProductMap(nil, 0), nil, repository.NewStockLedgerMap(),
+			failingReservations{repository.NewReservationMap()})
+		product := newProduct("code")
+		require.NoError(t, sv.Save(context.Background(), product))
+		reservation := internal.Reservation{ProductID: product.ID, Quantity: 4}
+		require.NoError(t, sv.Reserve(context.Background(), &reservation, 0))
+
+		// act
+		_, err := sv.ConfirmReservation(context.Background(), product.ID, reservation.ID)
+
+		// assert
+		require.Error(t, err)
+		level, _ := sv.Stock(product.ID)
+		require.Equal(t, internal.StockLevel{ProductID: product.ID, Quantity: 10, Reserved: 4, Available: 6}, level)
+		movements, _ := sv.StockMovements(product.ID)
+		require.Len(t, movements, 2)
+		require.Equal(t, -4, movements[0].Delta)
+		require.Equal(t, 4, movements[1].Delta)
+	})
+
+	t.Run("error - the reservation of another product is not found", func(t *testing.T) {
+		// arrange
+		sv, product := newReservedProduct(t)
+		other := newProduct("other")
+		require.NoError(t, sv.Save(context.Background(), other))
+		reservation := internal.Reservation{ProductID: product.ID, Quantity: 4}
+		require.NoError(t, sv.Reserve(context.Background(), &reservation, 0))
+
+		// act
+		_, err := sv.ConfirmReservation(context.Background(), other.ID, reservation.ID)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrReservationNotFound)
+		level, _ := sv.Stock(product.ID)
+		require.Equal(t, 10, level.Quantity)
+	})
+
+	t.Run("error - an expired reservation can not be confirmed", func(t *testing.T) {
+		// arrange
+		sv, product := newReservedProduct(t)
+		reservation := internal.Reservation{ProductID: product.ID, Quantity: 4}
+		require.NoError(t, sv.Reserve(context.Background(), &reservation, time.Millisecond))
+		time.Sleep(2 * time.Millisecond)
+
+		// act
+		_, err := sv.ConfirmReservation(context.Background(), product.ID, reservation.ID)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrReservationNotActive)
+		level, _ := sv.Stock(product.ID)
+		require.Equal(t, 10, level.Quantity)
+	})
+}
+
+// Tests for ProductDefault.ExpireReservations
+func TestProductDefault_ExpireReservations(t *testing.T) {
+	t.Run("success - expired reservations release their stock", func(t *testing.T) {
+		// arrange
+		sv, product := newReservedProduct(t)
+		expiring := internal.Reservation{ProductID: product.ID, Quantity: 6}
+		held := internal.Reservation{ProductID: product.ID, Quantity: 1}
+		require.NoError(t, sv.Reserve(context.Background(), &expiring, time.Millisecond))
+		require.NoError(t, sv.Reserve(context.Background(), &held, time.Hour))
+		time.Sleep(2 * time.Millisecond)
+
+		// act
+		levelBefore, _ := sv.Stock(product.ID)
+		listed, _ := sv.Reservations(product.ID)
+		expired, err := sv.ExpireReservations(context.Background())
+
+		// assert
+		require.Equal(t, 9, levelBefore.Available)
+		require.Equal(t, internal.ReservationExpired, listed[0].Status)
+		require.NoError(t, err)
+		require.Len(t, expired, 1)
+		require.Equal(t, expiring.ID, expired[0].ID)
+		require.Equal(t, internal.ReservationExpired, expired[0].Status)
+		levelAfter, _ := sv.Stock(product.ID)
+		require.Equal(t, internal.StockLevel{ProductID: product.ID, Quantity: 10, Reserved: 1, Available: 9}, levelAfter)
+	})
+}
+
+// Tests for NewProductDefault
+func TestNewProductDefault(t *testing.T) {
+	t.Run("error - the reservation store is required", func(t *testing.T) {
+		// act
+		build := func() {
+			service.NewProductDefault(repository.NewProductMap(nil, 0), nil, nil, nil)
+		}
+
+		// assert
+		require.Panics(t, build)
+	})
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"app/internal"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+)
+
+// maxStockTextLength is the longest reason or reference a stock movement can have
+const maxStockTextLength = 100
+
+func (pd *ProductDefault) MoveStock(ctx context.Context, movement *internal.StockMovement) (internal.Product, error) {
+	var ve internal.ValidationErrors
+	if movement.Delta == 0 {
+		ve.Add("quantity", internal.RuleRange, "must be greater than 0")
+	}
+	if movement.Reason == "" {
+		ve.Add("reason", internal.RuleRequired, "field is required")
+	}
+	if utf8.RuneCountInString(movement.Reason) > maxStockTextLength {
+		ve.Add("reason", internal.RuleLength, "must be at most 100 long")
+	}
+	if utf8.RuneCountInString(movement.Reference) > maxStockTextLength {
+		ve.Add("reference", internal.RuleLength, "must be at most 100 long")
+	}
+	if err := ve.Err(); err != nil {
+		return internal.Product{}, err
+	}
+
+	pd.stock.Lock()
+	defer pd.stock.Unlock()
+
+	// a decrement can not take the stock held by the reservations
+	if movement.Delta < 0 {
+		level, err := pd.stockLevel(movement.ProductID, time.Now())
+		if err != nil {
+			return internal.Product{}, err
+		}
+		if level.Available+movement.Delta < 0 {
+			return internal.Product{}, internal.NewFieldError(internal.ErrStockInsufficient, "quantity")
+		}
+	}
+
+	return pd.moveStock(ctx, movement)
+}
+
+// moveStock applies the valid movement to the product and records it
+func (pd *ProductDefault) moveStock(ctx context.Context, movement *internal.StockMovement) (internal.Product, error) {
+	product, err := pd.rp.AdjustStock(movement.ProductID, movement.Delta)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, internal.ErrProductNotFound):
+			err = internal.NewFieldError(internal.ErrProductNotFound, "id")
+		case errors.Is(err, internal.ErrStockInsufficient):
+			err = internal.NewFieldError(internal.ErrStockInsufficient, "quantity")
+		}
+		return internal.Product{}, err
+	}
+
+	movement.Quantity = product.Quantity
+	movement.Actor = internal.ActorFromContext(ctx)
+	movement.Timestamp = time.Now().UTC()
+
+	// the ledger must account for every movement: one that can not be recorded is moved back
+	if pd.sl != nil {
+		if err := pd.sl.Record(movement); err != nil {
+			if _, errBack := pd.rp.AdjustStock(movement.ProductID, -movement.Delta); errBack != nil {
+				err = errors.Join(err, fmt.Errorf("moving the stock back: %w", errBack))
+			}
+			return internal.Product{}, fmt.Errorf("stock ledger: recording movement of product %d: %w", product.ID, err)
+		}
+	}
+	pd.audit(ctx, internal.AuditOperationUpdate, product.ID, []internal.AuditChange{
+		{Field: "quantity", Before: product.Quantity - movement.Delta, After: product.Quantity},
+	})
+
+	return product, nil
+}
+
+func (pd *ProductDefault) StockMovements(id int) ([]internal.StockMovement, error) {
+	movements := []internal.StockMovement{}
+	if pd.sl != nil {
+		var err error
+		if movements, err = pd.sl.Movements(id); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(movements) == 0 {
+		if err := pd.productExists(id); err != nil {
+			return nil, err
+		}
+	}
+
+	return movements, nil
+}
+
+func (pd *ProductDefault) Stock(id int) (internal.StockLevel, error) {
+	return pd.stockLevel(id, time.Now())
+}
+
+// stockLevel returns the stock of the product with the reservations active at the time
+func (pd *ProductDefault) stockLevel(id int, at time.Time) (internal.StockLevel, error) {
+	product, err := pd.rp.GetById(id)
+	if err != nil {
+		if errors.Is(err, internal.ErrProductNotFound) {
+			err = internal.NewFieldError(internal.ErrProductNotFound, "id")
+		}
+		return internal.StockLevel{}, err
+	}
+
+	reserved, err := pd.rs.Reserved(id, at)
+	if err != nil {
+		return internal.StockLevel{}, err
+	}
+
+	return internal.StockLevel{
+		ProductID: id,
+		Quantity:  product.Quantity,
+		Reserved:  reserved,
+		Available: max(product.Quantity-reserved, 0),
+	}, nil
+}
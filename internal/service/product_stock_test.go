@@ -0,0 +1,125 @@
+package service_test
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"app/internal/service"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// failingLedger is a stock ledger failing to record the movements
+type failingLedger struct {
+	*repository.StockLedgerMap
+}
+
+func (fl failingLedger) Record(movement *internal.StockMovement) error {
+	return errors.New("ledger unavailable")
+}
+
+// Tests for ProductDefault.MoveStock
+func TestProductDefault_MoveStock(t *testing.T) {
+	t.Run("success - the movement is recorded with the quantity after it", func(t *testing.T) {
+		// arrange
+		rp := repository.NewProductMap(nil, 0)
+		sl := repository.NewStockLedgerMap()
+		sv := service.NewProductDefault(rp, nil, sl, repository.NewReservationMap())
+		product := newProduct("code")
+		require.NoError(t, sv.Save(context.Background(), product))
+		movement := internal.StockMovement{ProductID: product.ID, Delta: -4, Reason: "sale", Reference: "order-1"}
+		ctx := internal.ContextWithActor(context.Background(), "alice")
+
+		// act
+		moved, err := sv.MoveStock(ctx, &movement)
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, 6, moved.Quantity)
+		movements, err := sv.StockMovements(product.ID)
+		require.NoError(t, err)
+		require.Len(t, movements, 1)
+		require.Equal(t, -4, movements[0].Delta)
+		require.Equal(t, 6, movements[0].Quantity)
+		require.Equal(t, "alice", movements[0].Actor)
+		require.Equal(t, "order-1", movements[0].Reference)
+	})
+
+	t.Run("error - a movement the ledger can not record is moved back", func(t *testing.T) {
+		// arrange
+		rp := repository.NewProductMap(nil, 0)
+		sv := service.NewProductDefault(rp, nil, failingLedger{repository.NewStockLedgerMap()}, repository.NewReservationMap())
+		product := newProduct("code")
+		require.NoError(t, sv.Save(context.Background(), product))
+		movement := internal.StockMovement{ProductID: product.ID, Delta: -4, Reason: "sale"}
+
+		// act
+		_, err := sv.MoveStock(context.Background(), &movement)
+
+		// assert
+		require.Error(t, err)
+		found, _ := rp.GetById(product.ID)
+		require.Equal(t, 10, found.Quantity)
+	})
+
+	t.Run("error - the stock can not be negative", func(t *testing.T) {
+		// arrange
+		rp := repository.NewProductMap(nil, 0)
+		sl := repository.NewStockLedgerMap()
+		sv := service.NewProductDefault(rp, nil, sl, repository.NewReservationMap())
+		product := newProduct("code")
+		require.NoError(t, sv.Save(context.Background(), product))
+		movement := internal.StockMovement{ProductID: product.ID, Delta: -11, Reason: "sale"}
+
+		// act
+		_, err := sv.MoveStock(context.Background(), &movement)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrStockInsufficient)
+		movements, _ := sv.StockMovements(product.ID)
+		require.Empty(t, movements)
+	})
+}
+
+// Tests for ProductDefault.StockMovements
+func TestProductDefault_StockMovements(t *testing.T) {
+	t.Run("success - a product without movements has none", func(t *testing.T) {
+		// arrange
+		sv := service.NewProductDefault(repository.NewProductMap(nil, 0), nil, repository.NewStockLedgerMap(),
+			repository.NewReservationMap())
+		product := newProduct("code")
+		require.NoError(t, sv.Save(context.Background(), product))
+
+		// act
+		movements, err := sv.StockMovements(product.ID)
+
+		// assert
+		require.NoError(t, err)
+		require.Empty(t, movements)
+	})
+
+	t.Run("error - product not found", func(t *testing.T) {
+		// arrange
+		sv := service.NewProductDefault(repository.NewProductMap(nil, 0), nil, repository.NewStockLedgerMap(),
+			repository.NewReservationMap())
+
+		// act
+		_, err := sv.StockMovements(1)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrProductNotFound)
+	})
+
+	t.Run("error - product not found without ledger", func(t *testing.T) {
+		// arrange
+		sv, _ := newService()
+
+		// act
+		_, err := sv.StockMovements(1)
+
+		// assert
+		require.ErrorIs(t, err, internal.ErrProductNotFound)
+	})
+}
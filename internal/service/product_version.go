@@ -0,0 +1,70 @@
+package service
+
+import (
+	"app/internal"
+	"context"
+	"errors"
+)
+
+func (pd *ProductDefault) GetVersion(id int, version int) (internal.Product, error) {
+	// versions are only retained by the repositories decorated to do so
+	vr, ok := pd.rp.(internal.ProductVersioner)
+	if !ok {
+		return internal.Product{}, internal.NewFieldError(internal.ErrProductVersionNotFound, "version")
+	}
+
+	product, err := vr.GetVersion(id, version)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, internal.ErrProductVersionNotFound):
+			err = internal.NewFieldError(internal.ErrProductVersionNotFound, "version")
+		}
+	}
+
+	return product, err
+}
+
+func (pd *ProductDefault) Revert(ctx context.Context, id int, version int, current int) (internal.Product, error) {
+	if _, err := pd.GetById(id); err != nil {
+		return internal.Product{}, err
+	}
+
+	old, err := pd.GetVersion(id, version)
+	if err != nil {
+		return internal.Product{}, err
+	}
+
+	for {
+		latest, err := pd.GetById(id)
+		if err != nil {
+			return internal.Product{}, err
+		}
+		if current != 0 && latest.Version != current {
+			return internal.Product{}, internal.ErrProductVersionConflict
+		}
+
+		// the content of the old version is written over the version read, keeping its quantity:
+		// the stock only moves through the ledger, where the reservations are accounted for
+		product := internal.Product{
+			ID:          id,
+			Name:        old.Name,
+			Quantity:    latest.Quantity,
+			CodeValue:   old.CodeValue,
+			IsPublished: old.IsPublished,
+			Expiration:  old.Expiration,
+			Price:       old.Price,
+			Version:     latest.Version,
+		}
+
+		err = pd.Update(ctx, &product)
+		switch {
+		case err == nil:
+			return product, nil
+		// the stock moved in between, the revert is written again over the new quantity
+		case errors.Is(err, internal.ErrProductVersionConflict) && current == 0:
+			continue
+		}
+		return internal.Product{}, err
+	}
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"app/internal"
+	"context"
+	"log/slog"
+	"time"
+)
+
+// ReservationSweeper periodically releases the stock held by the reservations past their expiration.
+// Expired reservations already stop holding stock when they expire, sweeping closes them for good.
+type ReservationSweeper struct {
+	sv internal.ProductService
+	// interval is the time between two sweeps
+	interval time.Duration
+}
+
+func NewReservationSweeper(sv internal.ProductService, interval time.Duration) *ReservationSweeper {
+	return &ReservationSweeper{
+		sv:       sv,
+		interval: interval,
+	}
+}
+
+// Run sweeps the expired reservations every interval until the context is done
+func (s *ReservationSweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.sweep(ctx)
+		}
+	}
+}
+
+// sweep expires the reservations past their expiration, failures being logged as the next sweep retries them
+func (s *ReservationSweeper) sweep(ctx context.Context) {
+	expired, err := s.sv.ExpireReservations(ctx)
+	if err != nil {
+		slog.Warn("reservation sweeper: expiring the reservations", "error", err)
+		return
+	}
+
+	if len(expired) > 0 {
+		slog.Info("reservation sweeper: released the expired reservations", "count", len(expired))
+	}
+}
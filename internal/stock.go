@@ -0,0 +1,42 @@
+package internal
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrStockInsufficient is returned when a stock movement would leave a negative quantity
+var ErrStockInsufficient = errors.New("not enough stock")
+
+// StockMovement is a change of the quantity of a product, recorded in its ledger
+type StockMovement struct {
+	ID        int
+	ProductID int
+	// Delta is the quantity added to the stock, negative when it is taken from it
+	Delta int
+	// Quantity is the quantity of the product after the movement
+	Quantity int
+	// Reason explains the movement, e.g. "purchase" or "sale"
+	Reason string
+	// Reference identifies the document behind the movement, e.g. an order number
+	Reference string
+	Actor     string
+	Timestamp time.Time
+}
+
+// StockLevel is the stock of a product split between the quantity held by its active reservations and the available one
+type StockLevel struct {
+	ProductID int
+	Quantity  int
+	Reserved  int
+	// Available is the quantity minus the reserved one, zero when the product was updated below its reservations
+	Available int
+}
+
+// StockLedger stores the stock movements of the products
+type StockLedger interface {
+	// Record appends the movement to the ledger, setting its id
+	Record(movement *StockMovement) error
+	// Movements returns the movements of the product, oldest first
+	Movements(productID int) ([]StockMovement, error)
+}
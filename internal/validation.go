@@ -0,0 +1,53 @@
+package internal
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrValidation is matched by ValidationErrors
+var ErrValidation = errors.New("validation failed")
+
+// rules a field can violate
+const (
+	RuleRequired = "required"
+	RuleFormat   = "format"
+	RuleRange    = "range"
+	RuleLength   = "length"
+)
+
+// ValidationError is a violation of a rule by a field
+type ValidationError struct {
+	Field   string
+	Rule    string
+	Message string
+}
+
+// ValidationErrors are all the violations found validating a value.
+// It matches ErrValidation with errors.Is.
+type ValidationErrors []ValidationError
+
+// Add appends a violation of rule by field
+func (ve *ValidationErrors) Add(field, rule, message string) {
+	*ve = append(*ve, ValidationError{Field: field, Rule: rule, Message: message})
+}
+
+// Err returns the violations as an error, nil when there are none
+func (ve ValidationErrors) Err() error {
+	if len(ve) == 0 {
+		return nil
+	}
+	return ve
+}
+
+func (ve ValidationErrors) Error() string {
+	messages := make([]string, 0, len(ve))
+	for _, e := range ve {
+		messages = append(messages, e.Field+": "+e.Message)
+	}
+	return ErrValidation.Error() + ": " + strings.Join(messages, "; ")
+}
+
+func (ve ValidationErrors) Is(target error) bool {
+	return target == ErrValidation
+}
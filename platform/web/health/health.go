@@ -0,0 +1,153 @@
+// Package health runs named checks, e.g. the reachability of a database, and serves their report as json.
+package health
+
+import (
+	"app/platform/web/response"
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// status of a check and of a report
+const (
+	StatusOK      = "ok"
+	StatusFailing = "failing"
+)
+
+// Check reports whether a dependency works, returning the reason when it does not
+type Check func(ctx context.Context) error
+
+// CheckResult is the result of a check
+type CheckResult struct {
+	Name    string  `json:"name"`
+	Status  string  `json:"status"`
+	Latency float64 `json:"latency_ms"`
+	Error   string  `json:"error,omitempty"`
+}
+
+// Report is the result of every check, failing when any check fails
+type Report struct {
+	Status string        `json:"status"`
+	Checks []CheckResult `json:"checks"`
+}
+
+// namedCheck is a registered check
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Checker runs the registered checks concurrently, each one being bounded by the timeout
+type Checker struct {
+	timeout time.Duration
+
+	mu     sync.RWMutex
+	checks []namedCheck
+	// down is the reason every run fails, empty while the checker is up
+	down string
+}
+
+// New returns a checker without checks, whose report is ok until checks are registered
+func New(timeout time.Duration) *Checker {
+	return &Checker{
+		timeout: timeout,
+	}
+}
+
+// Register adds the check, reported under the name in the order of registration
+func (c *Checker) Register(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+// Down makes the later runs fail for the reason, e.g. while the server shuts down
+func (c *Checker) Down(reason string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.down = reason
+}
+
+// Run runs the checks and reports their results
+func (c *Checker) Run(ctx context.Context) Report {
+	c.mu.RLock()
+	checks := c.checks
+	down := c.down
+	c.mu.RUnlock()
+
+	report := Report{
+		Status: StatusOK,
+		Checks: make([]CheckResult, len(checks)),
+	}
+
+	var wg sync.WaitGroup
+	for i, nc := range checks {
+		wg.Add(1)
+		go func(i int, nc namedCheck) {
+			defer wg.Done()
+			report.Checks[i] = c.run(ctx, nc)
+		}(i, nc)
+	}
+	wg.Wait()
+
+	if down != "" {
+		report.Checks = append([]CheckResult{{Name: "down", Status: StatusFailing, Error: down}}, report.Checks...)
+	}
+	for _, result := range report.Checks {
+		if result.Status != StatusOK {
+			report.Status = StatusFailing
+		}
+	}
+
+	return report
+}
+
+// run runs the check within the timeout, a check overrunning it failing with context.DeadlineExceeded
+func (c *Checker) run(ctx context.Context, nc namedCheck) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	errc := make(chan error, 1)
+	go func() {
+		errc <- nc.check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-errc:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := CheckResult{
+		Name:    nc.name,
+		Status:  StatusOK,
+		Latency: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = StatusFailing
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+// Handler runs the checks on every request, responding 200 with the report when it is ok and 503 otherwise
+func (c *Checker) Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := c.Run(r.Context())
+
+		status := http.StatusOK
+		if report.Status != StatusOK {
+			status = http.StatusServiceUnavailable
+		}
+
+		// probes must not see a cached result
+		w.Header().Set("Cache-Control", "no-store")
+		response.JSON(w, status, report)
+	}
+}
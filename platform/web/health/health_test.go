@@ -0,0 +1,126 @@
+package health_test
+
+import (
+	"app/platform/web/health"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for Checker
+func TestChecker_Run(t *testing.T) {
+	ok := func(ctx context.Context) error { return nil }
+
+	t.Run("success - every check passes", func(t *testing.T) {
+		// arrange
+		c := health.New(time.Second)
+		c.Register("first", ok)
+		c.Register("second", ok)
+
+		// act
+		report := c.Run(context.Background())
+
+		// assert
+		require.Equal(t, health.StatusOK, report.Status)
+		require.Len(t, report.Checks, 2)
+		require.Equal(t, "first", report.Checks[0].Name)
+		require.Equal(t, "second", report.Checks[1].Name)
+		require.Equal(t, health.StatusOK, report.Checks[1].Status)
+	})
+
+	t.Run("success - no checks", func(t *testing.T) {
+		// act
+		report := health.New(time.Second).Run(context.Background())
+
+		// assert
+		require.Equal(t, health.StatusOK, report.Status)
+		require.Empty(t, report.Checks)
+	})
+
+	t.Run("error - a failing check fails the report", func(t *testing.T) {
+		// arrange
+		c := health.New(time.Second)
+		c.Register("ok", ok)
+		c.Register("broken", func(ctx context.Context) error { return errors.New("unreachable") })
+
+		// act
+		report := c.Run(context.Background())
+
+		// assert
+		require.Equal(t, health.StatusFailing, report.Status)
+		require.Equal(t, health.StatusOK, report.Checks[0].Status)
+		require.Equal(t, health.StatusFailing, report.Checks[1].Status)
+		require.Equal(t, "unreachable", report.Checks[1].Error)
+	})
+
+	t.Run("error - a check overrunning the timeout fails", func(t *testing.T) {
+		// arrange
+		c := health.New(10 * time.Millisecond)
+		c.Register("slow", func(ctx context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		})
+
+		// act
+		start := time.Now()
+		report := c.Run(context.Background())
+
+		// assert
+		require.Less(t, time.Since(start), time.Second)
+		require.Equal(t, health.StatusFailing, report.Status)
+		require.Equal(t, context.DeadlineExceeded.Error(), report.Checks[0].Error)
+	})
+
+	t.Run("error - a checker set down fails", func(t *testing.T) {
+		// arrange
+		c := health.New(time.Second)
+		c.Register("ok", ok)
+		c.Down("shutting down")
+
+		// act
+		report := c.Run(context.Background())
+
+		// assert
+		require.Equal(t, health.StatusFailing, report.Status)
+		require.Len(t, report.Checks, 2)
+		require.Equal(t, "shutting down", report.Checks[0].Error)
+	})
+}
+
+// Tests for Checker.Handler
+func TestChecker_Handler(t *testing.T) {
+	t.Run("success - ok report", func(t *testing.T) {
+		// arrange
+		c := health.New(time.Second)
+		c.Register("ok", func(ctx context.Context) error { return nil })
+		res := httptest.NewRecorder()
+
+		// act
+		c.Handler()(res, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+		// assert
+		require.Equal(t, http.StatusOK, res.Code)
+		require.Equal(t, "no-store", res.Header().Get("Cache-Control"))
+		require.Contains(t, res.Body.String(), `"status":"ok"`)
+		require.Contains(t, res.Body.String(), `"latency_ms"`)
+	})
+
+	t.Run("error - failing report", func(t *testing.T) {
+		// arrange
+		c := health.New(time.Second)
+		c.Down("shutting down")
+		res := httptest.NewRecorder()
+
+		// act
+		c.Handler()(res, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+		// assert
+		require.Equal(t, http.StatusServiceUnavailable, res.Code)
+		require.Contains(t, res.Body.String(), `"status":"failing"`)
+	})
+}
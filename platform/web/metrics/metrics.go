@@ -0,0 +1,305 @@
+// Package metrics records counters, gauges and histograms and serves them in the Prometheus text exposition format.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType is the content type of the text exposition format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultBuckets are the upper bounds of the histogram buckets, in seconds, suited to the latency of requests
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+var (
+	// metricName and labelName are the names accepted by the text exposition format
+	metricName = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	labelName  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	// labelValue escapes the label values
+	labelValue = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	// helpText escapes the help of the metrics
+	helpText = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+// Registry holds the metrics exposed together, in the order of registration.
+// Registering a metric with an invalid or taken name, or invalid label names, panics.
+type Registry struct {
+	mu      sync.Mutex
+	metrics []*metric
+	names   map[string]bool
+	// collects run before every exposition, e.g. to set gauges computed at scrape time
+	collects []func()
+}
+
+// NewRegistry returns a registry without metrics
+func NewRegistry() *Registry {
+	return &Registry{
+		names: make(map[string]bool),
+	}
+}
+
+// Counter registers a counter with the labels
+func (r *Registry) Counter(name, help string, labels ...string) *Counter {
+	return &Counter{m: r.register(name, help, "counter", nil, labels)}
+}
+
+// Gauge registers a gauge with the labels
+func (r *Registry) Gauge(name, help string, labels ...string) *Gauge {
+	return &Gauge{m: r.register(name, help, "gauge", nil, labels)}
+}
+
+// Histogram registers a histogram with the labels, whose buckets have the upper bounds, DefaultBuckets when nil
+func (r *Registry) Histogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	return &Histogram{m: r.register(name, help, "histogram", buckets, labels)}
+}
+
+// OnCollect runs fn before every exposition, in the order of registration
+func (r *Registry) OnCollect(fn func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.collects = append(r.collects, fn)
+}
+
+// WriteText writes the metrics to w in the text exposition format, the series of each one sorted by their labels
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	metrics := r.metrics
+	collects := r.collects
+	r.mu.Unlock()
+
+	for _, fn := range collects {
+		fn()
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, m := range metrics {
+		m.write(bw)
+	}
+
+	return bw.Flush()
+}
+
+// Handler responds with the metrics in the text exposition format
+func (r *Registry) Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusOK)
+		r.WriteText(w)
+	}
+}
+
+// register adds the metric, panicking when its names are invalid or taken
+func (r *Registry) register(name, help, kind string, buckets []float64, labels []string) *metric {
+	if !metricName.MatchString(name) {
+		panic(fmt.Sprintf("metrics: invalid metric name %q", name))
+	}
+	for _, label := range labels {
+		if !labelName.MatchString(label) || strings.HasPrefix(label, "__") || (kind == "histogram" && label == "le") {
+			panic(fmt.Sprintf("metrics: invalid label name %q of metric %s", label, name))
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.names[name] {
+		panic(fmt.Sprintf("metrics: metric %s already registered", name))
+	}
+	r.names[name] = true
+
+	m := &metric{
+		name:    name,
+		help:    help,
+		kind:    kind,
+		labels:  append([]string(nil), labels...),
+		buckets: buckets,
+		series:  make(map[string]*series),
+	}
+	r.metrics = append(r.metrics, m)
+
+	return m
+}
+
+// metric holds the series of a metric, one per combination of label values
+type metric struct {
+	name    string
+	help    string
+	kind    string
+	labels  []string
+	buckets []float64
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+// series is the value of a metric for a combination of label values
+type series struct {
+	values []string
+	// value is the value of a counter or a gauge
+	value float64
+	// counts are the observations of a histogram in each bucket, not cumulated, the last one being +Inf
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// get returns the series of the label values, created on first use. The caller holds the lock.
+func (m *metric) get(values []string) *series {
+	if len(values) != len(m.labels) {
+		panic(fmt.Sprintf("metrics: metric %s has %d labels, got %d values", m.name, len(m.labels), len(values)))
+	}
+
+	key := strings.Join(values, "\xff")
+	s, ok := m.series[key]
+	if !ok {
+		s = &series{values: append([]string(nil), values...)}
+		if m.kind == "histogram" {
+			s.counts = make([]uint64, len(m.buckets)+1)
+		}
+		m.series[key] = s
+	}
+
+	return s
+}
+
+// write writes the help, the type and the series of the metric
+func (m *metric) write(w *bufio.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fmt.Fprintf(w, "# HELP %s %s\n", m.name, helpText.Replace(m.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", m.name, m.kind)
+
+	keys := make([]string, 0, len(m.series))
+	for key := range m.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		s := m.series[key]
+		if m.kind != "histogram" {
+			fmt.Fprintf(w, "%s%s %s\n", m.name, m.labelSet(s.values, ""), formatFloat(s.value))
+			continue
+		}
+
+		var cumulative uint64
+		for i, count := range s.counts {
+			cumulative += count
+			le := math.Inf(1)
+			if i < len(m.buckets) {
+				le = m.buckets[i]
+			}
+			fmt.Fprintf(w, "%s_bucket%s %d\n", m.name, m.labelSet(s.values, formatFloat(le)), cumulative)
+		}
+		fmt.Fprintf(w, "%s_sum%s %s\n", m.name, m.labelSet(s.values, ""), formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", m.name, m.labelSet(s.values, ""), s.count)
+	}
+}
+
+// labelSet formats the label values, with the le label of a histogram bucket when it is not empty
+func (m *metric) labelSet(values []string, le string) string {
+	pairs := make([]string, 0, len(values)+1)
+	for i, value := range values {
+		pairs = append(pairs, m.labels[i]+`="`+labelValue.Replace(value)+`"`)
+	}
+	if le != "" {
+		pairs = append(pairs, `le="`+le+`"`)
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// formatFloat formats the value as the text exposition format expects
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// Counter is a value which only goes up, e.g. the number of requests served
+type Counter struct {
+	m *metric
+}
+
+// Inc adds one to the series of the label values
+func (c *Counter) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// Add adds delta, which can not be negative, to the series of the label values
+func (c *Counter) Add(delta float64, values ...string) {
+	if delta < 0 {
+		panic(fmt.Sprintf("metrics: counter %s can not decrease", c.m.name))
+	}
+
+	c.m.mu.Lock()
+	defer c.m.mu.Unlock()
+
+	c.m.get(values).value += delta
+}
+
+// Gauge is a value which goes up and down, e.g. the number of products
+type Gauge struct {
+	m *metric
+}
+
+// Set sets the series of the label values to value
+func (g *Gauge) Set(value float64, values ...string) {
+	g.m.mu.Lock()
+	defer g.m.mu.Unlock()
+
+	g.m.get(values).value = value
+}
+
+// Add adds delta, which can be negative, to the series of the label values
+func (g *Gauge) Add(delta float64, values ...string) {
+	g.m.mu.Lock()
+	defer g.m.mu.Unlock()
+
+	g.m.get(values).value += delta
+}
+
+// Histogram counts the observations in buckets, e.g. the latency of requests
+type Histogram struct {
+	m *metric
+}
+
+// Observe adds the value to the series of the label values
+func (h *Histogram) Observe(value float64, values ...string) {
+	h.m.mu.Lock()
+	defer h.m.mu.Unlock()
+
+	s := h.m.get(values)
+	// the value falls in the first bucket whose upper bound it does not exceed
+	i := sort.SearchFloat64s(h.m.buckets, value)
+	s.counts[i]++
+	s.sum += value
+	s.count++
+}
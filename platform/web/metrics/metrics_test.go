@@ -0,0 +1,180 @@
+package metrics_test
+
+import (
+	"app/platform/web/metrics"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// text returns the exposition of the registry
+func text(t *testing.T, r *metrics.Registry) string {
+	t.Helper()
+
+	var b strings.Builder
+	require.NoError(t, r.WriteText(&b))
+	return b.String()
+}
+
+// Tests for Registry
+func TestRegistry_WriteText(t *testing.T) {
+	t.Run("success - counter series are sorted by their labels", func(t *testing.T) {
+		// arrange
+		r := metrics.NewRegistry()
+		c := r.Counter("requests_total", "Requests served.", "route", "status")
+		c.Inc("/b", "200")
+		c.Inc("/a", "404")
+		c.Add(2, "/a", "200")
+
+		// act
+		out := text(t, r)
+
+		// assert
+		expected := "# HELP requests_total Requests served.\n" +
+			"# TYPE requests_total counter\n" +
+			`requests_total{route="/a",status="200"} 2` + "\n" +
+			`requests_total{route="/a",status="404"} 1` + "\n" +
+			`requests_total{route="/b",status="200"} 1` + "\n"
+		require.Equal(t, expected, out)
+	})
+
+	t.Run("success - gauge without labels", func(t *testing.T) {
+		// arrange
+		r := metrics.NewRegistry()
+		g := r.Gauge("products", "Products.")
+		g.Set(3)
+		g.Add(-1)
+
+		// act
+		out := text(t, r)
+
+		// assert
+		require.Contains(t, out, "# TYPE products gauge\nproducts 2\n")
+	})
+
+	t.Run("success - histogram buckets are cumulative", func(t *testing.T) {
+		// arrange
+		r := metrics.NewRegistry()
+		h := r.Histogram("latency_seconds", "Latency.", []float64{1, 0.1}, "op")
+		h.Observe(0.05, "find")
+		h.Observe(0.1, "find")
+		h.Observe(0.5, "find")
+		h.Observe(2, "find")
+
+		// act
+		out := text(t, r)
+
+		// assert
+		expected := "# HELP latency_seconds Latency.\n" +
+			"# TYPE latency_seconds histogram\n" +
+			`latency_seconds_bucket{op="find",le="0.1"} 2` + "\n" +
+			`latency_seconds_bucket{op="find",le="1"} 3` + "\n" +
+			`latency_seconds_bucket{op="find",le="+Inf"} 4` + "\n" +
+			`latency_seconds_sum{op="find"} 2.65` + "\n" +
+			`latency_seconds_count{op="find"} 4` + "\n"
+		require.Equal(t, expected, out)
+	})
+
+	t.Run("success - label values and help are escaped", func(t *testing.T) {
+		// arrange
+		r := metrics.NewRegistry()
+		r.Counter("escaped_total", "Line\nbreak and \\.", "value").Inc("a\"b\\c\nd")
+
+		// act
+		out := text(t, r)
+
+		// assert
+		require.Contains(t, out, `# HELP escaped_total Line\nbreak and \\.`+"\n")
+		require.Contains(t, out, `escaped_total{value="a\"b\\c\nd"} 1`+"\n")
+	})
+
+	t.Run("success - collect functions run before the exposition", func(t *testing.T) {
+		// arrange
+		r := metrics.NewRegistry()
+		g := r.Gauge("scrapes", "Scrapes.")
+		scrapes := 0
+		r.OnCollect(func() {
+			scrapes++
+			g.Set(float64(scrapes))
+		})
+
+		// act
+		text(t, r)
+		out := text(t, r)
+
+		// assert
+		require.Contains(t, out, "scrapes 2\n")
+	})
+
+	t.Run("success - metrics without series only have their help and type", func(t *testing.T) {
+		// arrange
+		r := metrics.NewRegistry()
+		r.Counter("unused_total", "Unused.", "label")
+
+		// act
+		out := text(t, r)
+
+		// assert
+		require.Equal(t, "# HELP unused_total Unused.\n# TYPE unused_total counter\n", out)
+	})
+}
+
+func TestRegistry_Register(t *testing.T) {
+	t.Run("error - name already registered", func(t *testing.T) {
+		// arrange
+		r := metrics.NewRegistry()
+		r.Counter("requests_total", "Requests.")
+
+		// act & assert
+		require.Panics(t, func() { r.Gauge("requests_total", "Requests.") })
+	})
+
+	t.Run("error - invalid names", func(t *testing.T) {
+		// arrange
+		r := metrics.NewRegistry()
+
+		// act & assert
+		require.Panics(t, func() { r.Counter("requests-total", "Requests.") })
+		require.Panics(t, func() { r.Counter("requests_total", "Requests.", "status code") })
+		require.Panics(t, func() { r.Histogram("latency_seconds", "Latency.", nil, "le") })
+	})
+
+	t.Run("error - wrong number of label values", func(t *testing.T) {
+		// arrange
+		r := metrics.NewRegistry()
+		c := r.Counter("requests_total", "Requests.", "route", "status")
+
+		// act & assert
+		require.Panics(t, func() { c.Inc("/products") })
+	})
+
+	t.Run("error - counter can not decrease", func(t *testing.T) {
+		// arrange
+		r := metrics.NewRegistry()
+		c := r.Counter("requests_total", "Requests.")
+
+		// act & assert
+		require.Panics(t, func() { c.Add(-1) })
+	})
+}
+
+func TestRegistry_Handler(t *testing.T) {
+	t.Run("success - text exposition format", func(t *testing.T) {
+		// arrange
+		r := metrics.NewRegistry()
+		r.Counter("requests_total", "Requests.").Inc()
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		res := httptest.NewRecorder()
+
+		// act
+		r.Handler()(res, req)
+
+		// assert
+		require.Equal(t, http.StatusOK, res.Code)
+		require.Equal(t, metrics.ContentType, res.Header().Get("Content-Type"))
+		require.Contains(t, res.Body.String(), "requests_total 1\n")
+	})
+}
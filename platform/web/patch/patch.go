@@ -0,0 +1,333 @@
+// Package patch applies patches to json documents:
+//   - Merge: RFC 7396 JSON Merge Patch, a document whose members replace the target ones, null removing them
+//   - JSON: RFC 6902 JSON Patch, a list of add, replace, remove and test operations on RFC 6901 JSON Pointers
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// media types of the patch documents
+const (
+	MediaTypeMergePatch = "application/merge-patch+json"
+	MediaTypeJSONPatch  = "application/json-patch+json"
+)
+
+var (
+	// ErrInvalid is returned when the document or the patch is not valid json or not a valid patch
+	ErrInvalid = errors.New("patch: invalid patch")
+	// ErrPath is returned when a path of a JSON Patch operation does not exist in the document
+	ErrPath = errors.New("patch: path not found")
+	// ErrTestFailed is returned when a JSON Patch test operation does not match the document
+	ErrTestFailed = errors.New("patch: test failed")
+)
+
+// Merge applies the RFC 7396 merge patch to the json document
+func Merge(doc, patch []byte) ([]byte, error) {
+	target, err := decode(doc)
+	if err != nil {
+		return nil, err
+	}
+	p, err := decode(patch)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(merge(target, p))
+}
+
+// merge returns the target with the patch applied
+func merge(target, patch any) any {
+	members, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	object, ok := target.(map[string]any)
+	if !ok {
+		object = make(map[string]any, len(members))
+	}
+	for key, value := range members {
+		if value == nil {
+			delete(object, key)
+			continue
+		}
+		object[key] = merge(object[key], value)
+	}
+
+	return object
+}
+
+// operation is an operation of a JSON Patch
+type operation struct {
+	Op    string          `json:"op"`
+	Path  *string         `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+// JSON applies the RFC 6902 JSON Patch to the json document.
+// The add, replace, remove and test operations are supported, any failing operation fails the whole patch.
+func JSON(doc, patch []byte) ([]byte, error) {
+	target, err := decode(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var operations []operation
+	if err := json.Unmarshal(patch, &operations); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+
+	for i, op := range operations {
+		if target, err = apply(target, op); err != nil {
+			return nil, fmt.Errorf("operation %d: %w", i, err)
+		}
+	}
+
+	return json.Marshal(target)
+}
+
+// apply returns the document with the operation applied
+func apply(doc any, op operation) (any, error) {
+	if op.Path == nil {
+		return nil, fmt.Errorf("%w: %s needs a path", ErrInvalid, op.Op)
+	}
+	tokens, err := pointer(*op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var value any
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return nil, fmt.Errorf("%w: %s needs a value", ErrInvalid, op.Op)
+		}
+		if value, err = decode(op.Value); err != nil {
+			return nil, err
+		}
+	case "remove":
+	default:
+		return nil, fmt.Errorf("%w: unsupported operation %q", ErrInvalid, op.Op)
+	}
+
+	switch op.Op {
+	case "test":
+		current, err := get(doc, tokens)
+		if err != nil {
+			return nil, err
+		}
+		if !equal(current, value) {
+			return nil, fmt.Errorf("%w: %s", ErrTestFailed, *op.Path)
+		}
+		return doc, nil
+	case "add":
+		if len(tokens) == 0 {
+			return value, nil
+		}
+		return walk(doc, tokens, func(parent any, key string) (any, error) {
+			switch node := parent.(type) {
+			case map[string]any:
+				node[key] = value
+				return node, nil
+			case []any:
+				i := len(node)
+				if key != "-" {
+					if i, err = index(key, len(node)+1); err != nil {
+						return nil, err
+					}
+				}
+				node = append(node, nil)
+				copy(node[i+1:], node[i:])
+				node[i] = value
+				return node, nil
+			}
+			return nil, fmt.Errorf("%w: %s", ErrPath, *op.Path)
+		})
+	case "replace":
+		if len(tokens) == 0 {
+			return value, nil
+		}
+		return walk(doc, tokens, func(parent any, key string) (any, error) {
+			switch node := parent.(type) {
+			case map[string]any:
+				if _, ok := node[key]; !ok {
+					return nil, fmt.Errorf("%w: %s", ErrPath, *op.Path)
+				}
+				node[key] = value
+				return node, nil
+			case []any:
+				i, err := index(key, len(node))
+				if err != nil {
+					return nil, err
+				}
+				node[i] = value
+				return node, nil
+			}
+			return nil, fmt.Errorf("%w: %s", ErrPath, *op.Path)
+		})
+	}
+
+	// remove
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("%w: the whole document can not be removed", ErrInvalid)
+	}
+	return walk(doc, tokens, func(parent any, key string) (any, error) {
+		switch node := parent.(type) {
+		case map[string]any:
+			if _, ok := node[key]; !ok {
+				return nil, fmt.Errorf("%w: %s", ErrPath, *op.Path)
+			}
+			delete(node, key)
+			return node, nil
+		case []any:
+			i, err := index(key, len(node))
+			if err != nil {
+				return nil, err
+			}
+			return append(node[:i], node[i+1:]...), nil
+		}
+		return nil, fmt.Errorf("%w: %s", ErrPath, *op.Path)
+	})
+}
+
+// walk follows the tokens down to the parent of the last one and replaces it with the result of leaf
+func walk(doc any, tokens []string, leaf func(parent any, key string) (any, error)) (any, error) {
+	if len(tokens) == 1 {
+		return leaf(doc, tokens[0])
+	}
+
+	key := tokens[0]
+	switch node := doc.(type) {
+	case map[string]any:
+		child, ok := node[key]
+		if !ok {
+			return nil, fmt.Errorf("%w: /%s", ErrPath, key)
+		}
+		child, err := walk(child, tokens[1:], leaf)
+		if err != nil {
+			return nil, err
+		}
+		node[key] = child
+		return node, nil
+	case []any:
+		i, err := index(key, len(node))
+		if err != nil {
+			return nil, err
+		}
+		child, err := walk(node[i], tokens[1:], leaf)
+		if err != nil {
+			return nil, err
+		}
+		node[i] = child
+		return node, nil
+	}
+
+	return nil, fmt.Errorf("%w: /%s", ErrPath, key)
+}
+
+// get returns the value of the document at the tokens
+func get(doc any, tokens []string) (any, error) {
+	for _, key := range tokens {
+		switch node := doc.(type) {
+		case map[string]any:
+			child, ok := node[key]
+			if !ok {
+				return nil, fmt.Errorf("%w: /%s", ErrPath, key)
+			}
+			doc = child
+		case []any:
+			i, err := index(key, len(node))
+			if err != nil {
+				return nil, err
+			}
+			doc = node[i]
+		default:
+			return nil, fmt.Errorf("%w: /%s", ErrPath, key)
+		}
+	}
+	return doc, nil
+}
+
+// pointer splits the RFC 6901 JSON Pointer into its unescaped reference tokens
+func pointer(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(path, "/") {
+		return nil, fmt.Errorf("%w: path %q must start with /", ErrInvalid, path)
+	}
+
+	tokens := strings.Split(path[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// index parses the array index token, which must be lower than size
+func index(token string, size int) (int, error) {
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i >= size || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("%w: index %s", ErrPath, token)
+	}
+	return i, nil
+}
+
+// decode decodes json keeping the text of the numbers
+func decode(data []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("%w: trailing data", ErrInvalid)
+	}
+	return value, nil
+}
+
+// equal reports whether two decoded json values are equal, numbers being compared by value
+func equal(a, b any) bool {
+	switch a := a.(type) {
+	case map[string]any:
+		b, ok := b.(map[string]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for key, value := range a {
+			other, ok := b[key]
+			if !ok || !equal(value, other) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		b, ok := b.([]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !equal(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	case json.Number:
+		b, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		x, errA := a.Float64()
+		y, errB := b.Float64()
+		return errA == nil && errB == nil && x == y
+	}
+	return a == b
+}
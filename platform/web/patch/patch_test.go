@@ -0,0 +1,116 @@
+package patch_test
+
+import (
+	"app/platform/web/patch"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for Merge
+func TestMerge(t *testing.T) {
+	t.Run("success - members are replaced, added and removed", func(t *testing.T) {
+		// arrange
+		doc := `{"name":"a","quantity":1,"is_published":true,"price":{"amount":"1.00","currency":"USD"}}`
+
+		// act
+		result, err := patch.Merge([]byte(doc), []byte(`{"name":"b","is_published":false,"quantity":null,"price":{"amount":"2.50"},"tags":["x"]}`))
+
+		// assert
+		expected := `{"name":"b","is_published":false,"price":{"amount":"2.50","currency":"USD"},"tags":["x"]}`
+		require.NoError(t, err)
+		require.JSONEq(t, expected, string(result))
+	})
+
+	t.Run("success - a patch that is not an object replaces the document", func(t *testing.T) {
+		// act
+		result, err := patch.Merge([]byte(`{"name":"a"}`), []byte(`["b"]`))
+
+		// assert
+		require.NoError(t, err)
+		require.JSONEq(t, `["b"]`, string(result))
+	})
+
+	t.Run("error - patch is not json", func(t *testing.T) {
+		// act
+		result, err := patch.Merge([]byte(`{"name":"a"}`), []byte(`{"name":`))
+
+		// assert
+		require.ErrorIs(t, err, patch.ErrInvalid)
+		require.Nil(t, result)
+	})
+}
+
+// Tests for JSON
+func TestJSON(t *testing.T) {
+	doc := `{"name":"a","quantity":1,"is_published":true,"tags":["x","y"],"a/b":{"c~d":1}}`
+
+	t.Run("success - operations are applied in order", func(t *testing.T) {
+		// arrange
+		operations := `[
+			{"op":"test","path":"/quantity","value":1.0},
+			{"op":"replace","path":"/quantity","value":0},
+			{"op":"remove","path":"/is_published"},
+			{"op":"add","path":"/tags/1","value":"z"},
+			{"op":"add","path":"/tags/-","value":"w"},
+			{"op":"remove","path":"/tags/0"},
+			{"op":"replace","path":"/a~1b/c~0d","value":2}
+		]`
+
+		// act
+		result, err := patch.JSON([]byte(doc), []byte(operations))
+
+		// assert
+		expected := `{"name":"a","quantity":0,"tags":["z","y","w"],"a/b":{"c~d":2}}`
+		require.NoError(t, err)
+		require.JSONEq(t, expected, string(result))
+	})
+
+	t.Run("error - failed test rejects the whole patch", func(t *testing.T) {
+		// act
+		result, err := patch.JSON([]byte(doc), []byte(`[{"op":"replace","path":"/name","value":"b"},{"op":"test","path":"/quantity","value":2}]`))
+
+		// assert
+		require.ErrorIs(t, err, patch.ErrTestFailed)
+		require.Nil(t, result)
+	})
+
+	t.Run("error - path does not exist", func(t *testing.T) {
+		// arrange
+		cases := []string{
+			`[{"op":"replace","path":"/price","value":1}]`,
+			`[{"op":"remove","path":"/tags/2"}]`,
+			`[{"op":"add","path":"/missing/name","value":1}]`,
+			`[{"op":"test","path":"/tags/01","value":"y"}]`,
+		}
+
+		for _, operations := range cases {
+			// act
+			result, err := patch.JSON([]byte(doc), []byte(operations))
+
+			// assert
+			require.ErrorIs(t, err, patch.ErrPath, operations)
+			require.Nil(t, result)
+		}
+	})
+
+	t.Run("error - invalid operations", func(t *testing.T) {
+		// arrange
+		cases := []string{
+			`{"op":"add"}`,
+			`[{"op":"move","from":"/name","path":"/title"}]`,
+			`[{"op":"add","path":"/name"}]`,
+			`[{"op":"remove"}]`,
+			`[{"op":"remove","path":"name"}]`,
+		}
+
+		for _, operations := range cases {
+			// act
+			result, err := patch.JSON([]byte(doc), []byte(operations))
+
+			// assert
+			require.ErrorIs(t, err, patch.ErrInvalid, operations)
+			require.Nil(t, result)
+		}
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"mime"
 	"net/http"
 )
 
@@ -17,8 +18,8 @@ var (
 
 // JSON decodes json from request body to ptr
 func JSON(r *http.Request, ptr any) (err error) {
-	// check content type
-	if r.Header.Get("Content-Type") != "application/json" {
+	// check content type, its parameters (e.g. charset) being ignored
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
 		err = ErrRequestContentTypeNotJSON
 		return
 	}
@@ -26,7 +27,7 @@ func JSON(r *http.Request, ptr any) (err error) {
 	// get body
 	err = json.NewDecoder(r.Body).Decode(ptr)
 	if err != nil {
-		err = fmt.Errorf("%w. %v", ErrRequestJSONInvalid, err)
+		err = fmt.Errorf("%w. %w", ErrRequestJSONInvalid, err)
 		return
 	}
 
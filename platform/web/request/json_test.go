@@ -32,6 +32,26 @@ func TestRequestJSON(t *testing.T) {
 		require.Equal(t, expectedSchema, inputSchema)
 	})
 
+	t.Run("success - content-type with parameters", func(t *testing.T) {
+		// arrange
+		type schema struct {
+			Name string `json:"name"`
+		}
+
+		// act
+		inputSchema := schema{}
+		inputRequest := http.Request{
+			Header: http.Header{"Content-Type": []string{"Application/JSON; charset=utf-8"}},
+			Body:   io.NopCloser(strings.NewReader(`{"name":"test"}`)),
+		}
+		err := request.JSON(&inputRequest, &inputSchema)
+
+		// assert
+		expectedSchema := schema{Name: "test"}
+		require.NoError(t, err)
+		require.Equal(t, expectedSchema, inputSchema)
+	})
+
 	t.Run("error - content-type", func(t *testing.T) {
 		// arrange
 		type schema struct {
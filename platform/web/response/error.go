@@ -9,9 +9,29 @@ import (
 type errorResponse struct {
 	Status  string `json:"status"`
 	Message string `json:"message"`
+	Code    string `json:"code,omitempty"`
+	Field   string `json:"field,omitempty"`
+	Errors  any    `json:"errors,omitempty"`
 }
 
+// Error writes a json error response
 func Error(w http.ResponseWriter, statusCode int, message string) {
+	writeError(w, statusCode, errorResponse{Message: message})
+}
+
+// ErrorCode writes a json error response with a machine readable code and the field that caused it.
+// Empty code and field are omitted from the body.
+func ErrorCode(w http.ResponseWriter, statusCode int, code string, field string, message string) {
+	writeError(w, statusCode, errorResponse{Message: message, Code: code, Field: field})
+}
+
+// ErrorDetails writes a json error response with a machine readable code and the list of errors that caused it,
+// e.g. every field that failed validation
+func ErrorDetails(w http.ResponseWriter, statusCode int, code string, message string, errors any) {
+	writeError(w, statusCode, errorResponse{Message: message, Code: code, Errors: errors})
+}
+
+func writeError(w http.ResponseWriter, statusCode int, body errorResponse) {
 	// default status code
 	defaultStatusCode := http.StatusInternalServerError
 	// check if status code is valid
@@ -20,10 +40,7 @@ func Error(w http.ResponseWriter, statusCode int, message string) {
 	}
 
 	// response
-	body := errorResponse{
-		Status:  http.StatusText(defaultStatusCode),
-		Message: message,
-	}
+	body.Status = http.StatusText(defaultStatusCode)
 	bytes, err := json.Marshal(body)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -39,6 +56,7 @@ func Error(w http.ResponseWriter, statusCode int, message string) {
 	w.Write(bytes)
 }
 
+// Errorf writes a json error response with a formatted message
 func Errorf(w http.ResponseWriter, statusCode int, format string, args ...interface{}) {
 	message := fmt.Sprintf(format, args...)
 	Error(w, statusCode, message)
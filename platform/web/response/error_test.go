@@ -92,3 +92,70 @@ func TestErrorf(t *testing.T) {
 		require.Equal(t, expectedHeaders, rr.Header())
 	})
 }
+
+// Tests for ErrorCode
+func TestErrorCode(t *testing.T) {
+	t.Run("case 1: should return status code 400 with code and field", func(t *testing.T) {
+		// arrange
+		// ...
+
+		// act
+		rr := httptest.NewRecorder()
+		code := http.StatusBadRequest
+		message := "field is required: name"
+		response.ErrorCode(rr, code, "field_required", "name", message)
+
+		// assert
+		expectedCode := http.StatusBadRequest
+		expectedBody := `{"status":"Bad Request","message":"field is required: name","code":"field_required","field":"name"}`
+		expectedHeaders := http.Header{"Content-Type": []string{"application/json"}}
+		require.Equal(t, expectedCode, rr.Code)
+		require.Equal(t, expectedBody, rr.Body.String())
+		require.Equal(t, expectedHeaders, rr.Header())
+	})
+
+	t.Run("case 2: should omit empty field", func(t *testing.T) {
+		// arrange
+		// ...
+
+		// act
+		rr := httptest.NewRecorder()
+		code := http.StatusNotFound
+		message := "product not found"
+		response.ErrorCode(rr, code, "product_not_found", "", message)
+
+		// assert
+		expectedCode := http.StatusNotFound
+		expectedBody := `{"status":"Not Found","message":"product not found","code":"product_not_found"}`
+		expectedHeaders := http.Header{"Content-Type": []string{"application/json"}}
+		require.Equal(t, expectedCode, rr.Code)
+		require.Equal(t, expectedBody, rr.Body.String())
+		require.Equal(t, expectedHeaders, rr.Header())
+	})
+}
+
+// Tests for ErrorDetails
+func TestErrorDetails(t *testing.T) {
+	t.Run("case 1: should return status code 422 with the list of errors", func(t *testing.T) {
+		// arrange
+		type detail struct {
+			Field string `json:"field"`
+			Rule  string `json:"rule"`
+		}
+
+		// act
+		rr := httptest.NewRecorder()
+		code := http.StatusUnprocessableEntity
+		message := "validation failed"
+		errors := []detail{{Field: "name", Rule: "required"}, {Field: "price", Rule: "range"}}
+		response.ErrorDetails(rr, code, "validation_failed", message, errors)
+
+		// assert
+		expectedCode := http.StatusUnprocessableEntity
+		expectedBody := `{"status":"Unprocessable Entity","message":"validation failed","code":"validation_failed","errors":[{"field":"name","rule":"required"},{"field":"price","rule":"range"}]}`
+		expectedHeaders := http.Header{"Content-Type": []string{"application/json"}}
+		require.Equal(t, expectedCode, rr.Code)
+		require.Equal(t, expectedBody, rr.Body.String())
+		require.Equal(t, expectedHeaders, rr.Header())
+	})
+}
@@ -0,0 +1,326 @@
+// Package validate validates structs against rules declared with the validate tag on their fields.
+// Rules are separated by commas:
+//   - required: the value can not be the zero value
+//   - present: the field must be sent, its zero value being valid. Struct can not tell it apart, see Required
+//   - min=n, max=n: bounds numbers, or the length of strings, slices and maps
+//   - regex=expr: the string must match the regular expression (it can not contain commas)
+//   - date=layout: the string must be a date in the time layout
+//   - enum=a|b|c: the value must be one of the options
+//
+// The field is reported by the name in its json tag, falling back to the struct field name.
+// A validator only validates values of its schema type, or of the target type it was bound to with NewFor.
+// Empty values that are not required are not checked against the other rules.
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// violations reported by the rules
+const (
+	RuleRequired = "required"
+	RuleRange    = "range"
+	RuleLength   = "length"
+	RuleFormat   = "format"
+)
+
+// rulePresent is the rule of the fields that must be sent, see Required
+const rulePresent = "present"
+
+var (
+	// ErrSchemaInvalid is returned when the rules declared on a struct can not be compiled
+	ErrSchemaInvalid = errors.New("validate: invalid schema")
+	// ErrValueInvalid is returned when the value to validate is not of the type the validator applies to
+	ErrValueInvalid = errors.New("validate: invalid value")
+)
+
+// FieldError is a violation of a rule by a field
+type FieldError struct {
+	Field   string
+	Rule    string
+	Message string
+}
+
+// Errors are all the violations found validating a struct
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, fe := range e {
+		messages = append(messages, fe.Field+": "+fe.Message)
+	}
+	return "validation failed: " + strings.Join(messages, "; ")
+}
+
+// Validator validates structs against the rules compiled from a schema struct
+type Validator struct {
+	// target is the struct type of the validated values
+	target reflect.Type
+	fields []field
+}
+
+// field holds the compiled rules of a struct field
+type field struct {
+	// index is the index of the struct field in the target type
+	index    []int
+	name     string
+	required bool
+	present  bool
+	checks   []check
+}
+
+// check returns the violated rule and its message, or an empty rule when the value is valid
+type check func(v reflect.Value) (rule string, message string)
+
+// New compiles the rules declared with the validate tag on the fields of schema
+func New(schema any) (*Validator, error) {
+	return NewFor(schema, schema)
+}
+
+// NewFor compiles the rules declared on the fields of schema to validate values of the target type.
+// Every field with rules must be in target with the same name and type.
+func NewFor(schema any, target any) (*Validator, error) {
+	st, ok := structType(schema)
+	if !ok {
+		return nil, fmt.Errorf("%w: schema is not a struct", ErrSchemaInvalid)
+	}
+	tt, ok := structType(target)
+	if !ok {
+		return nil, fmt.Errorf("%w: target is not a struct", ErrSchemaInvalid)
+	}
+
+	v := &Validator{target: tt}
+	for i := 0; i < st.NumField(); i++ {
+		sf := st.Field(i)
+		tag, ok := sf.Tag.Lookup("validate")
+		if !ok || tag == "" {
+			continue
+		}
+
+		tf, ok := tt.FieldByName(sf.Name)
+		switch {
+		case !ok || len(tf.Index) != 1:
+			return nil, fmt.Errorf("%w: field %s is not in %s", ErrSchemaInvalid, sf.Name, tt)
+		case tf.Type != sf.Type:
+			return nil, fmt.Errorf("%w: field %s is %s in %s", ErrSchemaInvalid, sf.Name, tf.Type, tt)
+		}
+
+		f := field{index: tf.Index, name: fieldName(sf)}
+		for _, item := range strings.Split(tag, ",") {
+			name, arg, _ := strings.Cut(strings.TrimSpace(item), "=")
+			switch name {
+			case RuleRequired:
+				f.required = true
+				continue
+			case rulePresent:
+				f.present = true
+				continue
+			}
+
+			c, err := compile(sf.Type, name, arg)
+			if err != nil {
+				return nil, fmt.Errorf("%w: field %s: %v", ErrSchemaInvalid, sf.Name, err)
+			}
+			f.checks = append(f.checks, c)
+		}
+
+		v.fields = append(v.fields, f)
+	}
+
+	return v, nil
+}
+
+// MustNew is like New but panics if the rules can not be compiled
+func MustNew(schema any) *Validator {
+	v, err := New(schema)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// MustNewFor is like NewFor but panics if the rules can not be compiled
+func MustNewFor(schema any, target any) *Validator {
+	v, err := NewFor(schema, target)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// Required returns the names of the fields that must be sent, the required and the present ones
+func (v *Validator) Required() []string {
+	var names []string
+	for _, f := range v.fields {
+		if f.required || f.present {
+			names = append(names, f.name)
+		}
+	}
+	return names
+}
+
+// Struct validates value, a struct of the target type or a pointer to it, against the rules, returning every violation found
+func (v *Validator) Struct(value any) (Errors, error) {
+	rv := reflect.Indirect(reflect.ValueOf(value))
+	if !rv.IsValid() || rv.Type() != v.target {
+		return nil, fmt.Errorf("%w: must be a %s", ErrValueInvalid, v.target)
+	}
+
+	var errs Errors
+	for _, f := range v.fields {
+		fv := rv.FieldByIndex(f.index)
+		if fv.IsZero() {
+			if f.required {
+				errs = append(errs, FieldError{Field: f.name, Rule: RuleRequired, Message: "field is required"})
+			}
+			continue
+		}
+
+		for _, c := range f.checks {
+			if rule, message := c(fv); rule != "" {
+				errs = append(errs, FieldError{Field: f.name, Rule: rule, Message: message})
+			}
+		}
+	}
+
+	return errs, nil
+}
+
+// structType returns the struct type of value, or of the value it points to
+func structType(value any) (reflect.Type, bool) {
+	t := reflect.TypeOf(value)
+	if t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t, t != nil && t.Kind() == reflect.Struct
+}
+
+// fieldName returns the json name of the struct field
+func fieldName(sf reflect.StructField) string {
+	if name, _, _ := strings.Cut(sf.Tag.Get("json"), ","); name != "" && name != "-" {
+		return name
+	}
+	return sf.Name
+}
+
+// compile returns the check of the rule for a field of type t
+func compile(t reflect.Type, name, arg string) (check, error) {
+	switch name {
+	case "min", "max":
+		bound, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s needs a number", name)
+		}
+		return compileBound(t, name == "min", bound, arg)
+	case "regex":
+		if t.Kind() != reflect.String {
+			return nil, fmt.Errorf("regex only applies to strings")
+		}
+		re, err := regexp.Compile(arg)
+		if err != nil {
+			return nil, fmt.Errorf("regex: %v", err)
+		}
+		return func(v reflect.Value) (string, string) {
+			if v.Kind() == reflect.String && !re.MatchString(v.String()) {
+				return RuleFormat, "must match " + arg
+			}
+			return "", ""
+		}, nil
+	case "date":
+		if t.Kind() != reflect.String || arg == "" {
+			return nil, fmt.Errorf("date only applies to strings and needs a layout")
+		}
+		return func(v reflect.Value) (string, string) {
+			if v.Kind() != reflect.String {
+				return "", ""
+			}
+			if _, err := time.Parse(arg, v.String()); err != nil {
+				return RuleFormat, "must be a date with the layout " + arg
+			}
+			return "", ""
+		}, nil
+	case "enum":
+		options := strings.Split(arg, "|")
+		return func(v reflect.Value) (string, string) {
+			value := fmt.Sprint(v.Interface())
+			for _, option := range options {
+				if value == option {
+					return "", ""
+				}
+			}
+			return RuleFormat, "must be one of " + strings.Join(options, ", ")
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unknown rule %s", name)
+}
+
+// compileBound returns the check of a min (or max) rule.
+// Numbers are bounded by value and strings, slices and maps by length.
+func compileBound(t reflect.Type, isMin bool, bound float64, arg string) (check, error) {
+	violated := func(n float64) bool {
+		if isMin {
+			return n < bound
+		}
+		return n > bound
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		message := "must be less than or equal to " + arg
+		if isMin {
+			message = "must be greater than or equal to " + arg
+		}
+		return func(v reflect.Value) (string, string) {
+			n, ok := number(v)
+			if ok && violated(n) {
+				return RuleRange, message
+			}
+			return "", ""
+		}, nil
+	case reflect.String, reflect.Slice, reflect.Map:
+		message := "must be at most " + arg + " long"
+		if isMin {
+			message = "must be at least " + arg + " long"
+		}
+		return func(v reflect.Value) (string, string) {
+			var n int
+			switch v.Kind() {
+			case reflect.String:
+				n = utf8.RuneCountInString(v.String())
+			case reflect.Slice, reflect.Map:
+				n = v.Len()
+			default:
+				return "", ""
+			}
+			if violated(float64(n)) {
+				return RuleLength, message
+			}
+			return "", ""
+		}, nil
+	}
+
+	return nil, fmt.Errorf("min and max only apply to numbers, strings, slices and maps")
+}
+
+// number returns the value of a numeric reflect.Value as a float64
+func number(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
@@ -0,0 +1,258 @@
+package validate_test
+
+import (
+	"app/platform/web/validate"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Tests for Struct
+func TestValidatorStruct(t *testing.T) {
+	type schema struct {
+		Name     string  `json:"name" validate:"required,max=5"`
+		Code     string  `json:"code" validate:"regex=^[A-Z]+$"`
+		Quantity int     `json:"quantity" validate:"required,min=1,max=10"`
+		Price    float64 `validate:"min=0"`
+		Date     string  `json:"date" validate:"date=02/01/2006"`
+		Status   string  `json:"status" validate:"enum=draft|published"`
+		Ignored  string  `json:"ignored"`
+	}
+
+	t.Run("success", func(t *testing.T) {
+		// arrange
+		vl := validate.MustNew(schema{})
+
+		// act
+		value := schema{Name: "abc", Code: "ABC", Quantity: 3, Price: 1.5, Date: "31/12/2030", Status: "draft"}
+		errs, err := vl.Struct(value)
+
+		// assert
+		require.NoError(t, err)
+		require.Empty(t, errs)
+	})
+
+	t.Run("success - empty optional fields are not checked", func(t *testing.T) {
+		// arrange
+		vl := validate.MustNew(schema{})
+
+		// act
+		value := schema{Name: "abc", Quantity: 3}
+		errs, err := vl.Struct(&value)
+
+		// assert
+		require.NoError(t, err)
+		require.Empty(t, errs)
+	})
+
+	t.Run("error - every violation is reported", func(t *testing.T) {
+		// arrange
+		vl := validate.MustNew(schema{})
+
+		// act
+		value := schema{Name: "abcdef", Code: "abc", Price: -1, Date: "12/31/2030", Status: "deleted"}
+		errs, err := vl.Struct(value)
+
+		// assert
+		expectedErrs := validate.Errors{
+			{Field: "name", Rule: validate.RuleLength, Message: "must be at most 5 long"},
+			{Field: "code", Rule: validate.RuleFormat, Message: "must match ^[A-Z]+$"},
+			{Field: "quantity", Rule: validate.RuleRequired, Message: "field is required"},
+			{Field: "Price", Rule: validate.RuleRange, Message: "must be greater than or equal to 0"},
+			{Field: "date", Rule: validate.RuleFormat, Message: "must be a date with the layout 02/01/2006"},
+			{Field: "status", Rule: validate.RuleFormat, Message: "must be one of draft, published"},
+		}
+		require.NoError(t, err)
+		require.Equal(t, expectedErrs, errs)
+	})
+
+	t.Run("success - a bound validator checks values of the target type", func(t *testing.T) {
+		// arrange
+		type target struct {
+			Quantity int
+			Name     string
+			Code     string
+			Price    float64
+			Date     string
+			Status   string
+		}
+		vl := validate.MustNewFor(schema{}, target{})
+
+		// act
+		errs, err := vl.Struct(&target{Name: "abcdef", Quantity: 11})
+
+		// assert
+		expectedErrs := validate.Errors{
+			{Field: "name", Rule: validate.RuleLength, Message: "must be at most 5 long"},
+			{Field: "quantity", Rule: validate.RuleRange, Message: "must be less than or equal to 10"},
+		}
+		require.NoError(t, err)
+		require.Equal(t, expectedErrs, errs)
+	})
+
+	t.Run("error - value of another struct type", func(t *testing.T) {
+		// arrange
+		type other struct {
+			Name     string
+			Quantity int
+		}
+		vl := validate.MustNew(schema{})
+
+		// act
+		errs, err := vl.Struct(other{Name: "abcdef", Quantity: 11})
+
+		// assert
+		require.ErrorIs(t, err, validate.ErrValueInvalid)
+		require.Empty(t, errs)
+	})
+
+	t.Run("error - value is not a struct", func(t *testing.T) {
+		// arrange
+		vl := validate.MustNew(schema{})
+
+		// act
+		errs, err := vl.Struct("value")
+
+		// assert
+		require.ErrorIs(t, err, validate.ErrValueInvalid)
+		require.Empty(t, errs)
+	})
+}
+
+// Tests for New
+func TestNew(t *testing.T) {
+	t.Run("success - required fields", func(t *testing.T) {
+		// arrange
+		type schema struct {
+			Name  string `json:"name,omitempty" validate:"required"`
+			Code  string `json:"code"`
+			Price int    `validate:"required,min=1"`
+		}
+
+		// act
+		vl, err := validate.New(schema{})
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, []string{"name", "Price"}, vl.Required())
+	})
+
+	t.Run("success - present fields must be sent but can be zero", func(t *testing.T) {
+		// arrange
+		type schema struct {
+			Name     string `json:"name" validate:"required"`
+			Quantity int    `json:"quantity" validate:"present,min=0"`
+		}
+
+		// act
+		vl, err := validate.New(schema{})
+		errs, errStruct := vl.Struct(schema{Name: "name"})
+		negative, _ := vl.Struct(schema{Name: "name", Quantity: -1})
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, []string{"name", "quantity"}, vl.Required())
+		require.NoError(t, errStruct)
+		require.Empty(t, errs)
+		require.Equal(t, validate.Errors{{Field: "quantity", Rule: validate.RuleRange, Message: "must be greater than or equal to 0"}}, negative)
+	})
+
+	t.Run("error - unknown rule", func(t *testing.T) {
+		// arrange
+		type schema struct {
+			Name string `validate:"unknown"`
+		}
+
+		// act
+		vl, err := validate.New(schema{})
+
+		// assert
+		require.ErrorIs(t, err, validate.ErrSchemaInvalid)
+		require.Nil(t, vl)
+	})
+
+	t.Run("error - rule does not apply to the field type", func(t *testing.T) {
+		// arrange
+		type schema struct {
+			Quantity int `validate:"regex=^[0-9]+$"`
+		}
+
+		// act
+		vl, err := validate.New(schema{})
+
+		// assert
+		require.ErrorIs(t, err, validate.ErrSchemaInvalid)
+		require.Nil(t, vl)
+	})
+
+	t.Run("error - schema is not a struct", func(t *testing.T) {
+		// act
+		vl, err := validate.New(1)
+
+		// assert
+		require.ErrorIs(t, err, validate.ErrSchemaInvalid)
+		require.Nil(t, vl)
+	})
+}
+
+// Tests for NewFor
+func TestNewFor(t *testing.T) {
+	type schema struct {
+		Name     string `json:"name" validate:"required"`
+		Quantity int    `json:"quantity" validate:"min=0"`
+		Code     string `json:"code"`
+	}
+
+	t.Run("success - fields without rules are not needed in the target", func(t *testing.T) {
+		// arrange
+		type target struct {
+			Name     string
+			Quantity int
+		}
+
+		// act
+		vl, err := validate.NewFor(schema{}, &target{})
+
+		// assert
+		require.NoError(t, err)
+		require.Equal(t, []string{"name"}, vl.Required())
+	})
+
+	t.Run("error - field missing in the target", func(t *testing.T) {
+		// arrange
+		type target struct {
+			Name string
+		}
+
+		// act
+		vl, err := validate.NewFor(schema{}, target{})
+
+		// assert
+		require.ErrorIs(t, err, validate.ErrSchemaInvalid)
+		require.Nil(t, vl)
+	})
+
+	t.Run("error - field of another type in the target", func(t *testing.T) {
+		// arrange
+		type target struct {
+			Name     string
+			Quantity int64
+		}
+
+		// act
+		vl, err := validate.NewFor(schema{}, target{})
+
+		// assert
+		require.ErrorIs(t, err, validate.ErrSchemaInvalid)
+		require.Nil(t, vl)
+	})
+
+	t.Run("error - target is not a struct", func(t *testing.T) {
+		// act
+		vl, err := validate.NewFor(schema{}, "target")
+
+		// assert
+		require.ErrorIs(t, err, validate.ErrSchemaInvalid)
+		require.Nil(t, vl)
+	})
+}